@@ -2,26 +2,43 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"planets-server/internal/auth"
+	"planets-server/internal/building"
+	"planets-server/internal/events"
 	"planets-server/internal/game"
 	"planets-server/internal/middleware"
 	"planets-server/internal/planet"
 	"planets-server/internal/player"
+	"planets-server/internal/research"
 	"planets-server/internal/server"
+	"planets-server/internal/shared/buildinfo"
 	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/lifecycle"
 	"planets-server/internal/shared/logger"
 	"planets-server/internal/shared/redis"
 	"planets-server/internal/spatial"
+	"planets-server/internal/visibility"
+)
+
+// Stamped at build time with -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..."
+var (
+	version   string
+	commit    string
+	buildTime string
 )
 
 func main() {
+	buildinfo.Init(version, commit, buildTime)
+
 	if err := config.Init(); err != nil {
 		slog.Error("Failed to initialize configuration", "error", err)
 		os.Exit(1)
@@ -32,7 +49,11 @@ func main() {
 	logger.Init()
 
 	logger := slog.With("component", "main")
+	build := buildinfo.Get()
 	logger.Info("Starting Planets! server",
+		"version", build.Version,
+		"commit", build.Commit,
+		"build_time", build.BuildTime,
 		"environment", cfg.Server.Environment,
 		"port", cfg.Server.Port,
 	)
@@ -50,7 +71,9 @@ func main() {
 		}
 	}()
 
-	auth.InitStateManager(redisClient)
+	auth.InitStateManager(redisClient, auth.StateManagerConfig{
+		StrictUserAgent: cfg.Auth.StrictStateUserAgent,
+	})
 
 	oauthConfig := initOAuth()
 
@@ -65,7 +88,20 @@ func main() {
 		}
 	}()
 
-	if err := db.RunMigrations(); err != nil {
+	if err := runSelfCheck(db, redisClient); err != nil {
+		logger.Error("Startup self-check failed", "error", err)
+		os.Exit(1)
+	}
+
+	if steps := config.GlobalConfig.Server.MigrateDownSteps; steps > 0 {
+		if err := rollbackMigrations(db, steps); err != nil {
+			logger.Error("Failed to roll back migrations", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := initMigrations(db); err != nil {
 		logger.Error("Failed to run migrations", "error", err)
 		os.Exit(1)
 	}
@@ -74,30 +110,77 @@ func main() {
 	playerRepo := player.NewRepository(db)
 	spatialRepo := spatial.NewRepository(db)
 	planetRepo := planet.NewRepository(db)
+	visibilityRepo := visibility.NewRepository(db)
 
 	authService := auth.NewService(authRepo)
+	auth.InitRevocationChecker(authService)
 	playerService := player.NewService(playerRepo)
-	spatialService := spatial.NewService(spatialRepo)
-	planetService := planet.NewService(planetRepo)
+	visibilityService := visibility.NewService(visibilityRepo)
+	spatialService := spatial.NewService(spatialRepo, visibilityService)
+	eventHub := events.NewHub(events.HubConfig{
+		BufferSize:            cfg.Events.SubscriberBuffer,
+		DisconnectSlow:        cfg.Events.SlowSubscriberPolicy == config.EventsSlowSubscriberDisconnect,
+		MaxSubscribers:        cfg.Events.MaxSubscribers,
+		MaxSubscribersPerGame: cfg.Events.MaxSubscribersPerGame,
+	})
+	planetService := planet.NewService(planetRepo, events.Fanout{eventHub, initEventPublisher(redisClient)}, visibilityService)
 
 	gameRepo := game.NewRepository(db)
-	gameService := game.NewService(gameRepo, spatialService, planetService)
+	buildingRepo := building.NewRepository(db)
+	buildingService := building.NewService(buildingRepo)
+
+	researchTree, err := research.LoadTree(cfg.Game.ResearchTreeFile)
+	if err != nil {
+		logger.Error("Failed to load research tree", "error", err)
+		os.Exit(1)
+	}
+	researchRepo := research.NewRepository(db)
+	researchService := research.NewService(researchRepo, researchTree)
+
+	gameService := game.NewService(gameRepo, spatialService, planetService, buildingService, researchService)
+
+	// Background workers register here and are closed in reverse order once the server drains
+	shutdown := lifecycle.NewRegistry()
+	shutdown.Register("oauth_state_manager", lifecycle.CloserFunc(auth.CloseStateManager))
+
+	tokenPruner := auth.NewTokenPruner(authService, cfg.Auth.TokenPruneInterval)
+	tokenPruner.Start()
+	shutdown.Register("token_pruner", tokenPruner)
+
+	gameScheduler := game.NewScheduler(gameService, cfg.Game.SchedulerInterval)
+	gameScheduler.Start()
+	shutdown.Register("game_scheduler", gameScheduler)
+
+	turnScheduler := game.NewTurnScheduler(gameService, cfg.Game.TurnSchedulerInterval)
+	turnScheduler.Start()
+	shutdown.Register("turn_scheduler", turnScheduler)
 
 	cors := initCORS()
 	rateLimiter := initRateLimiter()
 
-	routes := server.NewRoutes(db, playerService, authService, gameService, spatialService, planetService, oauthConfig, logger)
+	shutdown.Register("rate_limiter", rateLimiter)
+
+	routes := server.NewRoutes(db, playerService, authService, gameService, spatialService, planetService, visibilityService, buildingService, researchService, eventHub, oauthConfig, rateLimiter, logger)
 	mux := routes.Setup()
+	shutdown.Register("routes", routes)
+
+	concurrencyLimiter := initConcurrencyLimiter(mux)
 
 	var handler http.Handler = mux
+	handler = concurrencyLimiter.Middleware(handler)
 	handler = rateLimiter.Middleware(handler)
 	handler = cors.Middleware(handler)
+	handler = middleware.Metrics(mux)(handler)
+	handler = middleware.NewAccessLogger(cfg.Logging.AccessLogExcludePaths).Middleware(handler)
+	handler = middleware.RequestID(handler)
 
 	httpServer := createHTTPServer(handler)
+	// Event streams only end when the client leaves, so close them for the server to drain
+	httpServer.RegisterOnShutdown(eventHub.Close)
 
 	go startServer(httpServer, logger)
 
-	waitForShutdown(httpServer, logger)
+	waitForShutdown(httpServer, shutdown, logger)
 }
 
 func initRedis() (*redis.Client, error) {
@@ -156,6 +239,107 @@ func initDatabase() (*database.DB, error) {
 	return db, nil
 }
 
+func runSelfCheck(db *database.DB, redisClient *redis.Client) error {
+	cfg := config.GlobalConfig
+	logger := slog.With("component", "self_check")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	probes := []config.Probe{
+		{Name: "database", Fatal: true, Check: db.PingContext},
+	}
+	if redisClient != nil {
+		probes = append(probes, config.Probe{
+			Name:  "redis",
+			Fatal: true,
+			Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() },
+		})
+	}
+
+	report := cfg.SelfCheck(ctx, probes...)
+
+	for _, warning := range report.Warnings {
+		logger.Warn("Configuration warning", "issue", warning)
+	}
+	for _, problem := range report.Fatal {
+		logger.Error("Configuration problem", "issue", problem)
+	}
+
+	logger.Info("Startup self-check completed",
+		"warnings", len(report.Warnings),
+		"fatal", len(report.Fatal),
+	)
+
+	if report.HasFatal() {
+		return fmt.Errorf("%d fatal configuration problem(s)", len(report.Fatal))
+	}
+
+	return nil
+}
+
+func initMigrations(db *database.DB) error {
+	cfg := config.GlobalConfig
+	logger := slog.With("component", "migrations", "operation", "init")
+
+	if cfg.Server.RunMigrations {
+		_, err := db.RunMigrations(context.Background())
+		return err
+	}
+
+	logger.Warn("RUN_MIGRATIONS is disabled, skipping migrations at startup; use POST /api/admin/migrations/run to apply them")
+
+	pending, err := db.PendingMigrations(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		logger.Warn("Database schema is behind, serving with pending migrations",
+			"pending_count", len(pending),
+			"pending", pending,
+		)
+	} else {
+		logger.Info("Database schema is up to date")
+	}
+
+	return nil
+}
+
+// rollbackMigrations reverts the last steps migrations, for an operator run with MIGRATE_DOWN_STEPS
+func rollbackMigrations(db *database.DB, steps int) error {
+	logger := slog.With("component", "migrations", "operation", "rollback")
+	logger.Warn("MIGRATE_DOWN_STEPS is set, rolling back migrations and exiting", "steps", steps)
+
+	for i := 0; i < steps; i++ {
+		version, err := db.RollbackMigration(context.Background())
+		if err != nil {
+			return err
+		}
+		if version == "" {
+			logger.Info("No more migrations to roll back", "rolled_back", i)
+			return nil
+		}
+		logger.Info("Rolled back migration", "migration", version)
+	}
+
+	logger.Info("Rollback completed", "rolled_back", steps)
+	return nil
+}
+
+func initEventPublisher(redisClient *redis.Client) events.Publisher {
+	cfg := config.GlobalConfig.Events
+	logger := slog.With("component", "events")
+
+	if cfg.Publisher == config.EventsPublisherRedis && redisClient != nil {
+		logger.Info("Publishing domain events to Redis stream", "stream", cfg.Stream)
+		return events.NewRedisStreamPublisher(redisClient, cfg.Stream, cfg.StreamMaxLen)
+	}
+
+	logger.Debug("Domain event publishing disabled")
+	return events.NoopPublisher{}
+}
+
 func initCORS() *middleware.CORSMiddleware {
 	return middleware.NewCORS()
 }
@@ -168,7 +352,11 @@ func initRateLimiter() *middleware.RateLimiter {
 	rateLimitConfig := middleware.RateLimitConfig{
 		RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
 		BurstSize:         cfg.RateLimit.BurstSize,
-		TrustProxy:        cfg.RateLimit.TrustProxy,
+		TrustedProxies:    cfg.RateLimit.TrustedProxies,
+		AdminBypass:       cfg.RateLimit.AdminBypass,
+		MaxClients:        cfg.RateLimit.MaxClients,
+		CleanupInterval:   cfg.RateLimit.CleanupInterval,
+		ClientTTL:         cfg.RateLimit.ClientTTL,
 	}
 
 	rateLimiter := middleware.NewRateLimiter(rateLimitConfig)
@@ -176,11 +364,40 @@ func initRateLimiter() *middleware.RateLimiter {
 	logger.Info("Rate limiting middleware configured",
 		"requests_per_second", rateLimitConfig.RequestsPerSecond,
 		"burst_size", rateLimitConfig.BurstSize,
+		"admin_bypass", rateLimitConfig.AdminBypass,
+		"max_clients", rateLimitConfig.MaxClients,
+		"client_ttl", rateLimitConfig.ClientTTL,
 	)
 
 	return rateLimiter
 }
 
+func initConcurrencyLimiter(mux *http.ServeMux) *middleware.ConcurrencyLimiter {
+	cfg := config.GlobalConfig
+	logger := slog.With("component", "concurrency_limit", "operation", "init")
+	logger.Debug("Setting up concurrency limiting middleware")
+
+	concurrencyConfig := middleware.ConcurrencyLimitConfig{
+		MaxRequests:      cfg.RateLimit.MaxConcurrent,
+		MaxRequestsPerIP: cfg.RateLimit.MaxConcurrentPerIP,
+		TrustedProxies:   cfg.RateLimit.TrustedProxies,
+		// Event streams would hold a slot for as long as they are open, the event hub caps them instead
+		Exempt: func(r *http.Request) bool {
+			_, pattern := mux.Handler(r)
+			return pattern == server.EventStreamRoute
+		},
+	}
+
+	concurrencyLimiter := middleware.NewConcurrencyLimiter(concurrencyConfig)
+
+	logger.Info("Concurrency limiting middleware configured",
+		"max_requests", concurrencyConfig.MaxRequests,
+		"max_requests_per_ip", concurrencyConfig.MaxRequestsPerIP,
+	)
+
+	return concurrencyLimiter
+}
+
 func createHTTPServer(handler http.Handler) *http.Server {
 	cfg := config.GlobalConfig
 	port := cfg.Server.Port
@@ -207,7 +424,9 @@ func startServer(server *http.Server, logger *slog.Logger) {
 	}
 }
 
-func waitForShutdown(server *http.Server, logger *slog.Logger) {
+// waitForShutdown drains the HTTP server on SIGINT or SIGTERM, then closes the registered
+// background workers. The database and Redis are closed by main's deferred calls afterwards.
+func waitForShutdown(server *http.Server, shutdown *lifecycle.Registry, logger *slog.Logger) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -222,5 +441,9 @@ func waitForShutdown(server *http.Server, logger *slog.Logger) {
 		os.Exit(1)
 	}
 
+	if err := shutdown.Close(ctx); err != nil {
+		logger.Error("Background workers did not stop cleanly", "error", err)
+	}
+
 	logger.Info("Server exited gracefully")
 }
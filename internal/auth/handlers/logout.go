@@ -1,22 +1,45 @@
 package handlers
 
 import (
-	"log/slog"
 	"net/http"
+	"planets-server/internal/auth"
 	"planets-server/internal/shared/cookies"
+	"planets-server/internal/shared/logger"
 )
 
-type LogoutHandler struct{}
+type LogoutHandler struct {
+	authService *auth.Service
+}
 
-func NewLogoutHandler() *LogoutHandler {
-	return &LogoutHandler{}
+func NewLogoutHandler(authService *auth.Service) *LogoutHandler {
+	return &LogoutHandler{authService: authService}
 }
 
 func (h *LogoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	logger := slog.With("handler", "logout", "remote_addr", r.RemoteAddr)
+	logger := logger.FromContext(r.Context()).With("handler", "logout", "remote_addr", r.RemoteAddr)
 	logger.Debug("Logout requested")
 
+	// Revoke the session so its refresh token can't be used after logout. An invalid
+	// or already revoked token has nothing left to revoke, and logout still succeeds.
+	if cookie, err := r.Cookie(cookies.RefreshCookieName); err == nil {
+		if claims, err := auth.ValidateRefreshToken(cookie.Value); err == nil {
+			if err := h.authService.RevokeRefreshToken(r.Context(), claims.ID); err != nil {
+				logger.Error("Failed to revoke refresh token", "error", err)
+			}
+		}
+	}
+
+	// Blacklist the access token for the rest of its lifetime, in case it leaked before logout
+	if token, ok := auth.TokenFromRequest(r); ok {
+		if claims, err := auth.ValidateJWT(token); err == nil && claims.ID != "" && claims.ExpiresAt != nil {
+			if err := h.authService.RevokeToken(r.Context(), claims.ID, claims.ExpiresAt.Time); err != nil {
+				logger.Error("Failed to revoke access token", "error", err)
+			}
+		}
+	}
+
 	cookies.ClearAuthCookie(w)
+	cookies.ClearRefreshCookie(w)
 
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write([]byte("Logged out")); err != nil {
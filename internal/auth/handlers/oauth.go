@@ -3,18 +3,29 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"net/http"
 	"time"
 
 	"planets-server/internal/auth"
 	"planets-server/internal/auth/providers"
 	"planets-server/internal/player"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/cookies"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
 	"planets-server/internal/shared/response"
+
+	"golang.org/x/oauth2"
 )
 
+// TokenResponse hands the tokens to clients that can't use cookies, when AUTH_CALLBACK_RETURNS_TOKENS is set
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
 type OAuthHandler struct {
 	provider      providers.OAuthProvider
 	playerService *player.Service
@@ -33,7 +44,7 @@ func NewOAuthHandler(provider providers.OAuthProvider, playerService *player.Ser
 
 func (h *OAuthHandler) HandleAuth(w http.ResponseWriter, r *http.Request) {
 	name := h.provider.Name()
-	logger := slog.With("handler", name+"_oauth_init")
+	logger := logger.FromContext(r.Context()).With("handler", name+"_oauth_init")
 
 	if !h.isConfigured {
 		response.Error(w, r, logger, errors.External(fmt.Sprintf("%s OAuth is not properly configured", name)))
@@ -42,13 +53,18 @@ func (h *OAuthHandler) HandleAuth(w http.ResponseWriter, r *http.Request) {
 
 	redirectURI := resolveRedirectURI(r.URL.Query().Get("redirect_uri"))
 
-	state, err := auth.GenerateOAuthState(name, r.UserAgent(), redirectURI)
+	var codeVerifier string
+	if h.provider.UsesPKCE() {
+		codeVerifier = oauth2.GenerateVerifier()
+	}
+
+	state, err := auth.GenerateOAuthState(name, r.UserAgent(), redirectURI, codeVerifier)
 	if err != nil {
 		response.Error(w, r, logger, errors.WrapInternal("failed to initialize OAuth flow", err))
 		return
 	}
 
-	authURL := h.provider.GetAuthURL(state)
+	authURL := h.provider.GetAuthURL(state, codeVerifier)
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
 }
 
@@ -58,7 +74,7 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	state := r.URL.Query().Get("state")
 	errorParam := r.URL.Query().Get("error")
 
-	logger := slog.With(
+	logger := logger.FromContext(r.Context()).With(
 		"handler", name+"_oauth_callback",
 		"user_agent", r.UserAgent(),
 		"ip", r.RemoteAddr,
@@ -69,9 +85,11 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	// Try to recover redirect URI from state even in early-exit cases.
 	// Falls back to FRONTEND_CLIENT_URL if state is missing or invalid.
 	redirectURI := ""
+	codeVerifier := ""
 	if state != "" {
 		if entry, err := auth.ValidateOAuthState(state, name, r.UserAgent()); err == nil {
 			redirectURI = entry.RedirectURI
+			codeVerifier = entry.CodeVerifier
 		}
 	}
 
@@ -94,7 +112,7 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	token, err := h.provider.ExchangeCode(ctx, code)
+	token, err := h.provider.ExchangeCode(ctx, code, codeVerifier)
 	if err != nil {
 		logger.Error("Failed to exchange authorization code",
 			"error", err,
@@ -118,10 +136,13 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 		"provider_user_id", userInfo.ID,
 		"user_name", userInfo.Name)
 
-	if userInfo.Email == "" || !userInfo.EmailVerified {
-		userLogger.Error("User missing verified email", "provider", name)
-		redirectWithError(w, r, redirectURI, "oauth_error")
-		return
+	emailVerified := userInfo.Email != "" && userInfo.EmailVerified
+	if !emailVerified {
+		if err := player.CheckUnverifiedEmail(); err != nil {
+			userLogger.Error("User missing verified email", "provider", name)
+			redirectWithError(w, r, redirectURI, "oauth_error")
+			return
+		}
 	}
 
 	userLogger.Info("Creating or finding player account", "provider", name)
@@ -142,25 +163,54 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 			redirectWithError(w, r, redirectURI, "database_error")
 			return
 		}
+
+		if emailVerified && player.IsPlaceholderEmail(p.Email) {
+			userLogger.Info("Replacing placeholder email with verified provider email")
+			p, err = h.playerService.ReplacePlaceholderEmail(ctx, p, userInfo.Email)
+			if err != nil {
+				userLogger.Error("Failed to replace placeholder email", "error", err)
+				redirectWithError(w, r, redirectURI, "database_error")
+				return
+			}
+		}
 	} else {
-		userLogger.Debug("No existing OAuth link found, finding or creating player by email")
-		p, err = h.playerService.FindOrCreatePlayerByOAuth(
+		if !emailVerified {
+			userLogger.Warn("No verified email from provider, creating player with placeholder email")
+		} else {
+			userLogger.Debug("No existing OAuth link found, finding or creating player by email")
+		}
+		p, err = h.playerService.CreateOAuthPlayer(
 			ctx,
 			name,
 			userInfo.ID,
 			userInfo.Email,
+			emailVerified,
 			userInfo.Name,
 			&userInfo.AvatarURL,
 		)
+	}
+
+	if existingPlayerID == 0 {
 		if err != nil {
+			if errors.GetCode(err) == errors.CodeRegistrationDenied {
+				userLogger.Warn("Registration denied", "reason", err.Error())
+				redirectWithError(w, r, redirectURI, "registration_denied")
+				return
+			}
 			userLogger.Error("Failed to create player", "error", err)
 			redirectWithError(w, r, redirectURI, "database_error")
 			return
 		}
 
 		userLogger.Debug("Linking OAuth provider to player account")
-		err = h.authService.CreateAuthProvider(ctx, p.ID, name, userInfo.ID, userInfo.Email)
+		// An unverified provider email is never stored, so it cannot be mistaken for the player's own
+		err = h.authService.CreateAuthProvider(ctx, p.ID, name, userInfo.ID, p.Email)
 		if err != nil {
+			if errors.GetType(err) == errors.ErrorTypeConflict {
+				userLogger.Warn("OAuth account already linked", "error", err)
+				redirectWithError(w, r, redirectURI, "account_already_linked")
+				return
+			}
 			userLogger.Error("Failed to create auth provider link", "error", err)
 			redirectWithError(w, r, redirectURI, "database_error")
 			return
@@ -169,21 +219,32 @@ func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 
 	playerLogger := userLogger.With("player_id", p.ID)
 
-	playerLogger.Debug("Generating JWT token for player")
-	jwtToken, err := auth.GenerateJWT(p.ID, p.Username, p.Email, p.Role.String())
+	playerLogger.Debug("Issuing tokens for player")
+	accessToken, refreshToken, err := h.authService.IssueTokens(ctx, p.ID, p.Username, p.Email, p.Role.String())
 	if err != nil {
-		playerLogger.Error("Failed to generate JWT token", "error", err)
+		playerLogger.Error("Failed to issue tokens", "error", err)
 		redirectWithError(w, r, redirectURI, "auth_error")
 		return
 	}
 
-	cookies.SetAuthCookie(w, jwtToken)
+	cookies.SetAuthCookie(w, accessToken)
+	cookies.SetRefreshCookie(w, refreshToken)
 
 	playerLogger.Info("OAuth authentication successful",
 		"provider", name,
 		"player_username", p.Username,
 		"player_role", p.Role)
 
+	if config.GlobalConfig.Auth.CallbackReturnsTokens {
+		response.Success(w, http.StatusOK, TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(config.GlobalConfig.Auth.AccessTokenExpiration.Seconds()),
+		})
+		return
+	}
+
 	successURL := fmt.Sprintf("%s/auth/callback?success=true", redirectURI)
 	http.Redirect(w, r, successURL, http.StatusTemporaryRedirect)
 }
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type ProvidersHandler struct {
+	oauthConfig *auth.OAuthConfig
+}
+
+func NewProvidersHandler(oauthConfig *auth.OAuthConfig) *ProvidersHandler {
+	return &ProvidersHandler{oauthConfig: oauthConfig}
+}
+
+func (h *ProvidersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "auth_providers")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	response.Success(w, http.StatusOK, h.oauthConfig.EnabledProviders())
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/cookies"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type RefreshHandler struct {
+	authService *auth.Service
+}
+
+func NewRefreshHandler(authService *auth.Service) *RefreshHandler {
+	return &RefreshHandler{authService: authService}
+}
+
+// RefreshResponse tells the client how long the new access token lasts
+type RefreshResponse struct {
+	ExpiresIn int `json:"expires_in"`
+}
+
+func (h *RefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "refresh_token")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	cookie, err := r.Cookie(cookies.RefreshCookieName)
+	if err != nil || cookie.Value == "" {
+		response.Error(w, r, logger, errors.Unauthorized("refresh token required"))
+		return
+	}
+
+	access, refresh, err := h.authService.Refresh(r.Context(), cookie.Value)
+	if err != nil {
+		// A rejected refresh token is dead for good, so drop it along with any stale access cookie
+		if errors.GetType(err) == errors.ErrorTypeUnauthorized {
+			cookies.ClearAuthCookie(w)
+			cookies.ClearRefreshCookie(w)
+		}
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	cookies.SetAuthCookie(w, access)
+	cookies.SetRefreshCookie(w, refresh)
+
+	response.Success(w, http.StatusOK, RefreshResponse{
+		ExpiresIn: int(config.GlobalConfig.Auth.AccessTokenExpiration.Seconds()),
+	})
+}
@@ -1,15 +1,38 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// RevocationChecker reports whether an access token has been revoked before its expiry
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+var revocationChecker RevocationChecker
+
+// InitRevocationChecker registers the store ValidateJWT consults for revoked tokens.
+// Until it is called, tokens are checked for signature and expiry only.
+func InitRevocationChecker(checker RevocationChecker) {
+	var ttl time.Duration
+	if cfg := config.GlobalConfig; cfg != nil {
+		ttl = cfg.Cache.RevocationTTL
+	}
+
+	revocationChecker = checker
+	revocations = newRevocationCache(ttl, maxRevocationCacheEntries)
+}
+
 func GenerateJWT(playerID int, username, email, role string) (string, error) {
 	cfg := config.GlobalConfig
 	logger := slog.With(
@@ -21,13 +44,21 @@ func GenerateJWT(playerID int, username, email, role string) (string, error) {
 	)
 	logger.Debug("Generating JWT token for player")
 
-	expiresAt := time.Now().Add(cfg.Auth.TokenExpiration)
+	tokenID, err := utils.NewUUID()
+	if err != nil {
+		logger.Error("Failed to generate JWT token ID", "error", err)
+		return "", fmt.Errorf("failed to generate JWT token ID: %w", err)
+	}
+
+	expiresAt := time.Now().Add(cfg.Auth.AccessTokenExpiration)
 	claims := Claims{
-		PlayerID: playerID,
-		Username: username,
-		Email:    email,
-		Role:     role,
+		PlayerID:  playerID,
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   fmt.Sprintf("player_%d", playerID),
@@ -45,34 +76,160 @@ func GenerateJWT(playerID int, username, email, role string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateJWT validates a JWT token and returns claims - used by middleware
-func ValidateJWT(tokenString string) (*Claims, error) {
+// GenerateTokenPair issues a short-lived access token together with a refresh token that
+// can be exchanged for a new pair. The refresh token's ID claim is a random opaque ID; the
+// caller is responsible for persisting it so the token can be rotated and revoked.
+func GenerateTokenPair(playerID int, username, email, role string) (access, refresh string, err error) {
 	cfg := config.GlobalConfig
-	logger := slog.With("component", "jwt", "operation", "validate")
-	logger.Debug("Validating JWT token")
+	logger := slog.With("component", "jwt", "operation", "generate_pair", "player_id", playerID)
+
+	access, err = GenerateJWT(playerID, username, email, role)
+	if err != nil {
+		return "", "", err
+	}
+
+	idBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		logger.Error("Failed to generate refresh token ID", "error", err)
+		return "", "", fmt.Errorf("failed to generate refresh token ID: %w", err)
+	}
+
+	now := time.Now()
+	claims := RefreshClaims{
+		PlayerID:  playerID,
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(idBytes),
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.Auth.RefreshTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   fmt.Sprintf("player_%d", playerID),
+		},
+	}
+
+	refresh, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Auth.JWTSecret))
+	if err != nil {
+		logger.Error("Failed to sign refresh token", "error", err)
+		return "", "", fmt.Errorf("failed to sign refresh token: %w", err)
+	}
+
+	logger.Debug("Token pair generated successfully")
+	return access, refresh, nil
+}
+
+// ValidateRefreshToken checks a refresh token's signature and expiry and returns its claims.
+// Whether it has been rotated or revoked is up to the caller to check against the database.
+func ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	logger := slog.With("component", "jwt", "operation", "validate_refresh")
+
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, signingKey(logger))
+	if err != nil {
+		logger.Debug("Refresh token validation failed", "error", err)
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*RefreshClaims)
+	if !ok || !token.Valid || claims.TokenType != TokenTypeRefresh || claims.ID == "" {
+		return nil, fmt.Errorf("invalid refresh token claims")
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+	return claims, nil
+}
+
+// signingKey returns the key lookup shared by token validation, accepting only HMAC-signed tokens
+func signingKey(logger *slog.Logger) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			logger.Error("Unexpected JWT signing method", "method", token.Header["alg"])
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(cfg.Auth.JWTSecret), nil
-	})
+		return []byte(config.GlobalConfig.Auth.JWTSecret), nil
+	}
+}
+
+// ParseJWT checks an access token's signature, expiry and type without consulting the
+// revocation list. It only tells who a request claims to come from, as the rate limiter needs;
+// anything granting access must use ValidateJWT.
+func ParseJWT(tokenString string) (*Claims, error) {
+	logger := slog.With("component", "jwt", "operation", "parse")
 
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, signingKey(logger))
 	if err != nil {
-		logger.Warn("JWT token validation failed", "error", err)
 		return nil, fmt.Errorf("invalid JWT token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		logger.Debug("JWT token validated successfully",
-			"player_id", claims.PlayerID,
-			"username", claims.Username,
-			"role", claims.Role,
-			"expires_at", claims.ExpiresAt.Time)
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.TokenType == TokenTypeRefresh {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+// ValidateJWT validates a JWT token and returns claims - used by middleware
+func ValidateJWT(tokenString string) (*Claims, error) {
+	logger := slog.With("component", "jwt", "operation", "validate")
+	logger.Debug("Validating JWT token")
+
+	claims, err := ParseJWT(tokenString)
+	if err != nil {
+		logger.Warn("JWT token validation failed", "error", err)
+		return nil, err
+	}
+
+	if err := checkRevoked(claims, logger); err != nil {
+		return nil, err
 	}
 
-	logger.Error("JWT token claims are invalid")
-	return nil, fmt.Errorf("invalid token claims")
+	logger.Debug("JWT token validated successfully",
+		"player_id", claims.PlayerID,
+		"username", claims.Username,
+		"role", claims.Role,
+		"expires_at", claims.ExpiresAt.Time)
+	return claims, nil
+}
+
+// checkRevoked rejects tokens on the revocation list. Tokens issued before jti claims were
+// added carry no ID and can only expire. If the list can't be read the token is rejected,
+// since letting a possibly revoked token through defeats the point of revoking it.
+// Answers are cached briefly, see revocationCache.
+func checkRevoked(claims *Claims, logger *slog.Logger) error {
+	jti := claims.ID
+	if revocationChecker == nil || jti == "" {
+		return nil
+	}
+
+	now := time.Now()
+	revoked, cached := revocations.get(jti, now)
+	if !cached {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		var err error
+		revoked, err = revocationChecker.IsTokenRevoked(ctx, jti)
+		if err != nil {
+			logger.Error("Failed to check JWT token revocation", "error", err)
+			return fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		revocations.set(jti, revoked, tokenExpiry(claims, now), now)
+	}
+
+	if revoked {
+		logger.Warn("Revoked JWT token presented", "jti", jti)
+		return fmt.Errorf("token has been revoked")
+	}
+
+	return nil
+}
+
+// CheckRevoked rejects claims whose token is on the revocation list. It is for callers that
+// got the claims from ParseJWT and are about to grant something on their strength.
+func CheckRevoked(claims *Claims) error {
+	return checkRevoked(claims, slog.With("component", "jwt", "operation", "check_revoked"))
+}
+
+func tokenExpiry(claims *Claims, now time.Time) time.Time {
+	if claims.ExpiresAt == nil {
+		return now
+	}
+	return claims.ExpiresAt.Time
 }
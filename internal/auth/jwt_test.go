@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"planets-server/internal/shared/config"
+)
+
+// countingChecker answers every lookup with revoked and counts the lookups
+type countingChecker struct {
+	revoked bool
+	calls   atomic.Int32
+}
+
+func (c *countingChecker) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	c.calls.Add(1)
+	return c.revoked, nil
+}
+
+// useRevocationChecker installs checker with the given cache TTL for the rest of the test
+func useRevocationChecker(t *testing.T, checker RevocationChecker, ttl time.Duration) {
+	t.Helper()
+
+	previousConfig := config.GlobalConfig
+	config.GlobalConfig = &config.Config{
+		Auth: config.AuthConfig{
+			JWTSecret:             "a-test-secret-that-is-long-enough-for-hs256",
+			AccessTokenExpiration: 15 * time.Minute,
+		},
+		Cache: config.CacheConfig{RevocationTTL: ttl},
+	}
+	previousChecker, previousCache := revocationChecker, revocations
+	InitRevocationChecker(checker)
+
+	t.Cleanup(func() {
+		config.GlobalConfig = previousConfig
+		revocationChecker, revocations = previousChecker, previousCache
+	})
+}
+
+func generateTestToken(t *testing.T) string {
+	t.Helper()
+
+	token, err := GenerateJWT(7, "agent", "agent@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestParseJWTSkipsTheRevocationLookup(t *testing.T) {
+	checker := &countingChecker{revoked: true}
+	useRevocationChecker(t, checker, time.Minute)
+	token := generateTestToken(t)
+
+	claims, err := ParseJWT(token)
+	if err != nil || claims.PlayerID != 7 {
+		t.Fatalf("ParseJWT() = %+v, %v; want the token's claims", claims, err)
+	}
+	if calls := checker.calls.Load(); calls != 0 {
+		t.Fatalf("ParseJWT() made %d revocation lookups, want none", calls)
+	}
+
+	if _, err := ValidateJWT(token); err == nil {
+		t.Fatal("ValidateJWT() accepted a revoked token")
+	}
+}
+
+func TestParseJWTRejectsBadTokens(t *testing.T) {
+	useRevocationChecker(t, &countingChecker{}, 0)
+	token := generateTestToken(t)
+
+	for _, bad := range []string{"", "not-a-token", token + "x"} {
+		if _, err := ParseJWT(bad); err == nil {
+			t.Errorf("ParseJWT(%q) accepted a bad token", bad)
+		}
+	}
+
+	_, refresh, err := GenerateTokenPair(7, "agent", "agent@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJWT(refresh); err == nil {
+		t.Error("ParseJWT() accepted a refresh token")
+	}
+}
+
+func TestValidateJWTCachesRevocationLookups(t *testing.T) {
+	checker := &countingChecker{}
+	useRevocationChecker(t, checker, time.Minute)
+	token := generateTestToken(t)
+
+	for i := 0; i < 5; i++ {
+		if _, err := ValidateJWT(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := checker.calls.Load(); calls != 1 {
+		t.Fatalf("5 validations made %d revocation lookups, want 1", calls)
+	}
+}
+
+func TestValidateJWTWithoutCacheLooksUpEveryTime(t *testing.T) {
+	checker := &countingChecker{}
+	useRevocationChecker(t, checker, 0)
+	token := generateTestToken(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := ValidateJWT(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := checker.calls.Load(); calls != 3 {
+		t.Fatalf("3 validations made %d revocation lookups, want 3", calls)
+	}
+}
+
+func TestValidateJWTRemembersRevokedTokens(t *testing.T) {
+	checker := &countingChecker{revoked: true}
+	useRevocationChecker(t, checker, 0)
+	token := generateTestToken(t)
+
+	// Even with "not revoked" answers uncached, a revocation is final
+	for i := 0; i < 3; i++ {
+		if _, err := ValidateJWT(token); err == nil {
+			t.Fatal("ValidateJWT() accepted a revoked token")
+		}
+	}
+	if calls := checker.calls.Load(); calls != 1 {
+		t.Fatalf("3 validations of a revoked token made %d lookups, want 1", calls)
+	}
+}
+
+func TestRevocationCache(t *testing.T) {
+	now := time.Now()
+	expiry := now.Add(time.Hour)
+	cache := newRevocationCache(time.Minute, 3)
+
+	cache.set("valid", false, expiry, now)
+	cache.set("revoked", true, expiry, now)
+	cache.set("expiring", false, now.Add(10*time.Second), now)
+
+	if revoked, ok := cache.get("valid", now.Add(59*time.Second)); !ok || revoked {
+		t.Fatalf("valid token within the TTL: got %v, %v", revoked, ok)
+	}
+	if _, ok := cache.get("valid", now.Add(time.Minute)); ok {
+		t.Fatal("valid token answer outlived the TTL")
+	}
+	if _, ok := cache.get("expiring", now.Add(10*time.Second)); ok {
+		t.Fatal("answer outlived the token itself")
+	}
+	if revoked, ok := cache.get("revoked", now.Add(59*time.Minute)); !ok || !revoked {
+		t.Fatalf("revoked token before its expiry: got %v, %v", revoked, ok)
+	}
+
+	// Full of live entries, a new token is simply not cached
+	cache.set("a", false, expiry, now)
+	cache.set("b", false, expiry, now)
+	cache.set("c", false, expiry, now)
+	if cache.len() != 3 {
+		t.Fatalf("cache holds %d entries, want at most 3", cache.len())
+	}
+	if _, ok := cache.get("c", now); ok {
+		t.Fatal("cache grew past its bound")
+	}
+
+	// Expired entries make room
+	later := now.Add(2 * time.Minute)
+	cache.set("d", false, expiry, later)
+	if _, ok := cache.get("d", later); !ok {
+		t.Fatal("expired entries were not swept to make room")
+	}
+}
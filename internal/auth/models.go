@@ -6,14 +6,36 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token types, so a refresh token can never be presented as an access token
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 type Claims struct {
-	PlayerID int    `json:"player_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	PlayerID  int    `json:"player_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// RefreshClaims identify a stored refresh token; the ID claim is the refresh_tokens row ID
+type RefreshClaims struct {
+	PlayerID  int    `json:"player_id"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// ProviderInfo describes a configured OAuth provider for rendering login buttons
+type ProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	AuthURL     string `json:"auth_url"`
+	Icon        string `json:"icon"`
+}
+
 type PlayerAuthProvider struct {
 	ID             int       `json:"id"`
 	PlayerID       int       `json:"player_id"`
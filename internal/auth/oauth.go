@@ -73,11 +73,41 @@ func InitOAuth() *OAuthConfig {
 	}
 
 	return &OAuthConfig{
-		GoogleProvider:    providers.NewGoogleProvider(googleConfig),
-		GitHubProvider:    providers.NewGitHubProvider(githubConfig),
-		DiscordProvider:   providers.NewDiscordProvider(discordConfig),
+		GoogleProvider:    providers.NewGoogleProvider(googleConfig, cfg.OAuth.Google.PKCE),
+		GitHubProvider:    providers.NewGitHubProvider(githubConfig, cfg.OAuth.GitHub.PKCE),
+		DiscordProvider:   providers.NewDiscordProvider(discordConfig, cfg.OAuth.Discord.PKCE),
 		GoogleConfigured:  googleConfigured,
 		GitHubConfigured:  githubConfigured,
 		DiscordConfigured: discordConfigured,
 	}
 }
+
+// EnabledProviders lists the configured providers, skipping any without credentials
+func (c *OAuthConfig) EnabledProviders() []ProviderInfo {
+	serverURL := config.GlobalConfig.Server.URL
+
+	candidates := []struct {
+		provider   providers.OAuthProvider
+		configured bool
+	}{
+		{c.GoogleProvider, c.GoogleConfigured},
+		{c.GitHubProvider, c.GitHubConfigured},
+		{c.DiscordProvider, c.DiscordConfigured},
+	}
+
+	enabled := []ProviderInfo{}
+	for _, candidate := range candidates {
+		if !candidate.configured {
+			continue
+		}
+		name := candidate.provider.Name()
+		enabled = append(enabled, ProviderInfo{
+			Name:        name,
+			DisplayName: candidate.provider.DisplayName(),
+			AuthURL:     serverURL + "/auth/" + name,
+			Icon:        name,
+		})
+	}
+
+	return enabled
+}
@@ -41,23 +41,28 @@ func (u *discordAPIResponse) displayName() string {
 
 type DiscordProvider struct {
 	config *oauth2.Config
+	pkce   bool
 }
 
-func NewDiscordProvider(config *oauth2.Config) *DiscordProvider {
-	return &DiscordProvider{config: config}
+func NewDiscordProvider(config *oauth2.Config, pkce bool) *DiscordProvider {
+	return &DiscordProvider{config: config, pkce: pkce}
 }
 
 func (p *DiscordProvider) Name() string { return "discord" }
 
-func (p *DiscordProvider) GetAuthURL(state string) string {
-	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (p *DiscordProvider) DisplayName() string { return "Discord" }
+
+func (p *DiscordProvider) UsesPKCE() bool { return p.pkce }
+
+func (p *DiscordProvider) GetAuthURL(state, verifier string) string {
+	return p.config.AuthCodeURL(state, authCodeOptions(verifier)...)
 }
 
-func (p *DiscordProvider) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+func (p *DiscordProvider) ExchangeCode(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
 	logger := slog.With("provider", "discord", "operation", "exchange_code")
 	logger.Debug("Exchanging authorization code for Discord access token")
 
-	token, err := p.config.Exchange(ctx, code)
+	token, err := p.config.Exchange(ctx, code, exchangeOptions(verifier)...)
 	if err != nil {
 		logger.Error("Failed to exchange Discord authorization code", "error", err)
 		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
@@ -20,23 +20,28 @@ type githubAPIResponse struct {
 
 type GitHubProvider struct {
 	config *oauth2.Config
+	pkce   bool
 }
 
-func NewGitHubProvider(config *oauth2.Config) *GitHubProvider {
-	return &GitHubProvider{config: config}
+func NewGitHubProvider(config *oauth2.Config, pkce bool) *GitHubProvider {
+	return &GitHubProvider{config: config, pkce: pkce}
 }
 
 func (p *GitHubProvider) Name() string { return "github" }
 
-func (p *GitHubProvider) GetAuthURL(state string) string {
-	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (p *GitHubProvider) DisplayName() string { return "GitHub" }
+
+func (p *GitHubProvider) UsesPKCE() bool { return p.pkce }
+
+func (p *GitHubProvider) GetAuthURL(state, verifier string) string {
+	return p.config.AuthCodeURL(state, authCodeOptions(verifier)...)
 }
 
-func (p *GitHubProvider) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+func (p *GitHubProvider) ExchangeCode(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
 	logger := slog.With("provider", "github", "operation", "exchange_code")
 	logger.Debug("Exchanging authorization code for GitHub access token")
 
-	token, err := p.config.Exchange(ctx, code)
+	token, err := p.config.Exchange(ctx, code, exchangeOptions(verifier)...)
 	if err != nil {
 		logger.Error("Failed to exchange GitHub authorization code", "error", err)
 		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
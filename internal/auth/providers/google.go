@@ -19,23 +19,28 @@ type googleAPIResponse struct {
 
 type GoogleProvider struct {
 	config *oauth2.Config
+	pkce   bool
 }
 
-func NewGoogleProvider(config *oauth2.Config) *GoogleProvider {
-	return &GoogleProvider{config: config}
+func NewGoogleProvider(config *oauth2.Config, pkce bool) *GoogleProvider {
+	return &GoogleProvider{config: config, pkce: pkce}
 }
 
 func (p *GoogleProvider) Name() string { return "google" }
 
-func (p *GoogleProvider) GetAuthURL(state string) string {
-	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (p *GoogleProvider) DisplayName() string { return "Google" }
+
+func (p *GoogleProvider) UsesPKCE() bool { return p.pkce }
+
+func (p *GoogleProvider) GetAuthURL(state, verifier string) string {
+	return p.config.AuthCodeURL(state, authCodeOptions(verifier)...)
 }
 
-func (p *GoogleProvider) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+func (p *GoogleProvider) ExchangeCode(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
 	logger := slog.With("provider", "google", "operation", "exchange_code")
 	logger.Debug("Exchanging authorization code for Google access token")
 
-	token, err := p.config.Exchange(ctx, code)
+	token, err := p.config.Exchange(ctx, code, exchangeOptions(verifier)...)
 	if err != nil {
 		logger.Error("Failed to exchange Google authorization code", "error", err)
 		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
@@ -18,7 +18,28 @@ type OAuthUser struct {
 // OAuthProvider is the interface that all OAuth providers implement.
 type OAuthProvider interface {
 	Name() string
-	GetAuthURL(state string) string
-	ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error)
+	DisplayName() string
+	// UsesPKCE reports whether the flow should send a code challenge and verifier
+	UsesPKCE() bool
+	// GetAuthURL and ExchangeCode take an empty verifier when PKCE is off
+	GetAuthURL(state, verifier string) string
+	ExchangeCode(ctx context.Context, code, verifier string) (*oauth2.Token, error)
 	GetUserInfo(ctx context.Context, token *oauth2.Token) (*OAuthUser, error)
 }
+
+// authCodeOptions builds the authorization URL options, adding the S256 challenge when a verifier is set
+func authCodeOptions(verifier string) []oauth2.AuthCodeOption {
+	opts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+	if verifier != "" {
+		opts = append(opts, oauth2.S256ChallengeOption(verifier))
+	}
+	return opts
+}
+
+// exchangeOptions passes the PKCE verifier to the token exchange when one was used
+func exchangeOptions(verifier string) []oauth2.AuthCodeOption {
+	if verifier == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.VerifierOption(verifier)}
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenServer records the form of the last token request and answers with a bearer token
+func tokenServer(t *testing.T, form *url.Values) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse token request: %v", err)
+		}
+		*form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testProviders(tokenURL string) []OAuthProvider {
+	config := func() *oauth2.Config {
+		return &oauth2.Config{
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  "http://localhost/callback",
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   "https://provider.example/authorize",
+				TokenURL:  tokenURL,
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+		}
+	}
+
+	return []OAuthProvider{
+		NewGoogleProvider(config(), true),
+		NewGitHubProvider(config(), true),
+		NewDiscordProvider(config(), true),
+	}
+}
+
+func TestPKCEChallengeAndVerifierRoundTrip(t *testing.T) {
+	var form url.Values
+	server := tokenServer(t, &form)
+
+	for _, provider := range testProviders(server.URL) {
+		t.Run(provider.Name(), func(t *testing.T) {
+			verifier := oauth2.GenerateVerifier()
+
+			authURL, err := url.Parse(provider.GetAuthURL("state", verifier))
+			if err != nil {
+				t.Fatal(err)
+			}
+			params := authURL.Query()
+
+			sum := sha256.Sum256([]byte(verifier))
+			wantChallenge := base64.RawURLEncoding.EncodeToString(sum[:])
+			if got := params.Get("code_challenge"); got != wantChallenge {
+				t.Fatalf("code_challenge = %q, want S256 of the verifier %q", got, wantChallenge)
+			}
+			if got := params.Get("code_challenge_method"); got != "S256" {
+				t.Fatalf("code_challenge_method = %q, want S256", got)
+			}
+			if params.Get("state") != "state" {
+				t.Fatalf("state = %q, want it passed through", params.Get("state"))
+			}
+
+			if _, err := provider.ExchangeCode(context.Background(), "code", verifier); err != nil {
+				t.Fatal(err)
+			}
+			if got := form.Get("code_verifier"); got != verifier {
+				t.Fatalf("code_verifier sent = %q, want %q", got, verifier)
+			}
+		})
+	}
+}
+
+func TestNoPKCEWithoutVerifier(t *testing.T) {
+	var form url.Values
+	server := tokenServer(t, &form)
+
+	for _, provider := range testProviders(server.URL) {
+		t.Run(provider.Name(), func(t *testing.T) {
+			authURL, err := url.Parse(provider.GetAuthURL("state", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if authURL.Query().Has("code_challenge") {
+				t.Fatal("auth URL carries a code_challenge without a verifier")
+			}
+
+			if _, err := provider.ExchangeCode(context.Background(), "code", ""); err != nil {
+				t.Fatal(err)
+			}
+			if form.Has("code_verifier") {
+				t.Fatal("token request carries a code_verifier without PKCE")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TokenPruner deletes expired refresh tokens and revocation entries every interval, so the
+// tables do not keep a row for every session ever started. A zero interval disables it.
+type TokenPruner struct {
+	service  *Service
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewTokenPruner(service *Service, interval time.Duration) *TokenPruner {
+	return &TokenPruner{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start runs the pruner in a background goroutine until Close is called
+func (p *TokenPruner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		p.run(ctx)
+	}()
+}
+
+// Close stops the pruner at shutdown, giving up on waiting for the prune in progress once
+// ctx is done
+func (p *TokenPruner) Close(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *TokenPruner) run(ctx context.Context) {
+	logger := slog.With("component", "token_pruner")
+
+	if p.interval <= 0 {
+		logger.Info("Token pruning disabled")
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	logger.Debug("Starting token pruner", "interval", p.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("Token pruner stopped")
+			return
+		case <-ticker.C:
+			p.prune(ctx, logger)
+		}
+	}
+}
+
+func (p *TokenPruner) prune(ctx context.Context, logger *slog.Logger) {
+	refresh, revoked, err := p.service.PruneExpiredTokens(ctx)
+	if err != nil {
+		logger.Error("Failed to prune expired tokens", "error", err)
+		return
+	}
+
+	if refresh > 0 || revoked > 0 {
+		logger.Info("Pruned expired tokens", "refresh_tokens", refresh, "revoked_tokens", revoked)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"time"
 )
 
 type Repository struct {
@@ -23,7 +24,7 @@ func (r *Repository) CreateAuthProvider(ctx context.Context, playerID int, provi
 
 	_, err := r.db.ExecContext(ctx, query, playerID, provider, providerUserID, providerEmail)
 	if err != nil {
-		return errors.WrapInternal("failed to create auth provider", err)
+		return database.ClassifyError("failed to create auth provider", err)
 	}
 
 	return nil
@@ -47,3 +48,120 @@ func (r *Repository) FindPlayerByAuthProvider(ctx context.Context, provider, pro
 
 	return playerID, nil
 }
+
+func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
+	if tx != nil {
+		return tx
+	}
+	return r.db
+}
+
+// tokenOwner is the player a refresh token was issued to, as currently stored
+type tokenOwner struct {
+	PlayerID int
+	Username string
+	Email    string
+	Role     string
+}
+
+// CreateRefreshToken stores a newly issued refresh token, valid for ttl from now
+func (r *Repository) CreateRefreshToken(ctx context.Context, tokenID string, playerID int, ttl time.Duration, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		INSERT INTO refresh_tokens (id, player_id, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+	`
+
+	_, err := exec.ExecContext(ctx, query, tokenID, playerID, ttl.Seconds())
+	if err != nil {
+		return database.ClassifyError("failed to store refresh token", err)
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken revokes a live refresh token and returns its player, so each token can
+// be exchanged only once. A revoked, expired or unknown token yields Unauthorized.
+func (r *Repository) ConsumeRefreshToken(ctx context.Context, tokenID string, tx *database.Tx) (*tokenOwner, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE refresh_tokens rt
+		SET revoked_at = NOW()
+		FROM players p
+		WHERE rt.id = $1
+			AND p.id = rt.player_id
+			AND rt.revoked_at IS NULL
+			AND rt.expires_at > NOW()
+		RETURNING p.id, p.username, p.email, p.role
+	`
+
+	var owner tokenOwner
+	err := exec.QueryRowContext(ctx, query, tokenID).Scan(&owner.PlayerID, &owner.Username, &owner.Email, &owner.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Unauthorized("refresh token has been revoked or has expired")
+		}
+		return nil, errors.WrapInternal("failed to consume refresh token", err)
+	}
+
+	return &owner, nil
+}
+
+// RevokeRefreshToken marks the token revoked; revoking an unknown or already revoked token is a no-op
+func (r *Repository) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenID); err != nil {
+		return errors.WrapInternal("failed to revoke refresh token", err)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken blacklists an access token ID for ttl, the token's remaining lifetime
+func (r *Repository) RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, NOW() + make_interval(secs => $2))
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, jti, ttl.Seconds()); err != nil {
+		return database.ClassifyError("failed to revoke access token", err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether the access token ID has been blacklisted and not yet expired
+func (r *Repository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > NOW())`
+
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, database.ClassifyError("failed to check access token revocation", err)
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpiredTokens removes refresh tokens and access token revocations past their expiry,
+// which can no longer be used either way, and returns how many of each it removed
+func (r *Repository) DeleteExpiredTokens(ctx context.Context) (refresh, revoked int64, err error) {
+	query := `
+		WITH refresh AS (
+			DELETE FROM refresh_tokens WHERE expires_at <= NOW() RETURNING 1
+		), revoked AS (
+			DELETE FROM revoked_tokens WHERE expires_at <= NOW() RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM refresh), (SELECT COUNT(*) FROM revoked)
+	`
+
+	if err := r.db.QueryRowContext(ctx, query).Scan(&refresh, &revoked); err != nil {
+		return 0, 0, database.ClassifyError("failed to delete expired tokens", err)
+	}
+
+	return refresh, revoked, nil
+}
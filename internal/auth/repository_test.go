@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"planets-server/internal/shared/database/dbtest"
+)
+
+func TestDeleteExpiredTokensKeepsLiveOnes(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	playerID := dbtest.CreatePlayer(t, db)
+
+	for id, ttl := range map[string]time.Duration{"expired": -time.Minute, "live": time.Hour} {
+		if err := repo.CreateRefreshToken(ctx, id, playerID, ttl, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RevokeAccessToken(ctx, id, ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refresh, revoked, err := repo.DeleteExpiredTokens(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refresh != 1 || revoked != 1 {
+		t.Fatalf("deleted %d refresh tokens and %d revocations, want 1 of each", refresh, revoked)
+	}
+
+	var ids []string
+	rows, err := db.QueryContext(ctx, `
+		SELECT id FROM refresh_tokens
+		UNION ALL
+		SELECT jti FROM revoked_tokens
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "live" || ids[1] != "live" {
+		t.Fatalf("tokens left %v, want the live refresh token and revocation", ids)
+	}
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+const maxRevocationCacheEntries = 10000
+
+// revocations caches the answers of the revocation checker for ValidateJWT
+var revocations = newRevocationCache(0, maxRevocationCacheEntries)
+
+// revocationCache remembers revocation lookups so validating the same token again does not
+// query the database each time. A revoked token stays revoked, so that answer is kept until
+// the token expires. A "not revoked" answer is only reused for ttl, which bounds how long a
+// token revoked on another server instance may still be accepted here; zero disables that.
+type revocationCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]revocationEntry
+}
+
+type revocationEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+func newRevocationCache(ttl time.Duration, maxEntries int) *revocationCache {
+	return &revocationCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]revocationEntry),
+	}
+}
+
+func (c *revocationCache) get(jti string, now time.Time) (revoked, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[jti]
+	if !ok {
+		return false, false
+	}
+	if !now.Before(entry.until) {
+		delete(c.entries, jti)
+		return false, false
+	}
+	return entry.revoked, true
+}
+
+// set records a lookup for the token expiring at expiresAt
+func (c *revocationCache) set(jti string, revoked bool, expiresAt, now time.Time) {
+	until := expiresAt
+	if !revoked {
+		if c.ttl <= 0 {
+			return
+		}
+		if ttlEnd := now.Add(c.ttl); ttlEnd.Before(until) {
+			until = ttlEnd
+		}
+	}
+	if !now.Before(until) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[jti]; !exists && len(c.entries) >= c.maxEntries {
+		for key, entry := range c.entries {
+			if !now.Before(entry.until) {
+				delete(c.entries, key)
+			}
+		}
+		// Still full of live entries: look the next tokens up again rather than grow
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+
+	c.entries[jti] = revocationEntry{revoked: revoked, until: until}
+}
+
+func (c *revocationCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -2,6 +2,11 @@ package auth
 
 import (
 	"context"
+	"time"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
 )
 
 type Service struct {
@@ -21,3 +26,100 @@ func (s *Service) CreateAuthProvider(ctx context.Context, playerID int, provider
 func (s *Service) FindPlayerByAuthProvider(ctx context.Context, provider, providerUserID string) (int, error) {
 	return s.repo.FindPlayerByAuthProvider(ctx, provider, providerUserID)
 }
+
+// IssueTokens starts a session: it generates an access and refresh token pair and stores the refresh token
+func (s *Service) IssueTokens(ctx context.Context, playerID int, username, email, role string) (access, refresh string, err error) {
+	return s.issueTokens(ctx, playerID, username, email, role, nil)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token is revoked in
+// the same transaction, so replaying it afterwards fails.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (access, refresh string, err error) {
+	claims, err := ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", errors.Unauthorized("invalid refresh token")
+	}
+
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return "", "", database.ClassifyError("failed to begin transaction for token refresh", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	owner, err := s.repo.ConsumeRefreshToken(ctx, claims.ID, tx)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Issue from the stored player so role changes take effect on refresh
+	access, refresh, err = s.issueTokens(ctx, owner.PlayerID, owner.Username, owner.Email, owner.Role, tx)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", "", errors.WrapInternal("failed to commit token refresh", err)
+	}
+
+	return access, refresh, nil
+}
+
+// RevokeRefreshToken ends the session the refresh token belongs to
+func (s *Service) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	return s.repo.RevokeRefreshToken(ctx, tokenID)
+}
+
+// RevokeToken blacklists an access token until it expires, so it is rejected even while its
+// signature is still valid. A token that has already expired needs no entry.
+func (s *Service) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.Validation("token has no ID to revoke")
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.repo.RevokeAccessToken(ctx, jti, ttl); err != nil {
+		return err
+	}
+
+	// Take effect on this instance at once rather than when a cached answer runs out
+	revocations.set(jti, true, expiresAt, time.Now())
+	return nil
+}
+
+// PruneExpiredTokens deletes refresh tokens and revocation entries past their expiry
+func (s *Service) PruneExpiredTokens(ctx context.Context) (refresh, revoked int64, err error) {
+	return s.repo.DeleteExpiredTokens(ctx)
+}
+
+// IsTokenRevoked reports whether the access token ID has been revoked by RevokeToken
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.repo.IsAccessTokenRevoked(ctx, jti)
+}
+
+func (s *Service) issueTokens(ctx context.Context, playerID int, username, email, role string, tx *database.Tx) (string, string, error) {
+	access, refresh, err := GenerateTokenPair(playerID, username, email, role)
+	if err != nil {
+		return "", "", errors.WrapInternal("failed to generate tokens", err)
+	}
+
+	claims, err := ValidateRefreshToken(refresh)
+	if err != nil {
+		return "", "", errors.WrapInternal("failed to read generated refresh token", err)
+	}
+
+	err = s.repo.CreateRefreshToken(ctx, claims.ID, playerID, config.GlobalConfig.Auth.RefreshTokenExpiration, tx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return access, refresh, nil
+}
@@ -18,6 +18,18 @@ type StateManager struct {
 	memoryStore map[string]StateEntry
 	mutex       sync.RWMutex
 	useRedis    bool
+	config      StateManagerConfig
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// StateManagerConfig tunes OAuth state validation
+type StateManagerConfig struct {
+	// StrictUserAgent rejects callbacks whose User-Agent differs from the one that started
+	// the login. Binding the state to the browser makes a leaked state token harder to replay,
+	// but some mobile browsers and in-app webviews change their User-Agent between the
+	// redirect and the callback, so strict mode locks those users out. Off by default.
+	StrictUserAgent bool
 }
 
 type StateEntry struct {
@@ -25,17 +37,21 @@ type StateEntry struct {
 	Provider    string    `json:"provider"`
 	UserAgent   string    `json:"user_agent"`
 	RedirectURI string    `json:"redirect_uri"`
+	// CodeVerifier is the PKCE verifier, empty when the provider doesn't use PKCE
+	CodeVerifier string `json:"code_verifier,omitempty"`
 }
 
 var globalStateManager *StateManager
 
-func InitStateManager(redisClient *redis.Client) {
+func InitStateManager(redisClient *redis.Client, config StateManagerConfig) {
 	useRedis := redisClient != nil
 
 	globalStateManager = &StateManager{
 		redis:       redisClient,
 		memoryStore: make(map[string]StateEntry),
 		useRedis:    useRedis,
+		config:      config,
+		stop:        make(chan struct{}),
 	}
 
 	logger := slog.With("component", "state_manager", "operation", "init")
@@ -47,7 +63,7 @@ func InitStateManager(redisClient *redis.Client) {
 	}
 }
 
-func (sm *StateManager) GenerateState(provider, userAgent, redirectURI string) (string, error) {
+func (sm *StateManager) GenerateState(provider, userAgent, redirectURI, codeVerifier string) (string, error) {
 	logger := slog.With("component", "state_manager", "operation", "generate", "provider", provider)
 
 	b := make([]byte, 32)
@@ -58,10 +74,11 @@ func (sm *StateManager) GenerateState(provider, userAgent, redirectURI string) (
 
 	state := base64.URLEncoding.EncodeToString(b)
 	entry := StateEntry{
-		CreatedAt:   time.Now(),
-		Provider:    provider,
-		UserAgent:   userAgent,
-		RedirectURI: redirectURI,
+		CreatedAt:    time.Now(),
+		Provider:     provider,
+		UserAgent:    userAgent,
+		RedirectURI:  redirectURI,
+		CodeVerifier: codeVerifier,
 	}
 
 	if sm.useRedis {
@@ -180,9 +197,11 @@ func (sm *StateManager) validateEntry(entry StateEntry, provider, userAgent stri
 	if entry.UserAgent != userAgent {
 		logger.Warn("State token user agent mismatch - possible session hijacking attempt",
 			"stored_user_agent", entry.UserAgent,
-			"received_user_agent", userAgent)
-		// Uncomment next line for strict user agent validation (optional)
-		// return fmt.Errorf("state token user agent mismatch")
+			"received_user_agent", userAgent,
+			"strict", sm.config.StrictUserAgent)
+		if sm.config.StrictUserAgent {
+			return fmt.Errorf("state token user agent mismatch")
+		}
 	}
 
 	logger.Debug("State token validated successfully",
@@ -198,9 +217,23 @@ func (sm *StateManager) startMemoryCleanup() {
 	logger := slog.With("component", "state_manager", "operation", "cleanup")
 	logger.Debug("Starting memory cleanup goroutine")
 
-	for range ticker.C {
-		sm.cleanupExpiredStates()
+	for {
+		select {
+		case <-sm.stop:
+			logger.Debug("Stopping memory cleanup goroutine")
+			return
+		case <-ticker.C:
+			sm.cleanupExpiredStates()
+		}
+	}
+}
+
+// CloseStateManager stops the in-memory store's cleanup goroutine, if it is running
+func CloseStateManager(ctx context.Context) error {
+	if globalStateManager != nil {
+		globalStateManager.stopOnce.Do(func() { close(globalStateManager.stop) })
 	}
+	return nil
 }
 
 func (sm *StateManager) cleanupExpiredStates() {
@@ -226,11 +259,11 @@ func (sm *StateManager) cleanupExpiredStates() {
 	}
 }
 
-func GenerateOAuthState(provider, userAgent, redirectURI string) (string, error) {
+func GenerateOAuthState(provider, userAgent, redirectURI, codeVerifier string) (string, error) {
 	if globalStateManager == nil {
 		return "", fmt.Errorf("state manager not initialized")
 	}
-	return globalStateManager.GenerateState(provider, userAgent, redirectURI)
+	return globalStateManager.GenerateState(provider, userAgent, redirectURI, codeVerifier)
 }
 
 func ValidateOAuthState(state, provider, userAgent string) (StateEntry, error) {
@@ -0,0 +1,60 @@
+package auth
+
+import "testing"
+
+func newMemoryStateManager(config StateManagerConfig) *StateManager {
+	return &StateManager{
+		memoryStore: make(map[string]StateEntry),
+		config:      config,
+		stop:        make(chan struct{}),
+	}
+}
+
+func TestStateKeepsPKCEVerifier(t *testing.T) {
+	sm := newMemoryStateManager(StateManagerConfig{})
+
+	state, err := sm.GenerateState("github", "agent", "http://localhost/after", "the-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := sm.ValidateState(state, "github", "agent")
+	if err != nil {
+		t.Fatalf("ValidateState() error = %v", err)
+	}
+	if entry.CodeVerifier != "the-verifier" {
+		t.Fatalf("CodeVerifier = %q, want the one stored with the state", entry.CodeVerifier)
+	}
+	if entry.RedirectURI != "http://localhost/after" {
+		t.Fatalf("RedirectURI = %q, want the one stored with the state", entry.RedirectURI)
+	}
+}
+
+func TestStateIsSingleUse(t *testing.T) {
+	sm := newMemoryStateManager(StateManagerConfig{})
+
+	state, err := sm.GenerateState("google", "agent", "", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.ValidateState(state, "google", "agent"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.ValidateState(state, "google", "agent"); err == nil {
+		t.Fatal("a state token was accepted twice, replaying its verifier")
+	}
+}
+
+func TestStateRejectsOtherProvider(t *testing.T) {
+	sm := newMemoryStateManager(StateManagerConfig{})
+
+	state, err := sm.GenerateState("google", "agent", "", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.ValidateState(state, "github", "agent"); err == nil {
+		t.Fatal("a google state token was accepted by the github callback")
+	}
+}
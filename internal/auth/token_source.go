@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/cookies"
+)
+
+const bearerPrefix = "Bearer "
+
+// TokenFromRequest returns the access token the request carries, looking only where
+// AUTH_TOKEN_SOURCE allows. When both are accepted the Authorization header wins, since a
+// client that bothers to send one means it over whatever cookie the browser attached.
+func TokenFromRequest(r *http.Request) (string, bool) {
+	source := config.GlobalConfig.Auth.TokenSource
+
+	if source == config.TokenSourceHeader || source == config.TokenSourceBoth {
+		if header := r.Header.Get("Authorization"); len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(header[len(bearerPrefix):]), true
+		}
+	}
+
+	if source == config.TokenSourceCookie || source == config.TokenSourceBoth {
+		if cookie, err := r.Cookie(cookies.AuthCookieName()); err == nil && cookie.Value != "" {
+			return cookie.Value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"planets-server/internal/shared/config"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		header    string
+		cookie    string
+		wantToken string
+		wantOK    bool
+	}{
+		{"cookie mode reads the cookie", config.TokenSourceCookie, "", "from-cookie", "from-cookie", true},
+		{"cookie mode ignores the header", config.TokenSourceCookie, "Bearer from-header", "", "", false},
+		{"header mode reads the header", config.TokenSourceHeader, "Bearer from-header", "", "from-header", true},
+		{"header mode ignores the cookie", config.TokenSourceHeader, "", "from-cookie", "", false},
+		{"header scheme is case insensitive", config.TokenSourceHeader, "bearer from-header", "", "from-header", true},
+		{"other header schemes are ignored", config.TokenSourceHeader, "Basic dXNlcjpwYXNz", "", "", false},
+		{"bare scheme carries no token", config.TokenSourceHeader, "Bearer ", "", "", false},
+		{"both modes prefer the header", config.TokenSourceBoth, "Bearer from-header", "from-cookie", "from-header", true},
+		{"both modes fall back to the cookie", config.TokenSourceBoth, "", "from-cookie", "from-cookie", true},
+		{"empty cookie carries no token", config.TokenSourceBoth, "", "", "", false},
+	}
+
+	previous := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = previous })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GlobalConfig = &config.Config{Auth: config.AuthConfig{CookieName: "auth_token", TokenSource: tt.source}}
+
+			r := httptest.NewRequest("GET", "/api/players/me", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			r.AddCookie(&http.Cookie{Name: "auth_token", Value: tt.cookie})
+
+			token, ok := TokenFromRequest(r)
+			if token != tt.wantToken || ok != tt.wantOK {
+				t.Fatalf("TokenFromRequest() = %q, %v; want %q, %v", token, ok, tt.wantToken, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package building
+
+// Definition describes what a building costs and does at each level. Costs are paid in
+// population, the same currency fortification uses.
+type Definition struct {
+	MaxLevel int
+	// BaseCost is the cost of level 1; each further level costs CostGrowthPercent of the previous one
+	BaseCost          int64
+	CostGrowthPercent int64
+	// GrowthPercentPerLevel is the share of max_population the planet gains each turn, per level
+	GrowthPercentPerLevel int
+	// DefensePerLevel is the defense added when each level is completed, up to planet.MaxDefense
+	DefensePerLevel int
+}
+
+// Definitions is the catalogue of buildings. Tune buildings here; the turn economy and the
+// construction checks read everything from this table.
+var Definitions = map[Type]Definition{
+	TypeHabitat: {
+		MaxLevel:              5,
+		BaseCost:              20000,
+		CostGrowthPercent:     200,
+		GrowthPercentPerLevel: 2,
+	},
+	TypeShieldGenerator: {
+		MaxLevel:          5,
+		BaseCost:          30000,
+		CostGrowthPercent: 180,
+		DefensePerLevel:   25,
+	},
+}
+
+// CostFor returns the population needed to reach level
+func (d Definition) CostFor(level int) int64 {
+	cost := d.BaseCost
+	for i := 1; i < level; i++ {
+		cost = cost * d.CostGrowthPercent / 100
+	}
+	return cost
+}
+
+// growthRates lists the per-level growth of every building that boosts production
+func growthRates() (types []string, percents []int) {
+	for buildingType, def := range Definitions {
+		if def.GrowthPercentPerLevel > 0 {
+			types = append(types, string(buildingType))
+			percents = append(percents, def.GrowthPercentPerLevel)
+		}
+	}
+	return types, percents
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"planets-server/internal/building"
+	"planets-server/internal/middleware"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type BuildingHandler struct {
+	service *building.Service
+}
+
+func NewBuildingHandler(service *building.Service) *BuildingHandler {
+	return &BuildingHandler{service: service}
+}
+
+// ServeHTTP lists the planet's buildings on GET and constructs or upgrades one on POST
+func (h *BuildingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "planet_buildings", "method", r.Method)
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r, logger, planetID)
+	case http.MethodPost:
+		h.construct(w, r, logger, planetID)
+	default:
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+	}
+}
+
+func (h *BuildingHandler) list(w http.ResponseWriter, r *http.Request, logger *slog.Logger, planetID int) {
+	buildings, err := h.service.GetByPlanet(r.Context(), planetID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, buildings)
+}
+
+func (h *BuildingHandler) construct(w http.ResponseWriter, r *http.Request, logger *slog.Logger, planetID int) {
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	var req building.ConstructRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10) // 1 KB
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	built, err := h.service.Construct(r.Context(), planetID, claims.PlayerID, req.Type)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	logger.Info("Building constructed",
+		"planet_id", planetID,
+		"player_id", claims.PlayerID,
+		"building_type", built.Type,
+		"level", built.Level)
+
+	response.Success(w, http.StatusOK, built)
+}
@@ -0,0 +1,24 @@
+package building
+
+import "time"
+
+type Type string
+
+const (
+	TypeHabitat         Type = "habitat"
+	TypeShieldGenerator Type = "shield_generator"
+)
+
+type Building struct {
+	PlanetID  int       `json:"planet_id"`
+	Type      Type      `json:"type"`
+	Level     int       `json:"level"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// NextLevelCost is the population the next upgrade costs, absent at the maximum level
+	NextLevelCost *int64 `json:"next_level_cost,omitempty"`
+}
+
+// ConstructRequest asks for a building to be built, or upgraded by one level if it already exists
+type ConstructRequest struct {
+	Type Type `json:"type"`
+}
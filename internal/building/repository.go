@@ -0,0 +1,168 @@
+package building
+
+import (
+	"context"
+	"database/sql"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
+	if tx != nil {
+		return tx
+	}
+	return r.db
+}
+
+func (r *Repository) GetByPlanet(ctx context.Context, planetID int) ([]Building, error) {
+	query := `
+		SELECT planet_id, building_type, level, updated_at
+		FROM planet_buildings
+		WHERE planet_id = $1
+		ORDER BY building_type`
+
+	rows, err := r.db.QueryContext(ctx, query, planetID)
+	if err != nil {
+		return nil, database.ClassifyError("failed to get planet buildings", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	buildings := []Building{}
+	for rows.Next() {
+		var b Building
+		if err := rows.Scan(&b.PlanetID, &b.Type, &b.Level, &b.UpdatedAt); err != nil {
+			return nil, errors.WrapInternal("failed to scan planet building", err)
+		}
+		buildings = append(buildings, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating planet buildings", err)
+	}
+
+	return buildings, nil
+}
+
+// planetState is the part of a planet construction reads while its row is locked
+type planetState struct {
+	OwnerID    *int
+	Population int64
+	Defense    int
+}
+
+// LockPlanet locks the planet row for the rest of the transaction, so the population
+// spent on a building can't be spent twice
+func (r *Repository) LockPlanet(ctx context.Context, planetID int, tx *database.Tx) (*planetState, error) {
+	exec := r.getExecutor(tx)
+
+	query := `SELECT owner_id, population, defense FROM planets WHERE id = $1 FOR UPDATE`
+
+	var state planetState
+	err := exec.QueryRowContext(ctx, query, planetID).Scan(&state.OwnerID, &state.Population, &state.Defense)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, database.ClassifyError("failed to lock planet for construction", err)
+	}
+
+	return &state, nil
+}
+
+// GetLevel returns the building's current level on the planet, 0 when it isn't built
+func (r *Repository) GetLevel(ctx context.Context, planetID int, buildingType Type, tx *database.Tx) (int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `SELECT level FROM planet_buildings WHERE planet_id = $1 AND building_type = $2`
+
+	var level int
+	err := exec.QueryRowContext(ctx, query, planetID, buildingType).Scan(&level)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, database.ClassifyError("failed to get building level", err)
+	}
+
+	return level, nil
+}
+
+// SpendPopulation pays cost from the planet's population and adds defense, capped at maxDefense
+func (r *Repository) SpendPopulation(ctx context.Context, planetID int, cost int64, defense, maxDefense int, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets
+		SET population = population - $2, defense = LEAST(defense + $3, GREATEST(defense, $4))
+		WHERE id = $1`
+
+	if _, err := exec.ExecContext(ctx, query, planetID, cost, defense, maxDefense); err != nil {
+		return database.ClassifyError("failed to pay for building", err)
+	}
+
+	return nil
+}
+
+// Upgrade builds the building at level 1 or raises its level by one, returning the result
+func (r *Repository) Upgrade(ctx context.Context, planetID int, buildingType Type, tx *database.Tx) (*Building, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		INSERT INTO planet_buildings (planet_id, building_type, level)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (planet_id, building_type) DO UPDATE SET level = planet_buildings.level + 1
+		RETURNING planet_id, building_type, level, updated_at`
+
+	var b Building
+	err := exec.QueryRowContext(ctx, query, planetID, buildingType).Scan(&b.PlanetID, &b.Type, &b.Level, &b.UpdatedAt)
+	if err != nil {
+		return nil, database.ClassifyError("failed to upgrade building", err)
+	}
+
+	return &b, nil
+}
+
+// ApplyGrowth grows the population of the game's owned planets by the summed growth of their
+// buildings, as a percentage of max_population, never past max_population. It returns the
+// planets affected.
+func (r *Repository) ApplyGrowth(ctx context.Context, gameID int, types []string, percents []int, tx *database.Tx) (int64, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets p
+		SET population = LEAST(p.max_population, p.population + p.max_population * g.percent / 100)
+		FROM (
+			SELECT b.planet_id, SUM(b.level * rate.percent) AS percent
+			FROM planet_buildings b
+			JOIN unnest($2::text[], $3::int[]) AS rate(building_type, percent) ON rate.building_type = b.building_type
+			GROUP BY b.planet_id
+		) g, spatial_entities s
+		WHERE p.id = g.planet_id
+			AND s.id = p.system_id
+			AND s.game_id = $1
+			AND p.owner_id IS NOT NULL
+			AND p.population < p.max_population`
+
+	result, err := exec.ExecContext(ctx, query, gameID, pq.Array(types), pq.Array(percents))
+	if err != nil {
+		return 0, database.ClassifyError("failed to apply building growth", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapInternal("failed to count grown planets", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,112 @@
+package building
+
+import (
+	"context"
+
+	"planets-server/internal/planet"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetByPlanet lists the planet's buildings with the cost of their next level
+func (s *Service) GetByPlanet(ctx context.Context, planetID int) ([]Building, error) {
+	buildings, err := s.repo.GetByPlanet(ctx, planetID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range buildings {
+		withNextCost(&buildings[i])
+	}
+
+	return buildings, nil
+}
+
+// Construct builds the building on the player's planet, or upgrades it by one level, paying
+// for it with the planet's population. Defense buildings take effect immediately; production
+// buildings act on every turn.
+func (s *Service) Construct(ctx context.Context, planetID, playerID int, buildingType Type) (building *Building, err error) {
+	def, ok := Definitions[buildingType]
+	if !ok {
+		return nil, errors.Validationf("unknown building type: %s", buildingType)
+	}
+
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for construction", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	state, err := s.repo.LockPlanet(ctx, planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.OwnerID == nil || *state.OwnerID != playerID {
+		return nil, errors.WithCode(errors.Forbidden("only the planet owner can construct buildings"), errors.CodeNotPlanetOwner)
+	}
+
+	level, err := s.repo.GetLevel(ctx, planetID, buildingType, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if level >= def.MaxLevel {
+		return nil, errors.WithCode(errors.Validationf("%s is already at its maximum level %d", buildingType, def.MaxLevel), errors.CodeMaxBuildingLevel)
+	}
+
+	cost := def.CostFor(level + 1)
+	if state.Population < cost {
+		return nil, errors.WithCode(errors.Validationf("insufficient population: level %d %s costs %d", level+1, buildingType, cost), errors.CodeInsufficientPopulation)
+	}
+
+	if err = s.repo.SpendPopulation(ctx, planetID, cost, def.DefensePerLevel, planet.MaxDefense, tx); err != nil {
+		return nil, err
+	}
+
+	building, err = s.repo.Upgrade(ctx, planetID, buildingType, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit construction", err)
+	}
+
+	withNextCost(building)
+	return building, nil
+}
+
+// ApplyTurnEffects applies the per-turn production of every building in the game and
+// returns the number of planets that grew. Meant to run once per turn inside the turn's transaction.
+func (s *Service) ApplyTurnEffects(ctx context.Context, gameID int, tx *database.Tx) (int64, error) {
+	types, percents := growthRates()
+	if len(types) == 0 {
+		return 0, nil
+	}
+
+	return s.repo.ApplyGrowth(ctx, gameID, types, percents, tx)
+}
+
+func withNextCost(b *Building) {
+	def, ok := Definitions[b.Type]
+	if !ok || b.Level >= def.MaxLevel {
+		return
+	}
+
+	cost := def.CostFor(b.Level + 1)
+	b.NextLevelCost = &cost
+}
@@ -0,0 +1,17 @@
+package combat
+
+type Result struct {
+	AttackerWins    bool `json:"attacker_wins"`
+	AttackStrength  int  `json:"attack_strength"`
+	DefenseStrength int  `json:"defense_strength"`
+}
+
+// Resolve decides an attack against a planet with the given effective defense. The attacker
+// has to exceed it, so ties go to the defender.
+func Resolve(attackStrength, defenseStrength int) Result {
+	return Result{
+		AttackerWins:    attackStrength > defenseStrength,
+		AttackStrength:  attackStrength,
+		DefenseStrength: defenseStrength,
+	}
+}
@@ -0,0 +1,24 @@
+package combat
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		attack, defense int
+		attackerWins    bool
+	}{
+		{"stronger attack wins", 51, 50, true},
+		{"tie goes to the defender", 50, 50, false},
+		{"weaker attack is repelled", 10, 50, false},
+		{"undefended planet falls", 1, 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(tt.attack, tt.defense)
+			want := Result{AttackerWins: tt.attackerWins, AttackStrength: tt.attack, DefenseStrength: tt.defense}
+			if got != want {
+				t.Fatalf("Resolve(%d, %d) = %+v, want %+v", tt.attack, tt.defense, got, want)
+			}
+		})
+	}
+}
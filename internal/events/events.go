@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event types published to external consumers
+const (
+	TypePlanetColonized   = "planet.colonized"
+	TypePlanetTransferred = "planet.transferred"
+	TypePlanetConquered   = "planet.conquered"
+	TypePlanetAbandoned   = "planet.abandoned"
+)
+
+// Event is a domain event; Data is serialized as JSON by the publisher
+type Event struct {
+	Type       string    `json:"type"`
+	GameID     int       `json:"game_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+func New(eventType string, gameID int, data any) Event {
+	return Event{
+		Type:       eventType,
+		GameID:     gameID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher delivers domain events to an external transport. Delivery is best-effort:
+// implementations log their own failures, and callers publish only after committing.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NoopPublisher discards every event; it is used when no transport is configured
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(context.Context, Event) error {
+	return nil
+}
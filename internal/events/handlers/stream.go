@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"planets-server/internal/events"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+// keepaliveInterval spaces the comments sent on an idle stream so proxies keep it open
+const keepaliveInterval = 25 * time.Second
+
+// retryAfter is how long a client refused for too many subscribers is asked to wait
+const retryAfter = 5 * time.Second
+
+type StreamHandler struct {
+	hub *events.Hub
+}
+
+func NewStreamHandler(hub *events.Hub) *StreamHandler {
+	return &StreamHandler{hub: hub}
+}
+
+// ServeHTTP streams the game's events to the client as server-sent events until the client
+// goes away, or the hub drops it for falling behind or because the server is shutting down
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "game_events")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	sub, err := h.hub.Subscribe(gameID)
+	if err != nil {
+		// Streams are long-lived, so a client refused now gets a slot once another one leaves
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		response.Error(w, r, logger, err)
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout by design
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Debug("Could not lift the write deadline for the event stream", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logger.Error("Event stream cannot be flushed", "error", err)
+		return
+	}
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				logger.Error("Failed to encode event", "error", err, "event_type", event.Type)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"planets-server/internal/events"
+)
+
+func TestStreamDeliversTheGamesEvents(t *testing.T) {
+	hub := events.NewHub(events.HubConfig{BufferSize: 8})
+	mux := http.NewServeMux()
+	mux.Handle("/api/games/{id}/events", NewStreamHandler(hub))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/games/3/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// The subscription exists once the headers are flushed
+	_ = hub.Publish(context.Background(), events.New("planet.colonized", 4, "other game"))
+	_ = hub.Publish(context.Background(), events.New("planet.colonized", 3, "this game"))
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	next := func() (string, bool) {
+		select {
+		case line, ok := <-lines:
+			return line, ok
+		case <-time.After(time.Second):
+			t.Fatal("stream stalled")
+			return "", false
+		}
+	}
+
+	if line, _ := next(); line != "event: planet.colonized" {
+		t.Fatalf("got %q, want the event type", line)
+	}
+	if line, _ := next(); !strings.HasPrefix(line, "data: ") || !strings.Contains(line, `"this game"`) || !strings.Contains(line, `"game_id":3`) {
+		t.Fatalf("got %q, want the event of game 3", line)
+	}
+	next()
+
+	// Closing the hub, as the server does on shutdown, ends the stream
+	hub.Close()
+	for {
+		if _, ok := next(); !ok {
+			break
+		}
+	}
+}
+
+func TestStreamRejectsBadRequests(t *testing.T) {
+	handler := NewStreamHandler(events.NewHub(events.HubConfig{BufferSize: 8}))
+
+	for _, tt := range []struct {
+		method, id string
+		want       int
+	}{
+		{http.MethodPost, "1", http.StatusMethodNotAllowed},
+		{http.MethodGet, "abc", http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest(tt.method, "/api/games/"+tt.id+"/events", nil)
+		r.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tt.want {
+			t.Errorf("%s with id %q: status %d, want %d", tt.method, tt.id, w.Code, tt.want)
+		}
+	}
+}
+
+func TestStreamRefusesSubscribersOverTheCap(t *testing.T) {
+	hub := events.NewHub(events.HubConfig{BufferSize: 8, MaxSubscribersPerGame: 1})
+	held, err := hub.Subscribe(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/games/1/events", nil)
+	r.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	NewStreamHandler(hub).ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("no Retry-After header")
+	}
+	if hub.Subscribers() != 1 {
+		t.Fatalf("hub has %d subscribers, want only the one holding the slot", hub.Subscribers())
+	}
+}
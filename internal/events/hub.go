@@ -0,0 +1,207 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/metrics"
+)
+
+// HubConfig sizes a Hub. A subscriber limit of 0 disables that check.
+type HubConfig struct {
+	// BufferSize is how many events a subscriber may fall behind by
+	BufferSize int
+	// DisconnectSlow closes a subscriber with a full buffer instead of dropping the event for it,
+	// so the client reconnects and resyncs rather than silently missing updates
+	DisconnectSlow bool
+	// MaxSubscribers caps open subscriptions across all games
+	MaxSubscribers int
+	// MaxSubscribersPerGame caps open subscriptions to a single game
+	MaxSubscribersPerGame int
+}
+
+// Hub fans events out to in-process subscribers, such as the live update streams of a game.
+// Publish never blocks: every subscriber has its own buffer, and one that falls behind loses
+// events or is disconnected, so a stalled client cannot hold up the publisher or the other
+// subscribers. The number of subscriptions is capped overall and per game, so clients holding
+// streams open cannot exhaust the server.
+type Hub struct {
+	config HubConfig
+
+	// total counts open subscriptions across all games and perGame those of each game. Both
+	// are reserved before a subscriber is added and given back when it is removed.
+	total   atomic.Int64
+	perGame sync.Map // game ID -> *atomic.Int64
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// Subscription receives the events of one game until it is closed
+type Subscription struct {
+	hub    *Hub
+	gameID int
+	events chan Event
+	closed bool
+}
+
+func NewHub(config HubConfig) *Hub {
+	return &Hub{
+		config:      config,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving the events of the game. Callers must Close the subscription.
+// Once the hub is closed, subscriptions start out closed. It fails with an external error
+// coded too_many_subscribers when the hub or the game already has as many subscribers as
+// allowed.
+func (h *Hub) Subscribe(gameID int) (*Subscription, error) {
+	if err := h.reserve(gameID); err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		hub:    h,
+		gameID: gameID,
+		events: make(chan Event, h.config.BufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		h.release(gameID)
+		sub.closed = true
+		close(sub.events)
+		return sub, nil
+	}
+	h.subscribers[sub] = struct{}{}
+	metrics.EventSubscribers.Inc()
+
+	return sub, nil
+}
+
+// reserve takes a slot for one more subscriber of the game, or fails without taking any
+func (h *Hub) reserve(gameID int) error {
+	total := h.total.Add(1)
+	if limit := h.config.MaxSubscribers; limit > 0 && total > int64(limit) {
+		h.total.Add(-1)
+		return errors.WithCode(errors.External(fmt.Sprintf("too many event subscribers (max: %d)", limit)), errors.CodeTooManySubscribers)
+	}
+
+	perGame := h.gameCount(gameID).Add(1)
+	if limit := h.config.MaxSubscribersPerGame; limit > 0 && perGame > int64(limit) {
+		h.release(gameID)
+		return errors.WithCode(errors.External(fmt.Sprintf("too many event subscribers for game %d (max: %d)", gameID, limit)), errors.CodeTooManySubscribers)
+	}
+
+	return nil
+}
+
+// release gives back the slots reserve took for a subscriber of the game
+func (h *Hub) release(gameID int) {
+	h.total.Add(-1)
+	h.gameCount(gameID).Add(-1)
+}
+
+// gameCount returns the game's subscriber counter. Counters are kept once created, which costs
+// one small entry per game that was ever streamed.
+func (h *Hub) gameCount(gameID int) *atomic.Int64 {
+	if count, ok := h.perGame.Load(gameID); ok {
+		return count.(*atomic.Int64)
+	}
+	count, _ := h.perGame.LoadOrStore(gameID, new(atomic.Int64))
+	return count.(*atomic.Int64)
+}
+
+// Close ends every subscription, letting long-lived streams finish so the server can drain
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subscribers {
+		h.remove(sub)
+	}
+}
+
+// Events delivers the subscribed game's events. It is closed when the subscription is
+// closed, including when the hub disconnects a subscriber that fell behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription; closing it again is a no-op
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+// remove drops the subscriber and closes its channel; h.mu must be held
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(h.subscribers, sub)
+	close(sub.events)
+	h.release(sub.gameID)
+	metrics.EventSubscribers.Dec()
+}
+
+// Subscribers returns the number of open subscriptions
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// Publish hands the event to every subscriber of its game without waiting on any of them
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if sub.gameID != event.GameID {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+			continue
+		default:
+		}
+
+		logger := slog.With("component", "events", "transport", "hub", "event_type", event.Type, "game_id", event.GameID)
+		if h.config.DisconnectSlow {
+			logger.Warn("Disconnecting slow event subscriber", "buffer_size", h.config.BufferSize)
+			h.remove(sub)
+		} else {
+			logger.Warn("Dropped event for slow subscriber", "buffer_size", h.config.BufferSize)
+		}
+	}
+
+	return nil
+}
+
+// Fanout publishes every event to each of its publishers in order, trying them all even
+// when one fails, and returns the first failure
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, event Event) error {
+	var firstErr error
+	for _, publisher := range f {
+		if err := publisher.Publish(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
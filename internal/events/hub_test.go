@@ -0,0 +1,206 @@
+package events
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"planets-server/internal/shared/errors"
+)
+
+// publish fails the test if the hub does not return promptly
+func publish(t *testing.T, hub *Hub, event Event) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		_ = hub.Publish(context.Background(), event)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish() blocked")
+	}
+}
+
+func subscribe(t *testing.T, hub *Hub, gameID int) *Subscription {
+	t.Helper()
+
+	sub, err := hub.Subscribe(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+	return Event{}
+}
+
+func TestStalledSubscriberDoesNotBlockOthers(t *testing.T) {
+	const buffer = 4
+	hub := NewHub(HubConfig{BufferSize: buffer})
+	stalled := subscribe(t, hub, 1)
+	active := subscribe(t, hub, 1)
+	defer stalled.Close()
+	defer active.Close()
+
+	// Well past the stalled subscriber's buffer, the publisher and the active subscriber carry on
+	for i := 0; i < 50; i++ {
+		publish(t, hub, New("turn", 1, i))
+		if got := receive(t, active); got.Data != i {
+			t.Fatalf("active subscriber got event %v, want %d", got.Data, i)
+		}
+	}
+
+	// The stalled subscriber kept what fit in its buffer and missed the rest
+	if len(stalled.Events()) != buffer {
+		t.Fatalf("stalled subscriber holds %d events, want %d", len(stalled.Events()), buffer)
+	}
+	for i := 0; i < buffer; i++ {
+		if got := receive(t, stalled); got.Data != i {
+			t.Fatalf("stalled subscriber got event %v, want %d", got.Data, i)
+		}
+	}
+	if hub.Subscribers() != 2 {
+		t.Fatalf("hub has %d subscribers, want the slow one kept under the drop policy", hub.Subscribers())
+	}
+}
+
+func TestStalledSubscriberIsDisconnected(t *testing.T) {
+	const buffer = 2
+	hub := NewHub(HubConfig{BufferSize: buffer, DisconnectSlow: true})
+	stalled := subscribe(t, hub, 1)
+	active := subscribe(t, hub, 1)
+	defer active.Close()
+
+	for i := 0; i < 10; i++ {
+		publish(t, hub, New("turn", 1, i))
+		receive(t, active)
+	}
+
+	// The buffered events are still delivered, then the channel ends
+	for i := 0; i < buffer; i++ {
+		receive(t, stalled)
+	}
+	if _, ok := <-stalled.Events(); ok {
+		t.Fatal("stalled subscriber still open, want it disconnected")
+	}
+	if hub.Subscribers() != 1 {
+		t.Fatalf("hub has %d subscribers, want only the active one", hub.Subscribers())
+	}
+
+	// Closing a disconnected subscription is harmless
+	stalled.Close()
+}
+
+func TestSubscribersOnlyReceiveTheirGame(t *testing.T) {
+	hub := NewHub(HubConfig{BufferSize: 4})
+	mine := subscribe(t, hub, 1)
+	other := subscribe(t, hub, 2)
+	defer mine.Close()
+	defer other.Close()
+
+	publish(t, hub, New("turn", 1, nil))
+
+	if got := receive(t, mine); got.GameID != 1 {
+		t.Fatalf("got an event of game %d, want 1", got.GameID)
+	}
+	if len(other.Events()) != 0 {
+		t.Fatal("subscriber of game 2 received an event of game 1")
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(HubConfig{BufferSize: 4})
+	sub := subscribe(t, hub, 1)
+
+	hub.Close()
+
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("subscription still open after the hub closed")
+	}
+	if _, ok := <-subscribe(t, hub, 1).Events(); ok {
+		t.Fatal("subscription to a closed hub is open")
+	}
+	if hub.Subscribers() != 0 {
+		t.Fatalf("closed hub has %d subscribers", hub.Subscribers())
+	}
+	publish(t, hub, New("turn", 1, nil))
+	sub.Close()
+}
+
+func TestSubscribeIsCapped(t *testing.T) {
+	hub := NewHub(HubConfig{BufferSize: 4, MaxSubscribers: 3, MaxSubscribersPerGame: 2})
+	first := subscribe(t, hub, 1)
+	subscribe(t, hub, 1)
+
+	// Game 1 is full, but the hub still has room for another game
+	if _, err := hub.Subscribe(1); errors.GetCode(err) != errors.CodeTooManySubscribers {
+		t.Fatalf("third subscriber of game 1: error = %v, want too many subscribers", err)
+	}
+	subscribe(t, hub, 2)
+
+	// The hub is full, so even a game with room is refused
+	if _, err := hub.Subscribe(3); errors.GetCode(err) != errors.CodeTooManySubscribers {
+		t.Fatalf("fourth subscriber: error = %v, want too many subscribers", err)
+	}
+
+	// Closing a subscription frees its slot, closing it again frees nothing more
+	first.Close()
+	first.Close()
+	subscribe(t, hub, 1)
+	if _, err := hub.Subscribe(1); err == nil {
+		t.Fatal("closing one subscription twice freed two slots")
+	}
+}
+
+func TestClosedHubFreesItsSlots(t *testing.T) {
+	hub := NewHub(HubConfig{BufferSize: 4, MaxSubscribers: 1})
+	subscribe(t, hub, 1)
+
+	hub.Close()
+
+	// Subscriptions to a closed hub start closed and never hold a slot
+	for i := 0; i < 3; i++ {
+		subscribe(t, hub, 1)
+	}
+}
+
+type failingPublisher struct {
+	err       error
+	published int
+}
+
+func (p *failingPublisher) Publish(context.Context, Event) error {
+	p.published++
+	return p.err
+}
+
+func TestFanoutPublishesToEveryPublisher(t *testing.T) {
+	first := &failingPublisher{err: stderrors.New("first down")}
+	second := &failingPublisher{err: stderrors.New("second down")}
+	healthy := &failingPublisher{}
+
+	err := Fanout{first, second, healthy}.Publish(context.Background(), New("turn", 1, nil))
+	if err != first.err {
+		t.Fatalf("Publish() error = %v, want the first failure", err)
+	}
+	if first.published != 1 || second.published != 1 || healthy.published != 1 {
+		t.Fatalf("published %d, %d and %d times, want once each", first.published, second.published, healthy.published)
+	}
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"planets-server/internal/shared/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RedisStreamPublisher appends events to a Redis stream, trimmed to roughly maxLen entries
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamPublisher(client *redis.Client, stream string, maxLen int64) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event Event) error {
+	logger := slog.With("component", "events", "transport", "redis_stream", "event_type", event.Type)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Failed to encode event", "error", err)
+		return fmt.Errorf("failed to encode event %s: %w", event.Type, err)
+	}
+
+	err = p.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"type":    event.Type,
+			"payload": payload,
+		},
+	}).Err()
+	if err != nil {
+		logger.Error("Failed to publish event", "error", err, "stream", p.stream)
+		return fmt.Errorf("failed to publish event %s: %w", event.Type, err)
+	}
+
+	return nil
+}
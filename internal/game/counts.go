@@ -0,0 +1,28 @@
+package game
+
+import "sync/atomic"
+
+// generationCounter tallies what universe generation created. Stages add their counts as they
+// finish, so stages running side by side can share one counter without a lock.
+type generationCounter struct {
+	galaxies atomic.Int64
+	sectors  atomic.Int64
+	systems  atomic.Int64
+	planets  atomic.Int64
+}
+
+func (c *generationCounter) add(counts GameCounts) {
+	c.galaxies.Add(int64(counts.Galaxies))
+	c.sectors.Add(int64(counts.Sectors))
+	c.systems.Add(int64(counts.Systems))
+	c.planets.Add(int64(counts.Planets))
+}
+
+func (c *generationCounter) counts() GameCounts {
+	return GameCounts{
+		Galaxies: int(c.galaxies.Load()),
+		Sectors:  int(c.sectors.Load()),
+		Systems:  int(c.systems.Load()),
+		Planets:  int(c.planets.Load()),
+	}
+}
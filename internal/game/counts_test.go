@@ -0,0 +1,31 @@
+package game
+
+import (
+	"sync"
+	"testing"
+)
+
+// Run with -race: generation stages adding to one counter at once must neither race nor lose counts
+func TestGenerationCounterIsSafeForConcurrentStages(t *testing.T) {
+	const stages, addsPerStage = 32, 1000
+
+	var counter generationCounter
+	var wg sync.WaitGroup
+	for i := 0; i < stages; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < addsPerStage; j++ {
+				counter.add(GameCounts{Galaxies: 1, Sectors: 2, Systems: 3, Planets: 4})
+				_ = counter.counts()
+			}
+		}()
+	}
+	wg.Wait()
+
+	adds := stages * addsPerStage
+	want := GameCounts{Galaxies: adds, Sectors: 2 * adds, Systems: 3 * adds, Planets: 4 * adds}
+	if got := counter.counts(); got != want {
+		t.Fatalf("counts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+
+	"planets-server/internal/planet"
+	"planets-server/internal/spatial"
+)
+
+// UniverseDump is the portable form of a generated universe: every spatial entity and planet,
+// referencing each other by their IDs in the dump rather than by database IDs.
+type UniverseDump struct {
+	Entities []DumpEntity `json:"entities"`
+	Planets  []DumpPlanet `json:"planets"`
+}
+
+type DumpEntity struct {
+	ID         int                `json:"id"`
+	ParentID   *int               `json:"parent_id"`
+	EntityType spatial.EntityType `json:"entity_type"`
+	XCoord     int                `json:"x_coord"`
+	YCoord     int                `json:"y_coord"`
+	Name       string             `json:"name"`
+}
+
+type DumpPlanet struct {
+	ID            int               `json:"id"`
+	SystemID      int               `json:"system_id"`
+	PlanetIndex   int               `json:"planet_index"`
+	Name          string            `json:"name"`
+	Type          planet.PlanetType `json:"type"`
+	Size          int               `json:"size"`
+	MaxPopulation int64             `json:"max_population"`
+}
+
+// DumpProblem points at the dump entry that failed validation
+type DumpProblem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// DumpValidation is the result of checking a dump without importing it
+type DumpValidation struct {
+	Valid    bool          `json:"valid"`
+	Problems []DumpProblem `json:"problems"`
+}
+
+// ValidateDump checks a dump's referential integrity: unique IDs, known entity and planet types,
+// a single universe root, parents one level above their children, and planets attached to systems.
+// Any import must run this first so that validation and import accept exactly the same dumps.
+func ValidateDump(dump UniverseDump) DumpValidation {
+	problems := []DumpProblem{}
+	report := func(path, format string, args ...any) {
+		problems = append(problems, DumpProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	entityTypes := make(map[int]spatial.EntityType, len(dump.Entities))
+	for i, entity := range dump.Entities {
+		path := fmt.Sprintf("entities[%d]", i)
+
+		if _, ok := spatial.EntityLevels[entity.EntityType]; !ok {
+			report(path, "unknown entity type %q", entity.EntityType)
+		}
+		if _, ok := entityTypes[entity.ID]; ok {
+			report(path, "duplicate entity id %d", entity.ID)
+			continue
+		}
+		entityTypes[entity.ID] = entity.EntityType
+	}
+
+	roots := 0
+	for i, entity := range dump.Entities {
+		path := fmt.Sprintf("entities[%d]", i)
+
+		if entity.ParentID == nil {
+			if entity.EntityType != spatial.EntityTypeUniverse {
+				report(path, "%s %d has no parent", entity.EntityType, entity.ID)
+			}
+			roots++
+			continue
+		}
+
+		parentType, ok := entityTypes[*entity.ParentID]
+		if !ok {
+			report(path, "parent %d of entity %d is not in the dump", *entity.ParentID, entity.ID)
+			continue
+		}
+
+		parentLevel, parentKnown := spatial.EntityLevels[parentType]
+		level, known := spatial.EntityLevels[entity.EntityType]
+		if parentKnown && known && level != parentLevel+1 {
+			report(path, "%s %d cannot be a child of %s %d", entity.EntityType, entity.ID, parentType, *entity.ParentID)
+		}
+	}
+
+	if roots != 1 {
+		report("entities", "expected exactly one universe root, found %d", roots)
+	}
+
+	planetIDs := make(map[int]bool, len(dump.Planets))
+	for i, p := range dump.Planets {
+		path := fmt.Sprintf("planets[%d]", i)
+
+		if planetIDs[p.ID] {
+			report(path, "duplicate planet id %d", p.ID)
+		}
+		planetIDs[p.ID] = true
+
+		if !p.Type.IsValid() {
+			report(path, "unknown planet type %q", p.Type)
+		}
+
+		systemType, ok := entityTypes[p.SystemID]
+		switch {
+		case !ok:
+			report(path, "system %d of planet %d is not in the dump", p.SystemID, p.ID)
+		case systemType != spatial.EntityTypeSystem:
+			report(path, "planet %d belongs to %s %d, not a system", p.ID, systemType, p.SystemID)
+		}
+	}
+
+	return DumpValidation{
+		Valid:    len(problems) == 0,
+		Problems: problems,
+	}
+}
@@ -2,13 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
-	"log/slog"
 	"net/http"
 	"strconv"
 
 	"planets-server/internal/game"
+	"planets-server/internal/middleware"
 	appconfig "planets-server/internal/shared/config"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/query"
 	"planets-server/internal/shared/response"
 )
 
@@ -22,7 +24,7 @@ func NewGameHandler(service *game.Service) *GameHandler {
 
 func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "create_game")
+	logger := logger.FromContext(r.Context()).With("handler", "create_game")
 
 	if r.Method != http.MethodPost {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -32,13 +34,15 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 	defaults := appconfig.GlobalConfig.Game
 
 	gameConfig := game.GameConfig{
-		MaxPlayers:          defaults.MaxPlayers,
-		TurnIntervalHours:   defaults.TurnIntervalHours,
-		GalaxyCount:         defaults.GalaxyCount,
-		SectorsPerGalaxy:    defaults.SectorsPerGalaxy,
-		SystemsPerSector:    defaults.SystemsPerSector,
-		MinPlanetsPerSystem: defaults.MinPlanetsPerSystem,
-		MaxPlanetsPerSystem: defaults.MaxPlanetsPerSystem,
+		MinPlayers:            defaults.MinPlayers,
+		MaxPlayers:            defaults.MaxPlayers,
+		TurnIntervalHours:     defaults.TurnIntervalHours,
+		GalaxyCount:           defaults.GalaxyCount,
+		SectorsPerGalaxy:      defaults.SectorsPerGalaxy,
+		SystemsPerSector:      defaults.SystemsPerSector,
+		MinPlanetsPerSystem:   defaults.MinPlanetsPerSystem,
+		MaxPlanetsPerSystem:   defaults.MaxPlanetsPerSystem,
+		SpawnSystemsPerSector: defaults.SpawnSystemsPerSector,
 	}
 
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
@@ -47,7 +51,13 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdGame, err := h.service.CreateGame(ctx, gameConfig)
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	createdGame, err := h.service.CreateGame(ctx, gameConfig, claims.PlayerID)
 	if err != nil {
 		response.Error(w, r, logger, err)
 		return
@@ -58,14 +68,20 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 
 func (h *GameHandler) GetGames(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "get_games")
+	logger := logger.FromContext(r.Context()).With("handler", "get_games")
 
 	if r.Method != http.MethodGet {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
 		return
 	}
 
-	games, err := h.service.GetAllGames(ctx)
+	params, err := query.ParseListParams(r, game.ListSortFields, game.ListFilters)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	games, err := h.service.GetAllGames(ctx, params)
 	if err != nil {
 		response.Error(w, r, logger, err)
 		return
@@ -80,7 +96,7 @@ func (h *GameHandler) GetGames(w http.ResponseWriter, r *http.Request) {
 
 func (h *GameHandler) DeleteGame(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "delete_game")
+	logger := logger.FromContext(r.Context()).With("handler", "delete_game")
 
 	if r.Method != http.MethodDelete {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -109,7 +125,7 @@ func (h *GameHandler) DeleteGame(w http.ResponseWriter, r *http.Request) {
 
 func (h *GameHandler) GetGameStats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "get_game_stats")
+	logger := logger.FromContext(r.Context()).With("handler", "get_game_stats")
 
 	if r.Method != http.MethodGet {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -134,5 +150,318 @@ func (h *GameHandler) GetGameStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response.SetCacheControl(w, stats.Status == game.GameStatusCompleted, true)
 	response.Success(w, http.StatusOK, stats)
 }
+
+func (h *GameHandler) GetPublicGameStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_public_game_stats")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	stats, err := h.service.GetPublicGameStats(ctx, gameID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, stats.Status == game.GameStatusCompleted, false)
+	response.Success(w, http.StatusOK, stats)
+}
+
+func (h *GameHandler) ReconcileCounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "reconcile_game_counts")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	reconciled, err := h.service.ReconcileCounts(ctx)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	if reconciled > 0 {
+		logger.Warn("Corrected drifted game counts", "games", reconciled)
+	}
+
+	response.Success(w, http.StatusOK, map[string]int{"reconciled_games": reconciled})
+}
+
+func (h *GameHandler) AddGalaxy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "add_galaxy")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	defaults := appconfig.GlobalConfig.Game
+
+	gameConfig := game.GameConfig{
+		SectorsPerGalaxy:      defaults.SectorsPerGalaxy,
+		SystemsPerSector:      defaults.SystemsPerSector,
+		MinPlanetsPerSystem:   defaults.MinPlanetsPerSystem,
+		MaxPlanetsPerSystem:   defaults.MaxPlanetsPerSystem,
+		SpawnSystemsPerSector: defaults.SpawnSystemsPerSector,
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	if err := json.NewDecoder(r.Body).Decode(&gameConfig); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	summary, err := h.service.AddGalaxy(ctx, gameID, gameConfig)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, summary)
+}
+
+func (h *GameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "join_game")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	result, err := h.service.JoinGame(ctx, gameID, claims.PlayerID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	logger.Info("Player joined game", "game_id", gameID, "player_id", claims.PlayerID, "home_planet_assigned", result.HomePlanet != nil)
+	response.Success(w, http.StatusCreated, result)
+}
+
+type transferGameMasterRequest struct {
+	PlayerID int `json:"player_id"`
+}
+
+// TransferGameMaster hands game master control to another member of the game
+func (h *GameHandler) TransferGameMaster(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "transfer_game_master")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	var req transferGameMasterRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10) // 1 KB
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	if req.PlayerID <= 0 {
+		response.Error(w, r, logger, errors.Validation("player_id is required"))
+		return
+	}
+
+	updated, err := h.service.TransferGameMaster(ctx, gameID, claims.PlayerID, claims.Role == "admin", req.PlayerID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, updated)
+}
+
+func (h *GameHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "update_game_settings")
+
+	if r.Method != http.MethodPatch {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	var update game.SettingsUpdate
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid settings in request body", err))
+		return
+	}
+
+	updated, err := h.service.UpdateSettings(ctx, gameID, update)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, updated)
+}
+
+func (h *GameHandler) GetTurnTimer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_turn_timer")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	timer, err := h.service.GetTurnTimer(ctx, gameID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, false, true)
+	response.Success(w, http.StatusOK, timer)
+}
+
+// GetTurnLog returns the turn log entry of one processed turn
+func (h *GameHandler) GetTurnLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_turn_log")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	gameID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	turn, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid turn format", err))
+		return
+	}
+
+	entry, err := h.service.GetTurnLog(ctx, gameID, turn)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	// A processed turn never changes, but only the game's players may see it
+	response.SetCacheControl(w, true, true)
+	response.Success(w, http.StatusOK, entry)
+}
+
+// ImportGame accepts a universe dump. Only dry runs (?validate=true) are supported for now:
+// the dump is checked and its problems reported without writing anything.
+func (h *GameHandler) ImportGame(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "import_game")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	if r.URL.Query().Get("validate") != "true" {
+		response.Error(w, r, logger, errors.Validation("importing is not available yet, use validate=true to check a dump"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 256<<20) // 256 MB, dumps of large universes are big
+
+	var dump game.UniverseDump
+	if err := json.NewDecoder(r.Body).Decode(&dump); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	response.Success(w, http.StatusOK, game.ValidateDump(dump))
+}
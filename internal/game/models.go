@@ -1,6 +1,8 @@
 package game
 
 import (
+	"planets-server/internal/planet"
+	"planets-server/internal/shared/query"
 	"planets-server/internal/spatial"
 	"time"
 )
@@ -9,46 +11,182 @@ type GameStatus string
 
 const (
 	GameStatusCreating  GameStatus = "creating"
+	GameStatusScheduled GameStatus = "scheduled"
 	GameStatusActive    GameStatus = "active"
 	GameStatusPaused    GameStatus = "paused"
 	GameStatusCompleted GameStatus = "completed"
+	GameStatusCancelled GameStatus = "cancelled"
+)
+
+// ListSortFields and ListFilters are the sort and filter options accepted by the game list
+var (
+	ListSortFields = map[string]string{
+		"name":         "name",
+		"status":       "status",
+		"current_turn": "current_turn",
+		"planet_count": "planet_count",
+		"player_count": "player_count",
+		"start_at":     "start_at",
+		"created_at":   "created_at",
+	}
+	ListFilters = map[string]query.Filter{
+		"status": {Column: "status", Parse: query.OneOf(
+			string(GameStatusCreating), string(GameStatusScheduled), string(GameStatusActive),
+			string(GameStatusPaused), string(GameStatusCompleted), string(GameStatusCancelled),
+		)},
+	}
 )
 
 type Game struct {
-	ID                int        `json:"id"`
-	Name              string     `json:"name"`
-	Seed              string     `json:"seed"`
-	UniverseID        *int       `json:"universe_id"`
-	PlanetCount       int        `json:"planet_count"`
-	Status            GameStatus `json:"status"`
-	CurrentTurn       int        `json:"current_turn"`
-	MaxPlayers        int        `json:"max_players"`
-	TurnIntervalHours int        `json:"turn_interval_hours"`
-	NextTurnAt        *time.Time `json:"next_turn_at"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID                int          `json:"id"`
+	Name              string       `json:"name"`
+	Seed              string       `json:"seed"`
+	UniverseID        *int         `json:"universe_id"`
+	GalaxyCount       int          `json:"galaxy_count"`
+	SectorCount       int          `json:"sector_count"`
+	SystemCount       int          `json:"system_count"`
+	PlanetCount       int          `json:"planet_count"`
+	Status            GameStatus   `json:"status"`
+	CurrentTurn       int          `json:"current_turn"`
+	MinPlayers        int          `json:"min_players"`
+	MaxPlayers        int          `json:"max_players"`
+	TurnIntervalHours int          `json:"turn_interval_hours"`
+	NextTurnAt        *time.Time   `json:"next_turn_at"`
+	StartAt           *time.Time   `json:"start_at"`
+	Settings          GameSettings `json:"settings"`
+	GMPlayerID        *int         `json:"gm_player_id"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// IsGameMaster reports whether the player holds per-game authority over the game.
+// Global admins are checked separately by callers.
+func (g *Game) IsGameMaster(playerID int) bool {
+	return g.GMPlayerID != nil && *g.GMPlayerID == playerID
 }
 
 type GameConfig struct {
 	Seed                string `json:"seed,omitempty"`
+	MinPlayers          int    `json:"min_players"`
 	MaxPlayers          int    `json:"max_players"`
 	TurnIntervalHours   int    `json:"turn_interval_hours"`
-	GalaxyCount         int `json:"galaxy_count"`
-	SectorsPerGalaxy    int `json:"sectors_per_galaxy"`
-	SystemsPerSector    int `json:"systems_per_sector"`
-	MinPlanetsPerSystem int `json:"min_planets_per_system"`
-	MaxPlanetsPerSystem int `json:"max_planets_per_system"`
+	GalaxyCount         int    `json:"galaxy_count"`
+	SectorsPerGalaxy    int    `json:"sectors_per_galaxy"`
+	SystemsPerSector    int    `json:"systems_per_sector"`
+	MinPlanetsPerSystem int    `json:"min_planets_per_system"`
+	MaxPlanetsPerSystem int    `json:"max_planets_per_system"`
+	// SpawnSystemsPerSector is how many systems per sector are guaranteed a habitable planet
+	SpawnSystemsPerSector int `json:"spawn_systems_per_sector"`
+	// StartAt delays activation: the game stays scheduled until this time, letting players join first.
+	// A scheduled game also waits until MinPlayers have joined.
+	StartAt *time.Time `json:"start_at,omitempty"`
+	// Settings holds optional gameplay tuning, see GameSettings
+	Settings GameSettings `json:"settings"`
+}
+
+// Overview counts games by status and the planets in active games
+type Overview struct {
+	GamesByStatus map[GameStatus]int `json:"games_by_status"`
+	ActivePlanets int                `json:"active_planets"`
 }
 
 type GameStats struct {
-	ID          int        `json:"id"`
-	Name        string     `json:"name"`
-	Status      GameStatus `json:"status"`
-	CurrentTurn int        `json:"current_turn"`
-	PlayerCount int        `json:"player_count"`
-	MaxPlayers  int        `json:"max_players"`
-	NextTurnAt  *time.Time `json:"next_turn_at"`
-	PlanetCount int        `json:"planet_count"`
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Status      GameStatus  `json:"status"`
+	CurrentTurn int         `json:"current_turn"`
+	PlayerCount int         `json:"player_count"`
+	MaxPlayers  int         `json:"max_players"`
+	NextTurnAt  *time.Time  `json:"next_turn_at"`
+	GalaxyCount int         `json:"galaxy_count"`
+	SectorCount int         `json:"sector_count"`
+	SystemCount int         `json:"system_count"`
+	PlanetCount int         `json:"planet_count"`
+	Lobby       *LobbyState `json:"lobby,omitempty"`
+
+	startAt    *time.Time
+	minPlayers int
+}
+
+// LobbyState describes a scheduled game that is waiting to start
+type LobbyState struct {
+	StartAt           time.Time  `json:"start_at"`
+	SecondsUntilStart int64      `json:"seconds_until_start"`
+	MinPlayers        int        `json:"min_players"`
+	PlayersNeeded     int        `json:"players_needed"`
+	CancelsAt         *time.Time `json:"cancels_at,omitempty"`
+}
+
+// PublicGameStats is the unauthenticated view of a game, limited to non-sensitive aggregates
+type PublicGameStats struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Status      GameStatus         `json:"status"`
+	CurrentTurn int                `json:"current_turn"`
+	PlayerCount int                `json:"player_count"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard"`
+}
+
+type LeaderboardEntry struct {
+	Rank            int    `json:"rank"`
+	DisplayName     string `json:"display_name"`
+	TotalPlanets    int    `json:"total_planets"`
+	TotalPopulation int64  `json:"total_population"`
+}
+
+// TurnTimer tells clients how long until the next turn is processed
+type TurnTimer struct {
+	CurrentTurn       int        `json:"current_turn"`
+	NextTurnAt        *time.Time `json:"next_turn_at"`
+	SecondsRemaining  int64      `json:"seconds_remaining"`
+	TurnIntervalHours int        `json:"turn_interval"`
+}
+
+// JoinResult is a player's membership in a game, with the home planet they were given
+// when they joined a game already in progress
+type JoinResult struct {
+	GameID     int            `json:"game_id"`
+	PlayerID   int            `json:"player_id"`
+	HomePlanet *planet.Planet `json:"home_planet,omitempty"`
+}
+
+// TurnAdvance is the outcome of processing one turn of a game
+type TurnAdvance struct {
+	GameID     int
+	Turn       int
+	NextTurnAt time.Time
+}
+
+// TurnSummary records what processing a turn changed, as stored in the turn log
+type TurnSummary struct {
+	BuildingGrowthPlanets int64 `json:"building_growth_planets"`
+	ResearchCompleted     int64 `json:"research_completed"`
+	ResearchGrowthPlanets int64 `json:"research_growth_planets"`
+	DecayedPlanets        int64 `json:"decayed_planets"`
+}
+
+// TurnLogEntry is the ledger row of one processed turn
+type TurnLogEntry struct {
+	GameID      int         `json:"game_id"`
+	Turn        int         `json:"turn"`
+	Summary     TurnSummary `json:"summary"`
+	ProcessedAt time.Time   `json:"processed_at"`
+}
+
+// GameCounts is the size of a game's universe at each level
+type GameCounts struct {
+	Galaxies int
+	Sectors  int
+	Systems  int
+	Planets  int
+}
+
+// GalaxySummary describes a galaxy added to an existing game
+type GalaxySummary struct {
+	GalaxyID    int `json:"galaxy_id"`
+	SectorCount int `json:"sector_count"`
+	SystemCount int `json:"system_count"`
+	PlanetCount int `json:"planet_count"`
 }
 
 type SpatialLevel struct {
@@ -3,9 +3,13 @@ package game
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -25,56 +29,81 @@ func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
 	return r.db
 }
 
-func (r *Repository) CreateGame(ctx context.Context, name string, seed string, config GameConfig, tx *database.Tx) (*Game, error) {
+func (r *Repository) CreateGame(ctx context.Context, name string, seed string, config GameConfig, gmPlayerID int, tx *database.Tx) (*Game, error) {
 	exec := r.getExecutor(tx)
 
 	query := `
-		INSERT INTO games (name, seed, status, current_turn, max_players, turn_interval_hours)
-		VALUES ($1, $2, 'creating', 0, $3, $4)
-		RETURNING id, name, seed, planet_count, status, current_turn, max_players, turn_interval_hours, next_turn_at, created_at, updated_at
+		INSERT INTO games (name, seed, status, current_turn, min_players, max_players, turn_interval_hours, start_at, settings, gm_player_id)
+		VALUES ($1, $2, 'creating', 0, $3, $4, $5, $6, $7, $8)
+		RETURNING id, name, seed, galaxy_count, sector_count, system_count, planet_count, status, current_turn, min_players, max_players, turn_interval_hours, next_turn_at, start_at, settings, gm_player_id, created_at, updated_at
 	`
 
 	var game Game
-	err := exec.QueryRowContext(ctx, query, name, seed, config.MaxPlayers, config.TurnIntervalHours).Scan(
+	err := exec.QueryRowContext(ctx, query, name, seed, config.MinPlayers, config.MaxPlayers, config.TurnIntervalHours, config.StartAt, config.Settings, gmPlayerID).Scan(
 		&game.ID,
 		&game.Name,
 		&game.Seed,
+		&game.GalaxyCount,
+		&game.SectorCount,
+		&game.SystemCount,
 		&game.PlanetCount,
 		&game.Status,
 		&game.CurrentTurn,
+		&game.MinPlayers,
 		&game.MaxPlayers,
 		&game.TurnIntervalHours,
 		&game.NextTurnAt,
+		&game.StartAt,
+		&game.Settings,
+		&game.GMPlayerID,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
 
 	if err != nil {
-		return nil, errors.WrapInternal("failed to create game", err)
+		return nil, database.ClassifyError("failed to create game", err)
 	}
 
 	return &game, nil
 }
 
 func (r *Repository) GetGameByID(ctx context.Context, gameID int) (*Game, error) {
+	return database.Read(ctx, r.db, func() (*Game, error) {
+		return r.getGame(ctx, r.db, gameID, "")
+	})
+}
+
+// GetGameByIDForUpdate loads a game and locks its row until tx ends
+func (r *Repository) GetGameByIDForUpdate(ctx context.Context, gameID int, tx *database.Tx) (*Game, error) {
+	return r.getGame(ctx, tx, gameID, "FOR UPDATE")
+}
+
+func (r *Repository) getGame(ctx context.Context, exec database.Executor, gameID int, lockClause string) (*Game, error) {
 	query := `
-		SELECT id, name, seed, universe_id, planet_count, status, current_turn, max_players, turn_interval_hours, next_turn_at, created_at, updated_at
+		SELECT id, name, seed, universe_id, galaxy_count, sector_count, system_count, planet_count, status, current_turn, min_players, max_players, turn_interval_hours, next_turn_at, start_at, settings, gm_player_id, created_at, updated_at
 		FROM games
 		WHERE id = $1
-	`
+		` + lockClause
 
 	var game Game
-	err := r.db.QueryRowContext(ctx, query, gameID).Scan(
+	err := exec.QueryRowContext(ctx, query, gameID).Scan(
 		&game.ID,
 		&game.Name,
 		&game.Seed,
 		&game.UniverseID,
+		&game.GalaxyCount,
+		&game.SectorCount,
+		&game.SystemCount,
 		&game.PlanetCount,
 		&game.Status,
 		&game.CurrentTurn,
+		&game.MinPlayers,
 		&game.MaxPlayers,
 		&game.TurnIntervalHours,
 		&game.NextTurnAt,
+		&game.StartAt,
+		&game.Settings,
+		&game.GMPlayerID,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -89,14 +118,26 @@ func (r *Repository) GetGameByID(ctx context.Context, gameID int) (*Game, error)
 	return &game, nil
 }
 
-func (r *Repository) GetAllGames(ctx context.Context) ([]Game, error) {
-	query := `
-		SELECT id, name, seed, universe_id, planet_count, status, current_turn, max_players, turn_interval_hours, next_turn_at, created_at, updated_at
+func (r *Repository) GetAllGames(ctx context.Context, params query.ListParams) ([]Game, error) {
+	return database.Read(ctx, r.db, func() ([]Game, error) {
+		return r.getAllGames(ctx, params)
+	})
+}
+
+func (r *Repository) getAllGames(ctx context.Context, params query.ListParams) ([]Game, error) {
+	where, args := params.Where(1)
+	if where != "" {
+		where = "WHERE " + where
+	}
+
+	sqlQuery := `
+		SELECT id, name, seed, universe_id, galaxy_count, sector_count, system_count, planet_count, status, current_turn, min_players, max_players, turn_interval_hours, next_turn_at, start_at, settings, gm_player_id, created_at, updated_at
 		FROM games
-		ORDER BY created_at DESC
+		` + where + `
+		ORDER BY ` + params.OrderBy("created_at DESC") + `, id DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, errors.WrapInternal("failed to query games", err)
 	}
@@ -110,12 +151,19 @@ func (r *Repository) GetAllGames(ctx context.Context) ([]Game, error) {
 			&game.Name,
 			&game.Seed,
 			&game.UniverseID,
+			&game.GalaxyCount,
+			&game.SectorCount,
+			&game.SystemCount,
 			&game.PlanetCount,
 			&game.Status,
 			&game.CurrentTurn,
+			&game.MinPlayers,
 			&game.MaxPlayers,
 			&game.TurnIntervalHours,
 			&game.NextTurnAt,
+			&game.StartAt,
+			&game.Settings,
+			&game.GMPlayerID,
 			&game.CreatedAt,
 			&game.UpdatedAt,
 		)
@@ -160,6 +208,368 @@ func (r *Repository) ActivateGame(ctx context.Context, gameID int, tx *database.
 	return nil
 }
 
+// UpdateMutableSettings persists the settings an admin may change on an existing game.
+// max_players is checked against the live player count in the same statement.
+func (r *Repository) UpdateMutableSettings(ctx context.Context, game *Game, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE games
+		SET turn_interval_hours = $1, next_turn_at = $2, min_players = $3, max_players = $4, settings = $5
+		WHERE id = $6 AND player_count <= $4
+	`
+
+	result, err := exec.ExecContext(ctx, query,
+		game.TurnIntervalHours, game.NextTurnAt, game.MinPlayers, game.MaxPlayers, game.Settings, game.ID,
+	)
+	if err != nil {
+		return errors.WrapInternal("failed to update game settings", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapInternal("failed to get rows affected after settings update", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.WithCode(
+			errors.Conflictf("max_players cannot be lower than the number of joined players (game id: %d)", game.ID),
+			errors.CodeTooManyPlayers,
+		)
+	}
+
+	return nil
+}
+
+// ScheduleGame parks a freshly generated game until its start_at time
+func (r *Repository) ScheduleGame(ctx context.Context, gameID int, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE games
+		SET status = 'scheduled'
+		WHERE id = $1 AND status = 'creating' AND start_at IS NOT NULL
+	`
+
+	result, err := exec.ExecContext(ctx, query, gameID)
+	if err != nil {
+		return errors.WrapInternal("failed to schedule game", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapInternal("failed to get rows affected after scheduling", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.Conflictf("game not found or not ready for scheduling (id: %d)", gameID)
+	}
+
+	return nil
+}
+
+// LockDueLobby locks one scheduled game whose start_at has passed and whose lobby has enough
+// players, skipping games another transaction already holds and the IDs in skip. It returns
+// nil when no such game is left.
+func (r *Repository) LockDueLobby(ctx context.Context, now time.Time, skip []int, tx *database.Tx) (*int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT id
+		FROM games
+		WHERE status = 'scheduled' AND start_at <= $1 AND player_count >= min_players
+			AND NOT (id = ANY(COALESCE($2::int[], '{}')))
+		ORDER BY start_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	var gameID int
+	err := exec.QueryRowContext(ctx, query, now, pq.Array(skip)).Scan(&gameID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, database.ClassifyError("failed to lock due lobby", err)
+	}
+
+	return &gameID, nil
+}
+
+// StartLobby moves a scheduled game to active, with its first turn due on the next full hour
+func (r *Repository) StartLobby(ctx context.Context, gameID int, now time.Time, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	nextTurnAt := now.Add(1 * time.Hour).Truncate(time.Hour)
+
+	query := `
+		UPDATE games
+		SET status = 'active', current_turn = 1, next_turn_at = $1
+		WHERE id = $2 AND status = 'scheduled'
+	`
+
+	result, err := exec.ExecContext(ctx, query, nextTurnAt, gameID)
+	if err != nil {
+		return errors.WrapInternal("failed to activate scheduled game", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapInternal("failed to get rows affected after activation", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.Conflictf("game is not scheduled (id: %d)", gameID)
+	}
+
+	return nil
+}
+
+// GetPlayerIDs returns the IDs of the game's players in the order they joined
+func (r *Repository) GetPlayerIDs(ctx context.Context, gameID int, tx *database.Tx) ([]int, error) {
+	exec := r.getExecutor(tx)
+
+	rows, err := exec.QueryContext(ctx, `SELECT player_id FROM game_players WHERE game_id = $1 ORDER BY joined_at, id`, gameID)
+	if err != nil {
+		return nil, database.ClassifyError("failed to list game players", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var playerIDs []int
+	for rows.Next() {
+		var playerID int
+		if err := rows.Scan(&playerID); err != nil {
+			return nil, errors.WrapInternal("failed to scan game player", err)
+		}
+		playerIDs = append(playerIDs, playerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating game players", err)
+	}
+
+	return playerIDs, nil
+}
+
+// LockGameForJoin locks the game row and returns its status and player cap, so concurrent
+// joins are serialised and the player count read afterwards stays accurate until commit
+func (r *Repository) LockGameForJoin(ctx context.Context, gameID int, tx *database.Tx) (GameStatus, int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `SELECT status, max_players FROM games WHERE id = $1 FOR UPDATE`
+
+	var status GameStatus
+	var maxPlayers int
+	err := exec.QueryRowContext(ctx, query, gameID).Scan(&status, &maxPlayers)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, errors.NotFoundf("game not found with id: %d", gameID)
+		}
+		return "", 0, database.ClassifyError("failed to lock game for join", err)
+	}
+
+	return status, maxPlayers, nil
+}
+
+// IsPlayerInGame reports whether the player has joined the game
+func (r *Repository) IsPlayerInGame(ctx context.Context, gameID, playerID int, tx *database.Tx) (bool, error) {
+	exec := r.getExecutor(tx)
+
+	var member bool
+	err := exec.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM game_players WHERE game_id = $1 AND player_id = $2)`,
+		gameID, playerID,
+	).Scan(&member)
+	if err != nil {
+		return false, database.ClassifyError("failed to check game membership", err)
+	}
+
+	return member, nil
+}
+
+// SetGameMaster makes the player the game's game master
+func (r *Repository) SetGameMaster(ctx context.Context, gameID, playerID int, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	_, err := exec.ExecContext(ctx, `UPDATE games SET gm_player_id = $2 WHERE id = $1`, gameID, playerID)
+	if err != nil {
+		return database.ClassifyError("failed to set game master", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) CountPlayers(ctx context.Context, gameID int, tx *database.Tx) (int, error) {
+	exec := r.getExecutor(tx)
+
+	var count int
+	err := exec.QueryRowContext(ctx, `SELECT COUNT(*) FROM game_players WHERE game_id = $1`, gameID).Scan(&count)
+	if err != nil {
+		return 0, database.ClassifyError("failed to count game players", err)
+	}
+
+	return count, nil
+}
+
+// AddPlayer inserts the player into the game. Joining twice violates the unique
+// (game_id, player_id) constraint and surfaces as a conflict.
+func (r *Repository) AddPlayer(ctx context.Context, gameID, playerID int, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	_, err := exec.ExecContext(ctx, `INSERT INTO game_players (game_id, player_id) VALUES ($1, $2)`, gameID, playerID)
+	if err != nil {
+		return database.ClassifyError("player has already joined this game", err)
+	}
+
+	return nil
+}
+
+// LockDueTurn locks one active game whose next turn is due at now, skipping games another
+// transaction already holds and the IDs in skip. It returns nil when no such game is left.
+func (r *Repository) LockDueTurn(ctx context.Context, now time.Time, skip []int, tx *database.Tx) (*int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT id
+		FROM games
+		WHERE status = 'active' AND next_turn_at <= $1 AND NOT (id = ANY(COALESCE($2::int[], '{}')))
+		ORDER BY next_turn_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	var gameID int
+	err := exec.QueryRowContext(ctx, query, now, pq.Array(skip)).Scan(&gameID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, database.ClassifyError("failed to lock due game turn", err)
+	}
+
+	return &gameID, nil
+}
+
+// AdvanceTurn increments the game's turn and moves next_turn_at forward by one interval
+func (r *Repository) AdvanceTurn(ctx context.Context, gameID int, tx *database.Tx) (*TurnAdvance, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE games
+		SET current_turn = current_turn + 1,
+			next_turn_at = next_turn_at + make_interval(hours => turn_interval_hours)
+		WHERE id = $1 AND status = 'active'
+		RETURNING id, current_turn, next_turn_at
+	`
+
+	var advance TurnAdvance
+	err := exec.QueryRowContext(ctx, query, gameID).Scan(&advance.GameID, &advance.Turn, &advance.NextTurnAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Conflictf("game is not active (id: %d)", gameID)
+		}
+		return nil, database.ClassifyError("failed to advance game turn", err)
+	}
+
+	return &advance, nil
+}
+
+// ClaimTurn adds the ledger row for a turn about to be applied. It returns false when the
+// turn is already in the ledger, meaning its effects were applied before.
+func (r *Repository) ClaimTurn(ctx context.Context, gameID, turn int, tx *database.Tx) (bool, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		INSERT INTO turn_log (game_id, turn)
+		VALUES ($1, $2)
+		ON CONFLICT (game_id, turn) DO NOTHING`
+
+	result, err := exec.ExecContext(ctx, query, gameID, turn)
+	if err != nil {
+		return false, database.ClassifyError("failed to claim turn in the turn log", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapInternal("failed to check turn log claim", err)
+	}
+
+	return claimed == 1, nil
+}
+
+// SaveTurnSummary stores what a claimed turn changed
+func (r *Repository) SaveTurnSummary(ctx context.Context, gameID, turn int, summary TurnSummary, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return errors.WrapInternal("failed to encode turn summary", err)
+	}
+
+	_, err = exec.ExecContext(ctx, `UPDATE turn_log SET summary = $3 WHERE game_id = $1 AND turn = $2`, gameID, turn, data)
+	if err != nil {
+		return database.ClassifyError("failed to save turn summary", err)
+	}
+
+	return nil
+}
+
+// GetTurnLog returns the ledger row of one processed turn
+func (r *Repository) GetTurnLog(ctx context.Context, gameID, turn int) (*TurnLogEntry, error) {
+	query := `
+		SELECT game_id, turn, summary, processed_at
+		FROM turn_log
+		WHERE game_id = $1 AND turn = $2`
+
+	var entry TurnLogEntry
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, gameID, turn).Scan(&entry.GameID, &entry.Turn, &data, &entry.ProcessedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("turn %d of game %d has not been processed", turn, gameID)
+		}
+		return nil, errors.WrapInternal("failed to get turn log", err)
+	}
+
+	if err := json.Unmarshal(data, &entry.Summary); err != nil {
+		return nil, errors.WrapInternal("failed to decode turn summary", err)
+	}
+
+	return &entry, nil
+}
+
+// CancelExpiredLobbies cancels scheduled games that were due to start before cutoff
+// but never reached min_players, and returns their IDs.
+func (r *Repository) CancelExpiredLobbies(ctx context.Context, cutoff time.Time) ([]int, error) {
+	query := `
+		UPDATE games
+		SET status = 'cancelled'
+		WHERE status = 'scheduled' AND start_at <= $1 AND player_count < min_players
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to cancel expired lobbies", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var gameIDs []int
+	for rows.Next() {
+		var gameID int
+		if err := rows.Scan(&gameID); err != nil {
+			return nil, errors.WrapInternal("failed to scan cancelled game id", err)
+		}
+		gameIDs = append(gameIDs, gameID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating cancelled games", err)
+	}
+
+	return gameIDs, nil
+}
+
 func (r *Repository) GetGameStats(ctx context.Context, gameID int) (*GameStats, error) {
 	query := `
 		SELECT
@@ -167,16 +577,16 @@ func (r *Repository) GetGameStats(ctx context.Context, gameID int) (*GameStats,
 			g.name,
 			g.status,
 			g.current_turn,
-			COALESCE(player_count.count, 0) as player_count,
+			g.player_count,
 			g.max_players,
 			g.next_turn_at,
-			g.planet_count
+			g.galaxy_count,
+			g.sector_count,
+			g.system_count,
+			g.planet_count,
+			g.start_at,
+			g.min_players
 		FROM games g
-		LEFT JOIN (
-			SELECT game_id, COUNT(*) as count
-			FROM game_players
-			WHERE game_id = $1
-		) player_count ON g.id = player_count.game_id
 		WHERE g.id = $1
 	`
 
@@ -189,7 +599,12 @@ func (r *Repository) GetGameStats(ctx context.Context, gameID int) (*GameStats,
 		&stats.PlayerCount,
 		&stats.MaxPlayers,
 		&stats.NextTurnAt,
+		&stats.GalaxyCount,
+		&stats.SectorCount,
+		&stats.SystemCount,
 		&stats.PlanetCount,
+		&stats.startAt,
+		&stats.minPlayers,
 	)
 
 	if err != nil {
@@ -202,6 +617,67 @@ func (r *Repository) GetGameStats(ctx context.Context, gameID int) (*GameStats,
 	return &stats, nil
 }
 
+// GetPublicGameStats returns the game summary and the top players by population.
+// Only display names and aggregates are selected so nothing private leaves the database.
+func (r *Repository) GetPublicGameStats(ctx context.Context, gameID int, leaderboardSize int) (*PublicGameStats, error) {
+	query := `
+		SELECT
+			g.id,
+			g.name,
+			g.status,
+			g.current_turn,
+			g.player_count
+		FROM games g
+		WHERE g.id = $1
+	`
+
+	var stats PublicGameStats
+	err := r.db.QueryRowContext(ctx, query, gameID).Scan(
+		&stats.ID,
+		&stats.Name,
+		&stats.Status,
+		&stats.CurrentTurn,
+		&stats.PlayerCount,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("game not found with id: %d", gameID)
+		}
+		return nil, errors.WrapInternal("failed to get public game stats", err)
+	}
+
+	leaderboardQuery := `
+		SELECT p.display_name, ps.total_planets, ps.total_population
+		FROM player_stats ps
+		JOIN players p ON p.id = ps.player_id
+		WHERE ps.game_id = $1
+		ORDER BY ps.total_population DESC, ps.total_planets DESC, ps.player_id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, leaderboardQuery, gameID, leaderboardSize)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query leaderboard", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats.Leaderboard = []LeaderboardEntry{}
+	for rows.Next() {
+		entry := LeaderboardEntry{Rank: len(stats.Leaderboard) + 1}
+		if err := rows.Scan(&entry.DisplayName, &entry.TotalPlanets, &entry.TotalPopulation); err != nil {
+			return nil, errors.WrapInternal("failed to scan leaderboard entry", err)
+		}
+		stats.Leaderboard = append(stats.Leaderboard, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating leaderboard", err)
+	}
+
+	return &stats, nil
+}
+
 func (r *Repository) DeleteGame(ctx context.Context, gameID int) error {
 	query := `DELETE FROM games WHERE id = $1`
 	result, err := r.db.ExecContext(ctx, query, gameID)
@@ -233,15 +709,16 @@ func (r *Repository) SetUniverseID(ctx context.Context, gameID int, universeID i
 	return nil
 }
 
-func (r *Repository) UpdateGameCounts(ctx context.Context, gameID int, planetCount int, tx *database.Tx) error {
+// UpdateGameCounts stores the size of a freshly generated universe on the game
+func (r *Repository) UpdateGameCounts(ctx context.Context, gameID int, counts GameCounts, tx *database.Tx) error {
 	exec := r.getExecutor(tx)
 
 	query := `
 		UPDATE games
-		SET planet_count = $2, updated_at = NOW()
+		SET galaxy_count = $2, sector_count = $3, system_count = $4, planet_count = $5, updated_at = NOW()
 		WHERE id = $1`
 
-	result, err := exec.ExecContext(ctx, query, gameID, planetCount)
+	result, err := exec.ExecContext(ctx, query, gameID, counts.Galaxies, counts.Sectors, counts.Systems, counts.Planets)
 	if err != nil {
 		return errors.WrapInternal("failed to update game counts", err)
 	}
@@ -257,3 +734,97 @@ func (r *Repository) UpdateGameCounts(ctx context.Context, gameID int, planetCou
 
 	return nil
 }
+
+// AddSpatialCounts grows the game's galaxy, sector and system counts after an expansion.
+// Planets are left out since the planet insert trigger already counts them.
+func (r *Repository) AddSpatialCounts(ctx context.Context, gameID int, counts GameCounts, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE games
+		SET galaxy_count = galaxy_count + $2, sector_count = sector_count + $3, system_count = system_count + $4, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := exec.ExecContext(ctx, query, gameID, counts.Galaxies, counts.Sectors, counts.Systems)
+	if err != nil {
+		return errors.WrapInternal("failed to update game spatial counts", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapInternal("failed to get rows affected after spatial count update", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFoundf("game not found with id: %d", gameID)
+	}
+
+	return nil
+}
+
+// GetOverview counts games by status and totals the planets of active games
+func (r *Repository) GetOverview(ctx context.Context) (*Overview, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, COUNT(*), COALESCE(SUM(planet_count), 0)
+		FROM games
+		GROUP BY status`)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query game overview", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	overview := &Overview{GamesByStatus: map[GameStatus]int{}}
+	for rows.Next() {
+		var status GameStatus
+		var games, planets int
+		if err := rows.Scan(&status, &games, &planets); err != nil {
+			return nil, errors.WrapInternal("failed to scan game overview", err)
+		}
+		overview.GamesByStatus[status] = games
+		if status == GameStatusActive {
+			overview.ActivePlanets = planets
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating game overview", err)
+	}
+
+	return overview, nil
+}
+
+// ReconcileCounts recomputes the denormalized player, planet and spatial counts from their
+// source tables and returns how many games had drifted
+func (r *Repository) ReconcileCounts(ctx context.Context) (int, error) {
+	query := `
+		WITH actual AS (
+			SELECT
+				g.id,
+				(SELECT COUNT(*) FROM game_players gp WHERE gp.game_id = g.id) as player_count,
+				(SELECT COUNT(*) FROM planets p JOIN spatial_entities s ON s.id = p.system_id WHERE s.game_id = g.id) as planet_count,
+				(SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'galaxy') as galaxy_count,
+				(SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'sector') as sector_count,
+				(SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'system') as system_count
+			FROM games g
+		)
+		UPDATE games g
+		SET player_count = actual.player_count, planet_count = actual.planet_count,
+			galaxy_count = actual.galaxy_count, sector_count = actual.sector_count, system_count = actual.system_count
+		FROM actual
+		WHERE g.id = actual.id
+			AND (g.player_count != actual.player_count OR g.planet_count != actual.planet_count
+				OR g.galaxy_count != actual.galaxy_count OR g.sector_count != actual.sector_count
+				OR g.system_count != actual.system_count)`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, errors.WrapInternal("failed to reconcile game counts", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapInternal("failed to get rows affected after reconciling counts", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/query"
+)
+
+func TestGetAllGamesOrdersSameTimestampByID(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+
+	// NOW() is fixed for the whole transaction, so all three rows share created_at
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, name := range []string{"same_ts_a", "same_ts_b", "same_ts_c"} {
+		var id int
+		if err := tx.QueryRow("INSERT INTO games (name, seed) VALUES ($1, 'seed') RETURNING id", name).Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	for run := 0; run < 3; run++ {
+		games, err := repo.GetAllGames(context.Background(), query.ListParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(games) != 3 {
+			t.Fatalf("got %d games, want 3", len(games))
+		}
+		for i, game := range games {
+			if want := ids[len(ids)-1-i]; game.ID != want {
+				t.Fatalf("run %d: position %d has game %d, want %d (id DESC)", run, i, game.ID, want)
+			}
+		}
+	}
+}
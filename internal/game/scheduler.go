@@ -0,0 +1,50 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Scheduler periodically activates scheduled games once their start time arrives and
+// their lobby is full enough, and cancels lobbies that stayed short of players too long.
+// A zero interval disables it.
+type Scheduler struct {
+	tickerLoop
+	service *Service
+}
+
+func NewScheduler(service *Service, interval time.Duration) *Scheduler {
+	s := &Scheduler{service: service}
+	s.tickerLoop = newTickerLoop("game_scheduler", interval, s.tick)
+	return s
+}
+
+func (s *Scheduler) tick(ctx context.Context, logger *slog.Logger) {
+	s.activateDueGames(ctx, logger)
+	s.cancelExpiredLobbies(ctx, logger)
+}
+
+func (s *Scheduler) activateDueGames(ctx context.Context, logger *slog.Logger) {
+	gameIDs, err := s.service.ActivateScheduledGames(ctx)
+
+	for _, gameID := range gameIDs {
+		logger.Info("Scheduled game activated", "game_id", gameID)
+	}
+
+	if err != nil {
+		logger.Error("Failed to activate scheduled games", "error", err)
+	}
+}
+
+func (s *Scheduler) cancelExpiredLobbies(ctx context.Context, logger *slog.Logger) {
+	gameIDs, err := s.service.CancelExpiredLobbies(ctx)
+	if err != nil {
+		logger.Error("Failed to cancel expired lobbies", "error", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		logger.Info("Scheduled game cancelled for lack of players", "game_id", gameID)
+	}
+}
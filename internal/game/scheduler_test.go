@@ -0,0 +1,95 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSchedulerStopWaitsForTheLoopToExit(t *testing.T) {
+	scheduler := NewScheduler(nil, time.Hour)
+	scheduler.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not return")
+	}
+
+	select {
+	case <-scheduler.done:
+	default:
+		t.Fatal("Stop() returned before the scheduler loop exited")
+	}
+}
+
+func TestSchedulerClose(t *testing.T) {
+	scheduler := NewScheduler(nil, time.Hour)
+	scheduler.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := scheduler.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestSchedulerDisabledOrNeverStarted(t *testing.T) {
+	// A scheduler that was never started has nothing to stop
+	NewScheduler(nil, time.Hour).Stop()
+	if err := NewScheduler(nil, time.Hour).Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// A zero interval exits right away, and stopping it afterwards is still safe
+	scheduler := NewScheduler(nil, 0)
+	scheduler.Start()
+	<-scheduler.done
+	scheduler.Stop()
+}
+
+func TestTickerLoopTicksUntilStopped(t *testing.T) {
+	ticks := make(chan struct{}, 10)
+	loop := newTickerLoop("test", time.Millisecond, func(ctx context.Context, logger *slog.Logger) {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	})
+	loop.Start()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(time.Second):
+			t.Fatalf("tick %d never came", i+1)
+		}
+	}
+
+	loop.Stop()
+	for len(ticks) > 0 {
+		<-ticks
+	}
+	time.Sleep(10 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Fatal("loop kept ticking after Stop()")
+	}
+}
+
+func TestTurnSchedulerSharesTheLifecycle(t *testing.T) {
+	scheduler := NewTurnScheduler(nil, time.Hour)
+	scheduler.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := scheduler.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
@@ -4,37 +4,108 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	stderrors "errors"
+	"fmt"
 	"hash/fnv"
 	mathrand "math/rand"
+	"time"
 
+	"planets-server/internal/building"
 	"planets-server/internal/planet"
+	"planets-server/internal/research"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/metrics"
+	"planets-server/internal/shared/query"
+	"planets-server/internal/shared/validate"
 	"planets-server/internal/spatial"
 )
 
+// publicLeaderboardSize is how many players the public stats endpoint ranks
+const publicLeaderboardSize = 3
+
+// Bounds for a game's turn interval, from hourly turns to one turn a week
+const (
+	minTurnIntervalHours = 1
+	maxTurnIntervalHours = 168
+)
+
+// Upper bounds on universe generation, keeping a single game within what one transaction can build
+const (
+	maxGalaxyCount      = 64
+	maxSectorsPerGalaxy = 256
+	maxSystemsPerSector = 256
+	maxPlanetsPerSystem = 32
+)
+
 type Service struct {
-	gameRepo       *Repository
-	spatialService *spatial.Service
-	planetService  *planet.Service
+	gameRepo        *Repository
+	spatialService  *spatial.Service
+	planetService   *planet.Service
+	buildingService *building.Service
+	researchService *research.Service
+	// lobbyGracePeriod is how long past start_at a scheduled game may wait for players; zero waits forever
+	lobbyGracePeriod time.Duration
+	// maxGenerationTime caps how long universe generation may run before it is aborted; zero means no limit
+	maxGenerationTime time.Duration
 }
 
 func NewService(
 	gameRepo *Repository,
 	spatialService *spatial.Service,
 	planetService *planet.Service,
+	buildingService *building.Service,
+	researchService *research.Service,
 ) *Service {
+	var lobbyGracePeriod, maxGenerationTime time.Duration
+	if cfg := config.GlobalConfig; cfg != nil {
+		lobbyGracePeriod = cfg.Game.LobbyGracePeriod
+		maxGenerationTime = cfg.Game.MaxGenerationTime
+	}
+
 	return &Service{
-		gameRepo:       gameRepo,
-		spatialService: spatialService,
-		planetService:  planetService,
+		gameRepo:          gameRepo,
+		spatialService:    spatialService,
+		planetService:     planetService,
+		buildingService:   buildingService,
+		researchService:   researchService,
+		lobbyGracePeriod:  lobbyGracePeriod,
+		maxGenerationTime: maxGenerationTime,
 	}
 }
 
-func (s *Service) CreateGame(ctx context.Context, config GameConfig) (*Game, error) {
+// CreateGame generates a new game and makes creatorID its game master
+func (s *Service) CreateGame(ctx context.Context, config GameConfig, creatorID int) (*Game, error) {
+	if config.Seed != "" {
+		if err := validate.InRange("seed length", len(config.Seed), 3, 32); err != nil {
+			return nil, err
+		}
+	}
+
+	err := validate.First(
+		validate.InRange("galaxy_count", config.GalaxyCount, 1, maxGalaxyCount),
+		validateGenerationConfig(config),
+		validate.Positive("max_players", config.MaxPlayers),
+		validate.InRange("min_players", config.MinPlayers, 0, config.MaxPlayers),
+		validate.InRange("turn_interval_hours", config.TurnIntervalHours, minTurnIntervalHours, maxTurnIntervalHours),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.StartAt != nil && !config.StartAt.After(time.Now()) {
+		return nil, errors.Validation("start_at must be in the future")
+	}
+
+	if err := config.Settings.Validate(); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.gameRepo.db.BeginTx(ctx)
 	if err != nil {
-		return nil, errors.WrapInternal("failed to begin transaction for game creation", err)
+		return nil, database.ClassifyError("failed to begin transaction for game creation", err)
 	}
 
 	defer func() {
@@ -50,34 +121,50 @@ func (s *Service) CreateGame(ctx context.Context, config GameConfig) (*Game, err
 
 	seed := config.Seed
 	if seed == "" {
-		var err error
 		seed, err = generateSeed()
 		if err != nil {
 			return nil, errors.WrapInternal("failed to generate seed", err)
 		}
-	} else if len(seed) < 3 || len(seed) > 32 {
-		return nil, errors.Validation("seed must be between 3 and 32 characters")
+	}
+
+	// A player minimum only makes sense for a lobby, so open one that can start as soon as it fills
+	if config.MinPlayers > 0 && config.StartAt == nil {
+		now := time.Now()
+		config.StartAt = &now
 	}
 
 	seedInt := hashSeed(seed)
 
-	game, err := s.gameRepo.CreateGame(ctx, name, seed, config, tx)
+	game, err := s.gameRepo.CreateGame(ctx, name, seed, config, creatorID, tx)
 	if err != nil {
-		return nil, errors.WrapInternal("failed to create game", err)
+		return nil, err
 	}
 
 	rng := mathrand.New(mathrand.NewSource(seedInt))
 
-	err = s.generateUniverse(ctx, game.ID, config, rng, tx)
+	generationCtx, cancelGeneration := s.generationContext(ctx)
+	defer cancelGeneration()
+
+	// The deadline reaches every batch insert, so a timed out generation stops mid-query
+	// and the deferred rollback discards whatever was already built
+	err = s.generateUniverse(generationCtx, game.ID, config, rng, tx)
 	if err != nil {
+		if generationCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return nil, errors.External("universe generation timed out")
+		}
 		return nil, errors.WrapInternal("failed to generate universe", err)
 	}
 
-	if err := s.gameRepo.ActivateGame(ctx, game.ID, tx); err != nil {
+	// Assign the outer err so the deferred rollback sees these failures
+	if config.StartAt != nil {
+		if err = s.gameRepo.ScheduleGame(ctx, game.ID, tx); err != nil {
+			return nil, errors.WrapInternal("failed to schedule game", err)
+		}
+	} else if err = s.gameRepo.ActivateGame(ctx, game.ID, tx); err != nil {
 		return nil, errors.WrapInternal("failed to activate game", err)
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err = tx.Commit(); err != nil {
 		return nil, errors.WrapInternal("failed to commit game creation transaction", err)
 	}
 
@@ -89,12 +176,534 @@ func (s *Service) CreateGame(ctx context.Context, config GameConfig) (*Game, err
 	return updatedGame, nil
 }
 
-func (s *Service) GetAllGames(ctx context.Context) ([]Game, error) {
-	return s.gameRepo.GetAllGames(ctx)
+// ActivateScheduledGames starts every scheduled game whose start time has been reached
+// and whose lobby has enough players, and gives each of its players a home planet. Each game
+// is started in its own transaction with its row locked, so a player joining at the same
+// moment either makes the starting roster or joins the running game afterwards. A game that
+// fails to start stays scheduled and is retried on the next call; the failures are returned
+// together once every other due game has been started.
+func (s *Service) ActivateScheduledGames(ctx context.Context) ([]int, error) {
+	now := time.Now()
+	var attempted []int
+	var activated []int
+	var failures []error
+
+	for ctx.Err() == nil {
+		gameID, started, err := s.activateNextLobby(ctx, now, attempted)
+		if err != nil {
+			// No game was locked, so there is nothing to skip and the next attempt would fail the same way
+			if gameID == 0 {
+				failures = append(failures, err)
+				break
+			}
+
+			logger.FromContext(ctx).Error("Failed to activate scheduled game, skipping it until the next run",
+				"component", "game_scheduler",
+				"game_id", gameID,
+				"error", err,
+			)
+			attempted = append(attempted, gameID)
+			failures = append(failures, fmt.Errorf("game %d: %w", gameID, err))
+			continue
+		}
+		if !started {
+			break
+		}
+
+		attempted = append(attempted, gameID)
+		activated = append(activated, gameID)
+	}
+
+	return activated, stderrors.Join(failures...)
+}
+
+// activateNextLobby starts the next due lobby not in skip and claims a home planet for each
+// of its players. It returns the ID of the game it locked, if any, alongside any error so the
+// caller can skip that game.
+func (s *Service) activateNextLobby(ctx context.Context, now time.Time, skip []int) (gameID int, started bool, err error) {
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return 0, false, database.ClassifyError("failed to begin transaction for game activation", err)
+	}
+
+	defer func() {
+		if err != nil || !started {
+			_ = tx.Rollback()
+		}
+	}()
+
+	locked, err := s.gameRepo.LockDueLobby(ctx, now, skip, tx)
+	if err != nil || locked == nil {
+		return 0, false, err
+	}
+	gameID = *locked
+
+	if err = s.gameRepo.StartLobby(ctx, gameID, now, tx); err != nil {
+		return gameID, false, err
+	}
+
+	playerIDs, err := s.gameRepo.GetPlayerIDs(ctx, gameID, tx)
+	if err != nil {
+		return gameID, false, err
+	}
+
+	if _, err = s.assignFairStartingPositions(ctx, gameID, playerIDs, tx); err != nil {
+		return gameID, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return gameID, false, errors.WrapInternal("failed to commit game activation", err)
+	}
+
+	return gameID, true, nil
+}
+
+// JoinGame adds the player to the game while it has room, and hands them a home planet
+// when the game is already running. Games still being generated or already over cannot be
+// joined. The game row stays locked until commit, so two players racing for the last seat
+// or the same home planet cannot both get it.
+func (s *Service) JoinGame(ctx context.Context, gameID, playerID int) (result *JoinResult, err error) {
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for joining game", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	status, maxPlayers, err := s.gameRepo.LockGameForJoin(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case GameStatusCreating, GameStatusCompleted, GameStatusCancelled:
+		return nil, errors.Validationf("a %s game cannot be joined", status)
+	}
+
+	playerCount, err := s.gameRepo.CountPlayers(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if playerCount >= maxPlayers {
+		return nil, errors.WithCode(errors.Conflictf("game is full (max players: %d)", maxPlayers), errors.CodeGameFull)
+	}
+
+	if err = s.gameRepo.AddPlayer(ctx, gameID, playerID, tx); err != nil {
+		return nil, err
+	}
+
+	result = &JoinResult{GameID: gameID, PlayerID: playerID}
+
+	if status == GameStatusActive {
+		result.HomePlanet, err = s.AssignHomePlanet(ctx, gameID, playerID, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit game join", err)
+	}
+
+	return result, nil
+}
+
+// AssignHomePlanet gives the player an unowned terrestrial planet away from the other players
+// as their starting planet. It fails with a conflict when the game has no such planet left.
+func (s *Service) AssignHomePlanet(ctx context.Context, gameID, playerID int, tx *database.Tx) (*planet.Planet, error) {
+	home, err := s.planetService.ClaimHomePlanet(ctx, gameID, playerID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if home == nil {
+		return nil, errors.WithCode(errors.Conflictf("no planet left to start on in game %d", gameID), errors.CodeNoHomePlanet)
+	}
+
+	return home, nil
+}
+
+// assignFairStartingPositions hands the starting roster of a game its home planets in one
+// pass, in join order. Each pick is made against the homes already handed out earlier in the
+// pass, so with the spread strategy every player starts as far as the map allows from the
+// players placed before them. All picks share the caller's transaction and skip planets
+// another transaction has locked, so a concurrent join cannot be given the same planet.
+func (s *Service) assignFairStartingPositions(ctx context.Context, gameID int, playerIDs []int, tx *database.Tx) ([]*planet.Planet, error) {
+	homes := make([]*planet.Planet, 0, len(playerIDs))
+
+	for _, playerID := range playerIDs {
+		home, err := s.AssignHomePlanet(ctx, gameID, playerID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("player %d: %w", playerID, err)
+		}
+		homes = append(homes, home)
+	}
+
+	return homes, nil
+}
+
+// ProcessDueTurns advances every active game whose next turn is due by one turn and returns
+// the turns processed. Each game is advanced in its own transaction with its row locked, so
+// server instances running the turn scheduler side by side never process the same turn twice.
+// A game that fell several turns behind catches up one turn per call. A game whose turn fails
+// is skipped until the next call so it can't hold up the others; the failures are returned
+// together once every other due game has been processed.
+func (s *Service) ProcessDueTurns(ctx context.Context) ([]TurnAdvance, error) {
+	now := time.Now()
+	var processed []int
+	var advances []TurnAdvance
+	var failures []error
+
+	for ctx.Err() == nil {
+		start := time.Now()
+		gameID, advance, err := s.processNextTurn(ctx, now, processed)
+		if err != nil {
+			// No game was locked, so there is nothing to skip and the next attempt would fail the same way
+			if gameID == 0 {
+				failures = append(failures, err)
+				break
+			}
+
+			logger.FromContext(ctx).Error("Failed to process turn, skipping game until the next run",
+				"component", "turn_processor",
+				"game_id", gameID,
+				"error", err,
+			)
+			processed = append(processed, gameID)
+			failures = append(failures, fmt.Errorf("game %d: %w", gameID, err))
+			continue
+		}
+		if advance == nil {
+			break
+		}
+		metrics.ObserveSince(metrics.TurnDuration, start)
+		metrics.TurnsProcessed.Inc()
+
+		processed = append(processed, advance.GameID)
+		advances = append(advances, *advance)
+	}
+
+	return advances, stderrors.Join(failures...)
+}
+
+// processNextTurn advances the next due game not in skip. It returns the ID of the game it
+// locked, if any, alongside any error so the caller can skip that game.
+func (s *Service) processNextTurn(ctx context.Context, now time.Time, skip []int) (gameID int, advance *TurnAdvance, err error) {
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return 0, nil, database.ClassifyError("failed to begin transaction for turn processing", err)
+	}
+
+	defer func() {
+		if err != nil || advance == nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	locked, err := s.gameRepo.LockDueTurn(ctx, now, skip, tx)
+	if err != nil || locked == nil {
+		return 0, nil, err
+	}
+	gameID = *locked
+
+	advance, err = s.gameRepo.AdvanceTurn(ctx, gameID, tx)
+	if err != nil {
+		return gameID, nil, err
+	}
+
+	claimed, err := s.gameRepo.ClaimTurn(ctx, gameID, advance.Turn, tx)
+	if err != nil {
+		return gameID, nil, err
+	}
+
+	// The ledger already has this turn, so its effects were applied even though the game's
+	// turn counter was not advanced. Move the schedule on without applying them again.
+	if !claimed {
+		logger.FromContext(ctx).Warn("Turn already in the turn log, skipping its effects",
+			"component", "turn_processor",
+			"game_id", gameID,
+			"turn", advance.Turn,
+		)
+	} else {
+		var summary TurnSummary
+
+		if summary.BuildingGrowthPlanets, err = s.buildingService.ApplyTurnEffects(ctx, gameID, tx); err != nil {
+			return gameID, nil, err
+		}
+
+		var researched *research.TurnResult
+		if researched, err = s.researchService.ApplyTurn(ctx, gameID, tx); err != nil {
+			return gameID, nil, err
+		}
+		summary.ResearchCompleted = researched.Completed
+		summary.ResearchGrowthPlanets = researched.PlanetsGrown
+
+		if summary.DecayedPlanets, err = s.planetService.ApplyDecay(ctx, gameID, tx); err != nil {
+			return gameID, nil, err
+		}
+
+		if err = s.gameRepo.SaveTurnSummary(ctx, gameID, advance.Turn, summary, tx); err != nil {
+			return gameID, nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return gameID, nil, errors.WrapInternal("failed to commit turn processing", err)
+	}
+
+	return gameID, advance, nil
+}
+
+// CancelExpiredLobbies cancels scheduled games still short of players once the grace period
+// after their start time has passed. It does nothing when no grace period is configured.
+func (s *Service) CancelExpiredLobbies(ctx context.Context) ([]int, error) {
+	if s.lobbyGracePeriod <= 0 {
+		return nil, nil
+	}
+	return s.gameRepo.CancelExpiredLobbies(ctx, time.Now().Add(-s.lobbyGracePeriod))
+}
+
+// UpdateSettings applies a partial settings change to an existing game. Generation parameters
+// are rejected since the universe already exists, and a new turn interval moves the next turn
+// so it stays one interval after the previous one.
+func (s *Service) UpdateSettings(ctx context.Context, gameID int, update SettingsUpdate) (*Game, error) {
+	if field := update.immutableField(); field != "" {
+		return nil, errors.WithCode(errors.Conflictf("%s cannot be changed after the universe is generated", field), errors.CodeSettingImmutable)
+	}
+
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for settings update", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	game, err := s.gameRepo.GetGameByIDForUpdate(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Counted under the game row lock, which JoinGame also takes, so no join can slip in
+	// between this check and the commit
+	playerCount, err := s.gameRepo.CountPlayers(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = applySettingsUpdate(game, update, playerCount, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err = s.gameRepo.UpdateMutableSettings(ctx, game, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit settings update", err)
+	}
+
+	return s.gameRepo.GetGameByID(ctx, gameID)
+}
+
+// TransferGameMaster hands game master control to another member of the game. Only the
+// current game master or a global admin may do so.
+func (s *Service) TransferGameMaster(ctx context.Context, gameID, actorID int, actorIsAdmin bool, newGMID int) (game *Game, err error) {
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for game master transfer", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	game, err = s.gameRepo.GetGameByIDForUpdate(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actorIsAdmin && !game.IsGameMaster(actorID) {
+		return nil, errors.WithCode(errors.Forbidden("only the game master or an admin can transfer the game master role"), errors.CodeNotGameMaster)
+	}
+
+	if game.Status == GameStatusCompleted || game.Status == GameStatusCancelled {
+		return nil, errors.WithCode(errors.Conflictf("game master of a %s game cannot be changed", game.Status), errors.CodeGameFinished)
+	}
+
+	member, err := s.gameRepo.IsPlayerInGame(ctx, gameID, newGMID, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, errors.WithCode(errors.Validationf("player %d has not joined game %d", newGMID, gameID), errors.CodePlayerNotInGame)
+	}
+
+	if err = s.gameRepo.SetGameMaster(ctx, gameID, newGMID, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit game master transfer", err)
+	}
+
+	logger.FromContext(ctx).Info("Game master transferred",
+		"game_id", gameID,
+		"old_gm_player_id", game.GMPlayerID,
+		"new_gm_player_id", newGMID,
+		"actor_player_id", actorID,
+		"actor_is_admin", actorIsAdmin)
+
+	return s.gameRepo.GetGameByID(ctx, gameID)
+}
+
+// applySettingsUpdate validates update against the game and the players already in it,
+// and applies it to game
+func applySettingsUpdate(game *Game, update SettingsUpdate, playerCount int, now time.Time) error {
+	if game.Status == GameStatusCompleted || game.Status == GameStatusCancelled {
+		return errors.WithCode(errors.Conflictf("settings of a %s game cannot be changed", game.Status), errors.CodeGameFinished)
+	}
+
+	if update.TurnIntervalHours != nil {
+		hours := *update.TurnIntervalHours
+		if err := validate.InRange("turn_interval_hours", hours, minTurnIntervalHours, maxTurnIntervalHours); err != nil {
+			return err
+		}
+
+		if game.NextTurnAt != nil {
+			previousTurn := game.NextTurnAt.Add(-time.Duration(game.TurnIntervalHours) * time.Hour)
+			nextTurnAt := previousTurn.Add(time.Duration(hours) * time.Hour)
+			if nextTurnAt.Before(now) {
+				nextTurnAt = now
+			}
+			game.NextTurnAt = &nextTurnAt
+		}
+		game.TurnIntervalHours = hours
+	}
+
+	if update.MaxPlayers != nil {
+		if err := validate.Positive("max_players", *update.MaxPlayers); err != nil {
+			return err
+		}
+		if *update.MaxPlayers < playerCount {
+			return errors.WithCode(
+				errors.Conflictf("max_players cannot be lower than the %d players already in the game", playerCount),
+				errors.CodeTooManyPlayers,
+			)
+		}
+		game.MaxPlayers = *update.MaxPlayers
+	}
+
+	if update.MinPlayers != nil {
+		if game.Status != GameStatusScheduled {
+			return errors.WithCode(errors.Conflictf("min_players can only be changed before the game starts"), errors.CodeGameAlreadyStarted)
+		}
+		game.MinPlayers = *update.MinPlayers
+	}
+
+	if err := validate.InRange("min_players", game.MinPlayers, 0, game.MaxPlayers); err != nil {
+		return err
+	}
+
+	if update.PlanetTypeWeights != nil {
+		weights := make(map[planet.PlanetType]int, len(game.Settings.PlanetTypeWeights)+len(update.PlanetTypeWeights))
+		for planetType, weight := range game.Settings.PlanetTypeWeights {
+			weights[planetType] = weight
+		}
+		for planetType, weight := range update.PlanetTypeWeights {
+			weights[planetType] = weight
+		}
+		game.Settings.PlanetTypeWeights = weights
+	}
+
+	if update.FogOfWar != nil {
+		game.Settings.FogOfWar = *update.FogOfWar
+	}
+
+	return game.Settings.Validate()
+}
+
+func (s *Service) GetAllGames(ctx context.Context, params query.ListParams) ([]Game, error) {
+	return s.gameRepo.GetAllGames(ctx, params)
 }
 
 func (s *Service) GetGameStats(ctx context.Context, gameID int) (*GameStats, error) {
-	return s.gameRepo.GetGameStats(ctx, gameID)
+	stats, err := s.gameRepo.GetGameStats(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Status == GameStatusScheduled && stats.startAt != nil {
+		stats.Lobby = lobbyState(*stats.startAt, stats.minPlayers, stats.PlayerCount, s.lobbyGracePeriod, time.Now())
+	}
+
+	return stats, nil
+}
+
+func lobbyState(startAt time.Time, minPlayers, playerCount int, gracePeriod time.Duration, now time.Time) *LobbyState {
+	lobby := &LobbyState{
+		StartAt:           startAt,
+		SecondsUntilStart: max(int64(startAt.Sub(now).Seconds()), 0),
+		MinPlayers:        minPlayers,
+		PlayersNeeded:     max(minPlayers-playerCount, 0),
+	}
+
+	if gracePeriod > 0 && lobby.PlayersNeeded > 0 {
+		cancelsAt := startAt.Add(gracePeriod)
+		lobby.CancelsAt = &cancelsAt
+	}
+
+	return lobby
+}
+
+func (s *Service) GetPublicGameStats(ctx context.Context, gameID int) (*PublicGameStats, error) {
+	return s.gameRepo.GetPublicGameStats(ctx, gameID, publicLeaderboardSize)
+}
+
+func (s *Service) GetTurnTimer(ctx context.Context, gameID int) (*TurnTimer, error) {
+	game, err := s.gameRepo.GetGameByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := &TurnTimer{
+		CurrentTurn:       game.CurrentTurn,
+		NextTurnAt:        game.NextTurnAt,
+		TurnIntervalHours: game.TurnIntervalHours,
+	}
+
+	if game.NextTurnAt != nil {
+		timer.SecondsRemaining = max(int64(time.Until(*game.NextTurnAt).Seconds()), 0)
+	}
+
+	return timer, nil
+}
+
+// GetOverview summarizes the games on the server for the admin dashboard
+func (s *Service) GetOverview(ctx context.Context) (*Overview, error) {
+	return s.gameRepo.GetOverview(ctx)
+}
+
+// GetTurnLog returns what processing a past turn of the game changed
+func (s *Service) GetTurnLog(ctx context.Context, gameID, turn int) (*TurnLogEntry, error) {
+	if err := validate.Positive("turn", turn); err != nil {
+		return nil, err
+	}
+	return s.gameRepo.GetTurnLog(ctx, gameID, turn)
+}
+
+func (s *Service) ReconcileCounts(ctx context.Context) (int, error) {
+	return s.gameRepo.ReconcileCounts(ctx)
 }
 
 func (s *Service) DeleteGame(ctx context.Context, gameID int) error {
@@ -123,6 +732,14 @@ func hashSeed(seed string) int64 {
 	return int64(h.Sum64())
 }
 
+// generationContext bounds universe generation by maxGenerationTime, when one is set
+func (s *Service) generationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.maxGenerationTime <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.maxGenerationTime)
+}
+
 func (s *Service) generateUniverse(ctx context.Context, gameID int, config GameConfig, rng *mathrand.Rand, tx *database.Tx) error {
 	// Create the universe entity (level 0, root of spatial hierarchy)
 	universeIDs, err := s.spatialService.GenerateEntities(
@@ -144,12 +761,50 @@ func (s *Service) generateUniverse(ctx context.Context, gameID int, config GameC
 	}
 
 	// Generate spatial hierarchy: galaxies → sectors → systems
-	plan := config.BuildGenerationPlan()
-	currentLevelIDs := universeIDs
+	levelIDs, err := s.generateLevels(ctx, gameID, universeIDs, config.BuildGenerationPlan(), tx)
+	if err != nil {
+		return err
+	}
+
+	// Final level IDs are system IDs for planet generation
+	systemIDs := levelIDs[len(levelIDs)-1]
+
+	var counter generationCounter
+	counter.add(GameCounts{Galaxies: len(levelIDs[0]), Sectors: len(levelIDs[1]), Systems: len(systemIDs)})
+
+	totalPlanets, err := s.planetService.GeneratePlanets(
+		ctx,
+		systemIDs,
+		config.MinPlanetsPerSystem,
+		config.MaxPlanetsPerSystem,
+		config.Settings.TypeWeights(),
+		spawnSystems(systemIDs, config.SystemsPerSector, config.SpawnSystemsPerSector),
+		rng,
+		tx,
+	)
+	if err != nil {
+		return errors.WrapInternal("failed to generate planets", err)
+	}
+
+	counter.add(GameCounts{Planets: totalPlanets})
+
+	err = s.gameRepo.UpdateGameCounts(ctx, gameID, counter.counts(), tx)
+	if err != nil {
+		return errors.WrapInternal("failed to update game counts", err)
+	}
+
+	return nil
+}
+
+// generateLevels creates each level of the plan beneath the given root entities and
+// returns the IDs created at every level, in plan order
+func (s *Service) generateLevels(ctx context.Context, gameID int, rootIDs []int, plan []SpatialLevel, tx *database.Tx) ([][]int, error) {
+	currentLevelIDs := rootIDs
+	levelIDs := make([][]int, 0, len(plan))
 
 	for _, level := range plan {
 		if err := ctx.Err(); err != nil {
-			return errors.WrapInternal("universe generation cancelled", err)
+			return nil, errors.WrapInternal("universe generation cancelled", err)
 		}
 
 		parentIDs := make([]*int, len(currentLevelIDs))
@@ -158,6 +813,7 @@ func (s *Service) generateUniverse(ctx context.Context, gameID int, config GameC
 			parentIDs[j] = &idCopy
 		}
 
+		var err error
 		currentLevelIDs, err = s.spatialService.GenerateEntities(
 			ctx,
 			gameID,
@@ -167,28 +823,144 @@ func (s *Service) generateUniverse(ctx context.Context, gameID int, config GameC
 			tx,
 		)
 		if err != nil {
-			return errors.WrapInternal("failed to generate spatial entities", err)
+			return nil, errors.WrapInternal("failed to generate spatial entities", err)
 		}
+
+		levelIDs = append(levelIDs, currentLevelIDs)
 	}
 
-	// Final level IDs are system IDs for planet generation
-	systemIDs := currentLevelIDs
+	return levelIDs, nil
+}
 
-	totalPlanets, err := s.planetService.GeneratePlanets(
+// AddGalaxy grows an existing game by one galaxy, generated with the same pipeline
+// as the original universe. The galaxy is placed in the next free cell of the universe.
+// Only scheduled and active games can be expanded, and the game row stays locked until
+// commit so it cannot change state or be deleted while the galaxy is built.
+func (s *Service) AddGalaxy(ctx context.Context, gameID int, config GameConfig) (summary *GalaxySummary, err error) {
+	if err := validateGenerationConfig(config); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.gameRepo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for galaxy creation", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	game, err := s.gameRepo.GetGameByIDForUpdate(ctx, gameID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch game.Status {
+	case GameStatusScheduled, GameStatusActive:
+	case GameStatusCompleted, GameStatusCancelled:
+		return nil, errors.WithCode(errors.Conflictf("game %d is %s and cannot be expanded", gameID, game.Status), errors.CodeGameFinished)
+	default:
+		return nil, errors.Conflictf("a %s game cannot be expanded", game.Status)
+	}
+
+	if game.UniverseID == nil {
+		return nil, errors.Conflictf("game %d has no universe to expand", gameID)
+	}
+
+	// Read after the lock, so a concurrent expansion's galaxy is already counted
+	universe, err := s.spatialService.GetByID(ctx, *game.UniverseID)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to load universe", err)
+	}
+
+	// Derive the RNG from the game seed and galaxy position so expansions are reproducible
+	rng := mathrand.New(mathrand.NewSource(hashSeed(fmt.Sprintf("%s/galaxy-%d", game.Seed, universe.ChildCount))))
+
+	plan := GameConfig{
+		GalaxyCount:      1,
+		SectorsPerGalaxy: config.SectorsPerGalaxy,
+		SystemsPerSector: config.SystemsPerSector,
+	}.BuildGenerationPlan()
+
+	levelIDs, err := s.generateLevels(ctx, gameID, []int{universe.ID}, plan, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	systemIDs := levelIDs[len(levelIDs)-1]
+
+	// The planet insert trigger keeps games.planet_count current
+	planetCount, err := s.planetService.GeneratePlanets(
 		ctx,
 		systemIDs,
 		config.MinPlanetsPerSystem,
 		config.MaxPlanetsPerSystem,
+		game.Settings.TypeWeights(),
+		spawnSystems(systemIDs, config.SystemsPerSector, config.SpawnSystemsPerSector),
 		rng,
 		tx,
 	)
 	if err != nil {
-		return errors.WrapInternal("failed to generate planets", err)
+		return nil, errors.WrapInternal("failed to generate planets", err)
 	}
 
-	err = s.gameRepo.UpdateGameCounts(ctx, gameID, totalPlanets, tx)
+	err = s.gameRepo.AddSpatialCounts(ctx, gameID, GameCounts{
+		Galaxies: 1,
+		Sectors:  len(levelIDs[1]),
+		Systems:  len(systemIDs),
+	}, tx)
 	if err != nil {
-		return errors.WrapInternal("failed to update game counts", err)
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit galaxy creation transaction", err)
+	}
+
+	return &GalaxySummary{
+		GalaxyID:    levelIDs[0][0],
+		SectorCount: len(levelIDs[1]),
+		SystemCount: len(systemIDs),
+		PlanetCount: planetCount,
+	}, nil
+}
+
+// spawnSystems designates the first spawnPerSector systems of every sector as starting
+// locations. systemIDs are grouped by sector in generation order, systemsPerSector at a time.
+func spawnSystems(systemIDs []int, systemsPerSector, spawnPerSector int) map[int]bool {
+	spawns := make(map[int]bool)
+	if systemsPerSector <= 0 || spawnPerSector <= 0 {
+		return spawns
+	}
+
+	for i, systemID := range systemIDs {
+		if i%systemsPerSector < spawnPerSector {
+			spawns[systemID] = true
+		}
+	}
+
+	return spawns
+}
+
+// validateGenerationConfig checks the parameters shared by universe creation and galaxy expansion.
+// Systems may be empty, but a config that could never produce a single planet is rejected.
+func validateGenerationConfig(config GameConfig) error {
+	err := validate.First(
+		validate.InRange("sectors_per_galaxy", config.SectorsPerGalaxy, 1, maxSectorsPerGalaxy),
+		validate.InRange("systems_per_sector", config.SystemsPerSector, 1, maxSystemsPerSector),
+		validate.InRange("min_planets_per_system", config.MinPlanetsPerSystem, 0, maxPlanetsPerSystem),
+		validate.InRange("max_planets_per_system", config.MaxPlanetsPerSystem, config.MinPlanetsPerSystem, maxPlanetsPerSystem),
+		validate.InRange("spawn_systems_per_sector", config.SpawnSystemsPerSector, 0, config.SystemsPerSector),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Spawn systems always get a planet, so only a config with neither can come out empty
+	if config.MaxPlanetsPerSystem == 0 && config.SpawnSystemsPerSector == 0 {
+		return errors.Validation("max_planets_per_system and spawn_systems_per_sector cannot both be 0: the universe would have no planets")
 	}
 
 	return nil
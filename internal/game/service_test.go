@@ -0,0 +1,706 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"planets-server/internal/building"
+	"planets-server/internal/events"
+	"planets-server/internal/planet"
+	"planets-server/internal/research"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/spatial"
+	"planets-server/internal/visibility"
+)
+
+func newTestService(t *testing.T) (*Service, *database.DB) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+
+	tree, err := research.LoadTree("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visibilityService := visibility.NewService(visibility.NewRepository(db))
+	service := NewService(
+		NewRepository(db),
+		spatial.NewService(spatial.NewRepository(db), visibilityService),
+		planet.NewService(planet.NewRepository(db), events.NoopPublisher{}, visibilityService),
+		building.NewService(building.NewRepository(db)),
+		research.NewService(research.NewRepository(db), tree),
+	)
+
+	return service, db
+}
+
+// smallConfig is a universe of 1 galaxy, 2 sectors and 4 systems with a spawn system per sector
+func smallConfig() GameConfig {
+	return GameConfig{
+		MaxPlayers:            4,
+		TurnIntervalHours:     1,
+		GalaxyCount:           1,
+		SectorsPerGalaxy:      2,
+		SystemsPerSector:      2,
+		MinPlanetsPerSystem:   1,
+		MaxPlanetsPerSystem:   3,
+		SpawnSystemsPerSector: 1,
+	}
+}
+
+func createTestGame(t *testing.T, service *Service, db *database.DB, config GameConfig) *Game {
+	t.Helper()
+
+	creatorID := dbtest.CreatePlayer(t, db)
+	game, err := service.CreateGame(context.Background(), config, creatorID)
+	if err != nil {
+		t.Fatalf("CreateGame() error = %v", err)
+	}
+	return game
+}
+
+func setStatus(t *testing.T, db *database.DB, gameID int, status GameStatus) {
+	t.Helper()
+
+	if _, err := db.Exec("UPDATE games SET status = $1 WHERE id = $2", status, gameID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddGalaxyExpandsAnActiveGame(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	game := createTestGame(t, service, db, smallConfig())
+
+	summary, err := service.AddGalaxy(ctx, game.ID, smallConfig())
+	if err != nil {
+		t.Fatalf("AddGalaxy() error = %v", err)
+	}
+	if summary.SectorCount != 2 || summary.SystemCount != 4 {
+		t.Fatalf("summary = %+v, want 2 sectors and 4 systems", summary)
+	}
+
+	updated, err := service.gameRepo.GetGameByID(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GalaxyCount != 2 || updated.SectorCount != 4 || updated.SystemCount != 8 {
+		t.Fatalf("counts = %d/%d/%d, want 2/4/8", updated.GalaxyCount, updated.SectorCount, updated.SystemCount)
+	}
+	if updated.PlanetCount != game.PlanetCount+summary.PlanetCount {
+		t.Fatalf("planet_count = %d, want %d", updated.PlanetCount, game.PlanetCount+summary.PlanetCount)
+	}
+}
+
+func TestAddGalaxyRejectsGamesNotScheduledOrActive(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	for _, status := range []GameStatus{GameStatusCreating, GameStatusPaused, GameStatusCompleted, GameStatusCancelled} {
+		game := createTestGame(t, service, db, smallConfig())
+		setStatus(t, db, game.ID, status)
+
+		_, err := service.AddGalaxy(ctx, game.ID, smallConfig())
+		if errors.GetType(err) != errors.ErrorTypeConflict {
+			t.Fatalf("%s game: AddGalaxy() error = %v, want a conflict", status, err)
+		}
+
+		updated, err := service.gameRepo.GetGameByID(ctx, game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.GalaxyCount != game.GalaxyCount {
+			t.Fatalf("%s game: galaxy_count changed to %d", status, updated.GalaxyCount)
+		}
+	}
+}
+
+func TestAddGalaxyMissingGame(t *testing.T) {
+	service, _ := newTestService(t)
+
+	_, err := service.AddGalaxy(context.Background(), 999999, smallConfig())
+	if errors.GetType(err) != errors.ErrorTypeNotFound {
+		t.Fatalf("AddGalaxy() error = %v, want not found", err)
+	}
+}
+
+func TestSpawnSystemsPicksTheFirstSystemsOfEverySector(t *testing.T) {
+	// Three sectors of four systems, generated sector by sector
+	systemIDs := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+	spawns := spawnSystems(systemIDs, 4, 2)
+
+	want := map[int]bool{1: true, 2: true, 5: true, 6: true, 9: true, 10: true}
+	if len(spawns) != len(want) {
+		t.Fatalf("got %d spawn systems, want %d: %v", len(spawns), len(want), spawns)
+	}
+	for id := range want {
+		if !spawns[id] {
+			t.Fatalf("system %d is not a spawn system: %v", id, spawns)
+		}
+	}
+
+	if len(spawnSystems(systemIDs, 4, 0)) != 0 {
+		t.Fatal("spawn_systems_per_sector = 0 still designated spawn systems")
+	}
+}
+
+func TestCreatedGameHasAHabitablePlanetInEverySpawnSystem(t *testing.T) {
+	service, db := newTestService(t)
+
+	config := smallConfig()
+	config.MinPlanetsPerSystem = 0
+	config.MaxPlanetsPerSystem = 0
+	config.Settings.PlanetTypeWeights = map[planet.PlanetType]int{planet.PlanetTypeTerrestrial: 0}
+	game := createTestGame(t, service, db, config)
+
+	// One spawn system per sector, each with exactly its guaranteed terrestrial planet
+	var planets, terrestrial int
+	err := db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE p.type = 'terrestrial')
+		FROM planets p
+		JOIN spatial_entities s ON s.id = p.system_id
+		WHERE s.game_id = $1`, game.ID).Scan(&planets, &terrestrial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planets != config.SectorsPerGalaxy || terrestrial != planets {
+		t.Fatalf("game has %d planets, %d terrestrial; want %d, all terrestrial", planets, terrestrial, config.SectorsPerGalaxy)
+	}
+}
+
+func joinPlayers(t *testing.T, service *Service, db *database.DB, gameID, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		if _, err := service.JoinGame(context.Background(), gameID, dbtest.CreatePlayer(t, db)); err != nil {
+			t.Fatalf("JoinGame() error = %v", err)
+		}
+	}
+}
+
+func gameStatus(t *testing.T, service *Service, gameID int) GameStatus {
+	t.Helper()
+
+	game, err := service.gameRepo.GetGameByID(context.Background(), gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game.Status
+}
+
+func TestScheduledGameWaitsForMinPlayers(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	config := smallConfig()
+	config.MinPlayers = 2
+	game := createTestGame(t, service, db, config)
+	if game.Status != GameStatusScheduled {
+		t.Fatalf("status = %s, want a scheduled lobby", game.Status)
+	}
+
+	joinPlayers(t, service, db, game.ID, 1)
+
+	// start_at has passed but the lobby is one player short
+	activated, err := service.ActivateScheduledGames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(activated, game.ID) || gameStatus(t, service, game.ID) != GameStatusScheduled {
+		t.Fatalf("game with 1 of 2 players was activated")
+	}
+
+	joinPlayers(t, service, db, game.ID, 1)
+
+	activated, err = service.ActivateScheduledGames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(activated, game.ID) || gameStatus(t, service, game.ID) != GameStatusActive {
+		t.Fatalf("game with 2 of 2 players was not activated (activated: %v)", activated)
+	}
+}
+
+func TestActivatedLobbyGivesEveryPlayerAHomePlanet(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	config := smallConfig()
+	config.MinPlayers = 2
+	game := createTestGame(t, service, db, config)
+
+	playerIDs := []int{dbtest.CreatePlayer(t, db), dbtest.CreatePlayer(t, db)}
+	for _, playerID := range playerIDs {
+		result, err := service.JoinGame(ctx, game.ID, playerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.HomePlanet != nil {
+			t.Fatalf("player %d got a home planet while the game was still a lobby", playerID)
+		}
+	}
+
+	activated, err := service.ActivateScheduledGames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(activated, game.ID) {
+		t.Fatalf("game was not activated (activated: %v)", activated)
+	}
+
+	for _, playerID := range playerIDs {
+		var owned int
+		err := db.QueryRow(`
+			SELECT COUNT(*)
+			FROM planets p
+			JOIN spatial_entities s ON s.id = p.system_id
+			WHERE s.game_id = $1 AND p.owner_id = $2`, game.ID, playerID).Scan(&owned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if owned != 1 {
+			t.Fatalf("player %d owns %d planets after activation, want 1", playerID, owned)
+		}
+	}
+}
+
+func TestActivatedLobbySpreadsStartingPositions(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	// Four sectors with a spawn system each, so three players can all start in different sectors
+	config := smallConfig()
+	config.MinPlayers = 3
+	config.SectorsPerGalaxy = 4
+	game := createTestGame(t, service, db, config)
+	joinPlayers(t, service, db, game.ID, 3)
+
+	if _, err := service.ActivateScheduledGames(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The closest two homes; any two in different sectors are at least 1000 apart
+	var homes, minDistance int
+	err := db.QueryRow(`
+		WITH homes AS (
+			SELECT p.id, sec.id AS sector_id, sec.x_coord AS sec_x, sec.y_coord AS sec_y,
+				sys.x_coord AS sys_x, sys.y_coord AS sys_y
+			FROM planets p
+			JOIN spatial_entities sys ON sys.id = p.system_id
+			JOIN spatial_entities sec ON sec.id = sys.parent_id
+			WHERE sys.game_id = $1 AND p.owner_id IS NOT NULL
+		)
+		SELECT (SELECT COUNT(*) FROM homes), COALESCE(MIN(CASE
+			WHEN a.sector_id <> b.sector_id THEN 1000 * GREATEST(ABS(a.sec_x - b.sec_x), ABS(a.sec_y - b.sec_y))
+			ELSE GREATEST(ABS(a.sys_x - b.sys_x), ABS(a.sys_y - b.sys_y))
+		END), 0)
+		FROM homes a
+		JOIN homes b ON a.id < b.id`, game.ID).Scan(&homes, &minDistance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if homes != 3 {
+		t.Fatalf("%d home planets after activation, want 3", homes)
+	}
+	if minDistance < 1000 {
+		t.Fatalf("closest homes are %d apart, want every player in a different sector", minDistance)
+	}
+}
+
+func TestCancelExpiredLobbies(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	config := smallConfig()
+	config.MinPlayers = 2
+	short := createTestGame(t, service, db, config)
+	full := createTestGame(t, service, db, config)
+	joinPlayers(t, service, db, short.ID, 1)
+	joinPlayers(t, service, db, full.ID, 2)
+
+	if _, err := db.Exec("UPDATE games SET start_at = NOW() - INTERVAL '1 hour'"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a grace period a short lobby waits forever
+	cancelled, err := service.CancelExpiredLobbies(ctx)
+	if err != nil || len(cancelled) != 0 {
+		t.Fatalf("CancelExpiredLobbies() = %v, %v; want nothing without a grace period", cancelled, err)
+	}
+
+	service.lobbyGracePeriod = 2 * time.Hour
+	if cancelled, err = service.CancelExpiredLobbies(ctx); err != nil || len(cancelled) != 0 {
+		t.Fatalf("CancelExpiredLobbies() = %v, %v; want nothing within the grace period", cancelled, err)
+	}
+
+	service.lobbyGracePeriod = 30 * time.Minute
+	if cancelled, err = service.CancelExpiredLobbies(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cancelled, []int{short.ID}) {
+		t.Fatalf("cancelled %v, want only the short lobby %d", cancelled, short.ID)
+	}
+	if status := gameStatus(t, service, short.ID); status != GameStatusCancelled {
+		t.Fatalf("short lobby status = %s, want cancelled", status)
+	}
+	if status := gameStatus(t, service, full.ID); status != GameStatusScheduled {
+		t.Fatalf("full lobby status = %s, want scheduled", status)
+	}
+}
+
+func TestLobbyState(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	lobby := lobbyState(now.Add(90*time.Second), 4, 1, time.Hour, now)
+	if lobby.SecondsUntilStart != 90 || lobby.PlayersNeeded != 3 {
+		t.Fatalf("lobby = %+v, want 90s to start and 3 players needed", lobby)
+	}
+	if lobby.CancelsAt == nil || !lobby.CancelsAt.Equal(now.Add(90*time.Second+time.Hour)) {
+		t.Fatalf("cancels_at = %v, want start_at plus the grace period", lobby.CancelsAt)
+	}
+
+	// Past the start with enough players: nothing to wait for and nothing to cancel
+	lobby = lobbyState(now.Add(-time.Minute), 2, 3, time.Hour, now)
+	if lobby.SecondsUntilStart != 0 || lobby.PlayersNeeded != 0 || lobby.CancelsAt != nil {
+		t.Fatalf("lobby = %+v, want a ready lobby", lobby)
+	}
+
+	// Without a grace period a short lobby never cancels
+	if lobby = lobbyState(now, 2, 0, 0, now); lobby.CancelsAt != nil {
+		t.Fatalf("cancels_at = %v, want none without a grace period", lobby.CancelsAt)
+	}
+}
+
+func TestApplySettingsUpdateKeepsMaxPlayersAtOrAbovePlayerCount(t *testing.T) {
+	now := time.Now()
+
+	for _, tt := range []struct {
+		maxPlayers, playerCount int
+		wantErr                 bool
+	}{
+		{maxPlayers: 5, playerCount: 3},
+		{maxPlayers: 3, playerCount: 3},
+		{maxPlayers: 2, playerCount: 3, wantErr: true},
+	} {
+		game := &Game{Status: GameStatusActive, MaxPlayers: 8}
+		err := applySettingsUpdate(game, SettingsUpdate{MaxPlayers: &tt.maxPlayers}, tt.playerCount, now)
+
+		if !tt.wantErr {
+			if err != nil || game.MaxPlayers != tt.maxPlayers {
+				t.Fatalf("max_players %d with %d players: error = %v, max_players = %d", tt.maxPlayers, tt.playerCount, err, game.MaxPlayers)
+			}
+			continue
+		}
+		if errors.GetCode(err) != errors.CodeTooManyPlayers {
+			t.Fatalf("max_players %d with %d players: error = %v, want %s", tt.maxPlayers, tt.playerCount, err, errors.CodeTooManyPlayers)
+		}
+		if game.MaxPlayers != 8 {
+			t.Fatalf("rejected update still set max_players to %d", game.MaxPlayers)
+		}
+	}
+}
+
+func TestUpdateSettingsRejectsMaxPlayersBelowPlayerCount(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	game := createTestGame(t, service, db, smallConfig())
+	// An active game hands out home planets, and smallConfig only guarantees two
+	joinPlayers(t, service, db, game.ID, 2)
+
+	maxPlayers := 1
+	_, err := service.UpdateSettings(ctx, game.ID, SettingsUpdate{MaxPlayers: &maxPlayers})
+	if errors.GetType(err) != errors.ErrorTypeConflict {
+		t.Fatalf("UpdateSettings() error = %v, want a conflict", err)
+	}
+
+	updated, err := service.gameRepo.GetGameByID(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.MaxPlayers != game.MaxPlayers {
+		t.Fatalf("max_players = %d, want it unchanged at %d", updated.MaxPlayers, game.MaxPlayers)
+	}
+}
+
+func TestValidateGenerationConfigZeroPlanets(t *testing.T) {
+	config := smallConfig()
+	config.MinPlanetsPerSystem = 0
+	config.MaxPlanetsPerSystem = 0
+
+	// Spawn systems still get their guaranteed planet
+	if err := validateGenerationConfig(config); err != nil {
+		t.Fatalf("empty systems with spawn systems: error = %v, want it accepted", err)
+	}
+
+	config.SpawnSystemsPerSector = 0
+	if err := validateGenerationConfig(config); errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("no planets and no spawn systems: error = %v, want a validation error", err)
+	}
+
+	// Spawn systems alone are fine as long as systems may hold planets
+	config.MaxPlanetsPerSystem = 1
+	if err := validateGenerationConfig(config); err != nil {
+		t.Fatalf("no spawn systems: error = %v, want it accepted", err)
+	}
+}
+
+func TestZeroPlanetConfigs(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	empty := smallConfig()
+	empty.MinPlanetsPerSystem = 0
+	empty.MaxPlanetsPerSystem = 0
+	empty.SpawnSystemsPerSector = 0
+
+	_, err := service.CreateGame(ctx, empty, dbtest.CreatePlayer(t, db))
+	if errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("CreateGame() error = %v, want a validation error for a universe without planets", err)
+	}
+	var games int
+	if err := db.QueryRow("SELECT COUNT(*) FROM games").Scan(&games); err != nil {
+		t.Fatal(err)
+	}
+	if games != 0 {
+		t.Fatalf("rejected config left %d games behind", games)
+	}
+
+	// Empty systems next to spawn systems make a sparse but coherent universe
+	sparse := smallConfig()
+	sparse.MinPlanetsPerSystem = 0
+	sparse.MaxPlanetsPerSystem = 0
+	game := createTestGame(t, service, db, sparse)
+
+	stats, err := service.GetGameStats(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.SystemCount != 4 || stats.PlanetCount != 2 {
+		t.Fatalf("stats = %d systems and %d planets, want 4 and 2", stats.SystemCount, stats.PlanetCount)
+	}
+
+	if _, err := service.AddGalaxy(ctx, game.ID, empty); errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("AddGalaxy() error = %v, want a validation error for a galaxy without planets", err)
+	}
+}
+
+// makeTurnDue moves the game's next turn into the past, ago before now
+func makeTurnDue(t *testing.T, db *database.DB, gameID int, ago time.Duration) time.Time {
+	t.Helper()
+
+	var nextTurnAt time.Time
+	err := db.QueryRow("UPDATE games SET next_turn_at = $1 WHERE id = $2 RETURNING next_turn_at", time.Now().Add(-ago), gameID).Scan(&nextTurnAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nextTurnAt
+}
+
+func currentTurn(t *testing.T, service *Service, gameID int) int {
+	t.Helper()
+
+	game, err := service.gameRepo.GetGameByID(context.Background(), gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game.CurrentTurn
+}
+
+func TestProcessDueTurnsAdvancesDueGamesOnce(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	due := createTestGame(t, service, db, smallConfig())
+	notDue := createTestGame(t, service, db, smallConfig())
+	dueAt := makeTurnDue(t, db, due.ID, time.Minute)
+
+	advances, err := service.ProcessDueTurns(ctx)
+	if err != nil {
+		t.Fatalf("ProcessDueTurns() error = %v", err)
+	}
+	if len(advances) != 1 || advances[0].GameID != due.ID || advances[0].Turn != 2 {
+		t.Fatalf("ProcessDueTurns() = %+v, want game %d advanced to turn 2", advances, due.ID)
+	}
+	if want := dueAt.Add(time.Hour); !advances[0].NextTurnAt.Equal(want) {
+		t.Fatalf("next turn at %v, want one interval after the previous one, %v", advances[0].NextTurnAt, want)
+	}
+	if _, err := service.gameRepo.GetTurnLog(ctx, due.ID, 2); err != nil {
+		t.Fatalf("turn 2 is not in the turn log: %v", err)
+	}
+	if turn := currentTurn(t, service, notDue.ID); turn != 1 {
+		t.Fatalf("game not due is at turn %d, want 1", turn)
+	}
+
+	// The next turn is an interval away, so a second run has nothing to do
+	advances, err = service.ProcessDueTurns(ctx)
+	if err != nil || len(advances) != 0 {
+		t.Fatalf("second ProcessDueTurns() = %+v, %v; want nothing processed", advances, err)
+	}
+	if turn := currentTurn(t, service, due.ID); turn != 2 {
+		t.Fatalf("game is at turn %d, want 2", turn)
+	}
+}
+
+func TestProcessDueTurnsIsSafeAcrossRestarts(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	game := createTestGame(t, service, db, smallConfig())
+	makeTurnDue(t, db, game.ID, time.Minute)
+
+	// Another instance holds the game's row mid-turn, so this one passes it by without waiting
+	other, err := db.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Exec("SELECT id FROM games WHERE id = $1 FOR UPDATE", game.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	advances, err := service.ProcessDueTurns(ctx)
+	if err != nil || len(advances) != 0 {
+		t.Fatalf("ProcessDueTurns() = %+v, %v while the game is locked; want nothing processed", advances, err)
+	}
+	if err := other.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run that applied turn 2 left it in the ledger, so its effects must not be applied twice
+	if _, err := db.Exec(`INSERT INTO turn_log (game_id, turn, summary) VALUES ($1, 2, '{"decayed_planets": 99}')`, game.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	advances, err = service.ProcessDueTurns(ctx)
+	if err != nil || len(advances) != 1 || advances[0].Turn != 2 {
+		t.Fatalf("ProcessDueTurns() = %+v, %v; want the game moved on to turn 2", advances, err)
+	}
+	entry, err := service.gameRepo.GetTurnLog(ctx, game.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Summary.DecayedPlanets != 99 {
+		t.Fatalf("turn 2 summary = %+v, want the first run's summary kept", entry.Summary)
+	}
+}
+
+func TestProcessDueTurnsSkipsAFailingGame(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	failing := createTestGame(t, service, db, smallConfig())
+	healthy := createTestGame(t, service, db, smallConfig())
+
+	// The failing game comes first and its turn can never be written to the ledger
+	makeTurnDue(t, db, failing.ID, 2*time.Minute)
+	makeTurnDue(t, db, healthy.ID, time.Minute)
+	_, err := db.Exec(fmt.Sprintf(`
+		CREATE FUNCTION fail_turn() RETURNS trigger AS $$
+		BEGIN
+			IF NEW.game_id = %d THEN
+				RAISE EXCEPTION 'turn failed';
+			END IF;
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql;
+		CREATE TRIGGER fail_turn BEFORE INSERT ON turn_log FOR EACH ROW EXECUTE FUNCTION fail_turn();`, failing.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	advances, err := service.ProcessDueTurns(ctx)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("game %d", failing.ID)) {
+		t.Fatalf("ProcessDueTurns() error = %v, want the failing game's error", err)
+	}
+	if len(advances) != 1 || advances[0].GameID != healthy.ID {
+		t.Fatalf("ProcessDueTurns() = %+v, want game %d still processed", advances, healthy.ID)
+	}
+	if turn := currentTurn(t, service, failing.ID); turn != 1 {
+		t.Fatalf("failing game is at turn %d, want its turn rolled back", turn)
+	}
+}
+
+func TestCreateGameAbortsWhenGenerationTimesOut(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	countRows := func() (games, entities, planets int) {
+		t.Helper()
+		err := db.QueryRow(`
+			SELECT (SELECT COUNT(*) FROM games), (SELECT COUNT(*) FROM spatial_entities), (SELECT COUNT(*) FROM planets)`,
+		).Scan(&games, &entities, &planets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return games, entities, planets
+	}
+
+	creatorID := dbtest.CreatePlayer(t, db)
+	gamesBefore, entitiesBefore, planetsBefore := countRows()
+
+	service.maxGenerationTime = time.Nanosecond
+	_, err := service.CreateGame(ctx, smallConfig(), creatorID)
+	if errors.GetType(err) != errors.ErrorTypeExternal || err.Error() != "universe generation timed out" {
+		t.Fatalf("CreateGame() error = %v, want the generation timeout", err)
+	}
+
+	// Nothing generated before the deadline survives, not even the game row
+	games, entities, planets := countRows()
+	if games != gamesBefore || entities != entitiesBefore || planets != planetsBefore {
+		t.Fatalf("after the timeout there are %d games, %d entities and %d planets, want %d, %d and %d",
+			games, entities, planets, gamesBefore, entitiesBefore, planetsBefore)
+	}
+
+	// The service still works once generation has the time it needs
+	service.maxGenerationTime = 0
+	if _, err := service.CreateGame(ctx, smallConfig(), creatorID); err != nil {
+		t.Fatalf("CreateGame() after the timeout error = %v", err)
+	}
+}
+
+func TestCreatingASecondGameKeepsTheFirst(t *testing.T) {
+	service, db := newTestService(t)
+
+	// gameRows counts the game's players, planets and owned planets
+	gameRows := func(gameID int) (players, planets, owned int) {
+		t.Helper()
+		err := db.QueryRow(`
+			SELECT
+				(SELECT COUNT(*) FROM game_players WHERE game_id = $1),
+				COUNT(p.id),
+				COUNT(p.owner_id)
+			FROM planets p
+			JOIN spatial_entities s ON s.id = p.system_id
+			WHERE s.game_id = $1`, gameID).Scan(&players, &planets, &owned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return players, planets, owned
+	}
+
+	first := createTestGame(t, service, db, smallConfig())
+	joinPlayers(t, service, db, first.ID, 2)
+	players, planets, owned := gameRows(first.ID)
+	if players != 2 || owned != 2 || planets == 0 {
+		t.Fatalf("first game has %d players, %d planets, %d owned; want 2 players with a home planet each", players, planets, owned)
+	}
+
+	second := createTestGame(t, service, db, smallConfig())
+
+	if status := gameStatus(t, service, first.ID); status != GameStatusActive {
+		t.Fatalf("first game is %s, want it still active", status)
+	}
+	if p, pl, o := gameRows(first.ID); p != players || pl != planets || o != owned {
+		t.Fatalf("first game now has %d players, %d planets, %d owned; want %d, %d and %d", p, pl, o, players, planets, owned)
+	}
+	if p, pl, o := gameRows(second.ID); p != 0 || pl == 0 || o != 0 {
+		t.Fatalf("second game has %d players, %d planets, %d owned; want its own unowned planets and no players", p, pl, o)
+	}
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"planets-server/internal/planet"
+	"planets-server/internal/shared/errors"
+)
+
+// GameSettings holds per-game gameplay tuning, stored as JSONB so new settings don't need a
+// migration. Every field is optional; the getters fall back to the built-in defaults.
+type GameSettings struct {
+	// PlanetTypeWeights overrides the relative odds of each planet type during generation
+	PlanetTypeWeights map[planet.PlanetType]int `json:"planet_type_weights,omitempty"`
+	// FogOfWar limits what players see of the map to the systems they have discovered
+	FogOfWar bool `json:"fog_of_war,omitempty"`
+}
+
+// TypeWeights returns the planet type weights, using the default weight for any type not set
+func (s GameSettings) TypeWeights() map[planet.PlanetType]int {
+	weights := make(map[planet.PlanetType]int, len(planet.DefaultTypeWeights))
+	for planetType, weight := range planet.DefaultTypeWeights {
+		weights[planetType] = weight
+	}
+	for planetType, weight := range s.PlanetTypeWeights {
+		weights[planetType] = weight
+	}
+	return weights
+}
+
+// SettingsUpdate is a partial change to an existing game; omitted fields keep their value.
+// The generation fields are only decoded so that attempts to change them can be rejected.
+type SettingsUpdate struct {
+	TurnIntervalHours *int                      `json:"turn_interval_hours"`
+	MinPlayers        *int                      `json:"min_players"`
+	MaxPlayers        *int                      `json:"max_players"`
+	PlanetTypeWeights map[planet.PlanetType]int `json:"planet_type_weights"`
+	FogOfWar          *bool                     `json:"fog_of_war"`
+
+	Seed                *string `json:"seed"`
+	GalaxyCount         *int    `json:"galaxy_count"`
+	SectorsPerGalaxy    *int    `json:"sectors_per_galaxy"`
+	SystemsPerSector    *int    `json:"systems_per_sector"`
+	MinPlanetsPerSystem *int    `json:"min_planets_per_system"`
+	MaxPlanetsPerSystem *int    `json:"max_planets_per_system"`
+}
+
+// immutableField returns the name of the first generation field the update tries to set
+func (u SettingsUpdate) immutableField() string {
+	switch {
+	case u.Seed != nil:
+		return "seed"
+	case u.GalaxyCount != nil:
+		return "galaxy_count"
+	case u.SectorsPerGalaxy != nil:
+		return "sectors_per_galaxy"
+	case u.SystemsPerSector != nil:
+		return "systems_per_sector"
+	case u.MinPlanetsPerSystem != nil:
+		return "min_planets_per_system"
+	case u.MaxPlanetsPerSystem != nil:
+		return "max_planets_per_system"
+	}
+	return ""
+}
+
+func (s GameSettings) Validate() error {
+	for planetType, weight := range s.PlanetTypeWeights {
+		if !planetType.IsValid() {
+			return errors.Validationf("planet_type_weights: unknown planet type %q", planetType)
+		}
+		if weight < 0 {
+			return errors.Validationf("planet_type_weights: weight for %s cannot be negative", planetType)
+		}
+	}
+
+	total := 0
+	for _, weight := range s.TypeWeights() {
+		total += weight
+	}
+	if total == 0 {
+		return errors.Validation("planet_type_weights: at least one planet type needs a positive weight")
+	}
+
+	return nil
+}
+
+// Value stores the settings as JSON
+func (s GameSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan loads the settings from the JSONB column
+func (s *GameSettings) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*s = GameSettings{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into game settings", src)
+	}
+
+	return json.Unmarshal(data, s)
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// tickerLoop runs tick every interval in a background goroutine, between Start and Stop or
+// Close. The schedulers embed it for that lifecycle. A zero interval disables it.
+type tickerLoop struct {
+	component string
+	interval  time.Duration
+	tick      func(ctx context.Context, logger *slog.Logger)
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func newTickerLoop(component string, interval time.Duration, tick func(ctx context.Context, logger *slog.Logger)) tickerLoop {
+	return tickerLoop{
+		component: component,
+		interval:  interval,
+		tick:      tick,
+	}
+}
+
+// Start runs the loop in a background goroutine until Stop is called
+func (l *tickerLoop) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+		l.run(ctx)
+	}()
+}
+
+// Stop cancels the loop and waits for the tick in progress, if any, to finish
+func (l *tickerLoop) Stop() {
+	if l.cancel == nil {
+		return
+	}
+
+	l.cancel()
+	<-l.done
+}
+
+// Close stops the loop at shutdown, giving up on waiting for the tick in progress once ctx
+// is done
+func (l *tickerLoop) Close(ctx context.Context) error {
+	if l.cancel == nil {
+		return nil
+	}
+
+	l.cancel()
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *tickerLoop) run(ctx context.Context) {
+	logger := slog.With("component", l.component)
+
+	if l.interval <= 0 {
+		logger.Info("Scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	logger.Debug("Starting scheduler", "interval", l.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("Scheduler stopped")
+			return
+		case <-ticker.C:
+			l.tick(ctx, logger)
+		}
+	}
+}
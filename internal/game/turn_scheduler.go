@@ -0,0 +1,35 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TurnScheduler advances active games whose next turn is due, checking every interval.
+// A zero interval disables it.
+type TurnScheduler struct {
+	tickerLoop
+	service *Service
+}
+
+func NewTurnScheduler(service *Service, interval time.Duration) *TurnScheduler {
+	s := &TurnScheduler{service: service}
+	s.tickerLoop = newTickerLoop("turn_scheduler", interval, s.processDueTurns)
+	return s
+}
+
+func (s *TurnScheduler) processDueTurns(ctx context.Context, logger *slog.Logger) {
+	advances, err := s.service.ProcessDueTurns(ctx)
+
+	for _, advance := range advances {
+		logger.Info("Game turn processed",
+			"game_id", advance.GameID,
+			"turn", advance.Turn,
+			"next_turn_at", advance.NextTurnAt)
+	}
+
+	if err != nil {
+		logger.Error("Failed to process due turns", "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"planets-server/internal/shared/logger"
+)
+
+// AccessLogger writes one log line per request. Requests to excluded paths, such as
+// health probes, are only logged when they fail with a server error.
+type AccessLogger struct {
+	excluded map[string]bool
+}
+
+func NewAccessLogger(excludedPaths []string) *AccessLogger {
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = true
+	}
+	return &AccessLogger{excluded: excluded}
+}
+
+// statusRecorder captures the status code a handler writes
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// Middleware logs through the request-scoped logger, so it must run inside RequestID
+// for the lines to carry the request ID
+func (al *AccessLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		if al.excluded[r.URL.Path] && recorder.status < http.StatusInternalServerError {
+			return
+		}
+
+		logger.FromContext(r.Context()).Info("Request completed",
+			"middleware", "access_log",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"log/slog"
 	"net/http"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/errors"
 	"planets-server/internal/shared/response"
 )
@@ -43,3 +45,24 @@ func AdminMiddleware(next http.Handler) http.Handler {
 func RequireAdmin(next http.Handler) http.Handler {
 	return JWTMiddleware(AdminMiddleware(next))
 }
+
+// RequireAdminOrInternalToken admits automation presenting the configured
+// INTERNAL_TOKEN in the X-Internal-Token header, and falls back to RequireAdmin otherwise
+func RequireAdminOrInternalToken(next http.Handler) http.Handler {
+	adminOnly := RequireAdmin(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := config.GlobalConfig.Auth.InternalToken
+		provided := r.Header.Get("X-Internal-Token")
+
+		if expected != "" && provided != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1 {
+			slog.Debug("Internal token authorization successful",
+				"middleware", "internal_token",
+				"path", r.URL.Path)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		adminOnly.ServeHTTP(w, r)
+	})
+}
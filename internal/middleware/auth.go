@@ -23,15 +23,15 @@ func JWTMiddleware(next http.Handler) http.Handler {
 		)
 		logger.Debug("Processing JWT authentication")
 
-		// Get auth token from cookie
-		cookie, err := r.Cookie("auth_token")
-		if err != nil {
+		// Get auth token from the cookie or Authorization header, as configured
+		token, ok := auth.TokenFromRequest(r)
+		if !ok {
 			response.Error(w, r, logger, errors.Unauthorized("authentication required"))
 			return
 		}
 
 		// Validate JWT token
-		claims, err := auth.ValidateJWT(cookie.Value)
+		claims, err := auth.ValidateJWT(token)
 		if err != nil {
 			response.Error(w, r, logger, errors.Unauthorized("invalid token"))
 			return
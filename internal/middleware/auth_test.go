@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/cookies"
+)
+
+// authenticate runs r through JWTMiddleware and returns the status and the player it let through
+func authenticate(r *http.Request) (int, int) {
+	playerID := 0
+	handler := JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		playerID = GetUserFromContext(r).PlayerID
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code, playerID
+}
+
+func TestCustomAuthCookieRoundTrips(t *testing.T) {
+	useTestConfig(t, &config.Config{Auth: config.AuthConfig{
+		CookieName:  "planets_session",
+		CookiePath:  "/game",
+		TokenSource: config.TokenSourceCookie,
+	}})
+
+	token, err := auth.GenerateJWT(7, "agent", "agent@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := httptest.NewRecorder()
+	cookies.SetAuthCookie(login, token)
+	set := login.Result().Cookies()
+	if len(set) != 1 || set[0].Name != "planets_session" || set[0].Path != "/game" {
+		t.Fatalf("SetAuthCookie() set %+v, want the configured name and path", set)
+	}
+
+	r := httptest.NewRequest("GET", "/api/players/me", nil)
+	r.AddCookie(set[0])
+	if status, playerID := authenticate(r); status != http.StatusOK || playerID != 7 {
+		t.Fatalf("authenticate() = %d, player %d, want 200 for player 7", status, playerID)
+	}
+
+	// The default name is no longer read
+	r = httptest.NewRequest("GET", "/api/players/me", nil)
+	r.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+	if status, _ := authenticate(r); status != http.StatusUnauthorized {
+		t.Fatalf("authenticate() = %d with the default cookie name, want 401", status)
+	}
+
+	// Logout clears the same cookie it was set as
+	logout := httptest.NewRecorder()
+	cookies.ClearAuthCookie(logout)
+	cleared := logout.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].Name != set[0].Name || cleared[0].Path != set[0].Path || cleared[0].MaxAge >= 0 {
+		t.Fatalf("ClearAuthCookie() set %+v, want %s on %s expired", cleared, set[0].Name, set[0].Path)
+	}
+}
+
+func TestJWTMiddlewareTokenSources(t *testing.T) {
+	useTestConfig(t, &config.Config{Auth: config.AuthConfig{CookieName: "auth_token"}})
+
+	token, err := auth.GenerateJWT(7, "agent", "agent@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viaHeader := func() *http.Request {
+		r := httptest.NewRequest("GET", "/api/players/me", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+	viaCookie := func() *http.Request {
+		r := httptest.NewRequest("GET", "/api/players/me", nil)
+		r.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+		return r
+	}
+	badHeader := func() *http.Request {
+		r := httptest.NewRequest("GET", "/api/players/me", nil)
+		r.Header.Set("Authorization", "Bearer "+token+"x")
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		request func() *http.Request
+		want    int
+	}{
+		{"cookie in cookie mode", config.TokenSourceCookie, viaCookie, http.StatusOK},
+		{"header in cookie mode", config.TokenSourceCookie, viaHeader, http.StatusUnauthorized},
+		{"header in header mode", config.TokenSourceHeader, viaHeader, http.StatusOK},
+		{"cookie in header mode", config.TokenSourceHeader, viaCookie, http.StatusUnauthorized},
+		{"header in both mode", config.TokenSourceBoth, viaHeader, http.StatusOK},
+		{"cookie in both mode", config.TokenSourceBoth, viaCookie, http.StatusOK},
+		{"badly signed header", config.TokenSourceBoth, badHeader, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GlobalConfig.Auth.TokenSource = tt.source
+
+			status, playerID := authenticate(tt.request())
+			if status != tt.want {
+				t.Fatalf("authenticate() = %d, want %d", status, tt.want)
+			}
+			if status == http.StatusOK && playerID != 7 {
+				t.Fatalf("authenticated player %d, want 7", playerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxies are the address ranges whose X-Forwarded-For and X-Real-IP headers are believed
+type trustedProxies []netip.Prefix
+
+// parseTrustedProxies accepts CIDRs and bare IPs. Entries that do not parse are logged
+// and skipped; the configuration is validated at startup, so none are expected.
+func parseTrustedProxies(entries []string) trustedProxies {
+	var proxies trustedProxies
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		slog.Warn("Ignoring invalid trusted proxy", "middleware", "client_ip", "entry", entry)
+	}
+	return proxies
+}
+
+func (tp trustedProxies) trusts(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range tp {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address the request came from. Forwarding headers are only honored
+// when the direct peer is a trusted proxy, and X-Forwarded-For is read from the right so
+// the first hop not added by a trusted proxy wins; entries to its left are client-supplied.
+func (tp trustedProxies) clientIP(r *http.Request) string {
+	// Strip port from RemoteAddr (e.g. "192.168.1.1:12345" -> "192.168.1.1")
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	if !tp.trusts(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !tp.trusts(hop) {
+				return hop
+			}
+		}
+		// Every hop is a trusted proxy; the left-most is the closest to the client
+		if first := strings.TrimSpace(hops[0]); first != "" {
+			return first
+		}
+	}
+
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
+		return xri
+	}
+
+	return remote
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// ConcurrencyLimitConfig caps requests in flight, across the server and per client IP.
+// A limit of 0 disables that check.
+type ConcurrencyLimitConfig struct {
+	MaxRequests      int
+	MaxRequestsPerIP int
+	// TrustedProxies works as in RateLimitConfig
+	TrustedProxies []string
+	// Exempt, when set, picks requests that are served without holding a slot, such as
+	// long-lived streams that are capped on their own
+	Exempt func(r *http.Request) bool
+}
+
+// ConcurrencyLimiter rejects requests once too many are already being served. Unlike the
+// rate limiter it bounds slow clients that hold requests open rather than clients that send many.
+type ConcurrencyLimiter struct {
+	config  ConcurrencyLimitConfig
+	proxies trustedProxies
+	global  chan struct{}
+	active  map[string]int
+	mu      sync.Mutex
+}
+
+func NewConcurrencyLimiter(config ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	cl := &ConcurrencyLimiter{
+		config:  config,
+		proxies: parseTrustedProxies(config.TrustedProxies),
+		active:  make(map[string]int),
+	}
+
+	if config.MaxRequests > 0 {
+		cl.global = make(chan struct{}, config.MaxRequests)
+	}
+
+	return cl
+}
+
+func (cl *ConcurrencyLimiter) acquireIP(ip string) bool {
+	if cl.config.MaxRequestsPerIP <= 0 {
+		return true
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.active[ip] >= cl.config.MaxRequestsPerIP {
+		return false
+	}
+	cl.active[ip]++
+	return true
+}
+
+func (cl *ConcurrencyLimiter) releaseIP(ip string) {
+	if cl.config.MaxRequestsPerIP <= 0 {
+		return
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	// Drop idle clients so the map only holds IPs with requests in flight
+	if cl.active[ip] <= 1 {
+		delete(cl.active, ip)
+		return
+	}
+	cl.active[ip]--
+}
+
+func (cl *ConcurrencyLimiter) acquireGlobal() bool {
+	if cl.global == nil {
+		return true
+	}
+
+	select {
+	case cl.global <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (cl *ConcurrencyLimiter) releaseGlobal() {
+	if cl.global != nil {
+		<-cl.global
+	}
+}
+
+func (cl *ConcurrencyLimiter) reject(w http.ResponseWriter, logger *slog.Logger, reason string) {
+	logger.Warn("Concurrency limit exceeded",
+		"limit", reason,
+		"max_requests", cl.config.MaxRequests,
+		"max_requests_per_ip", cl.config.MaxRequestsPerIP,
+	)
+
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "Too many concurrent requests", http.StatusServiceUnavailable)
+}
+
+// Middleware holds a slot for the duration of the request. Slots are released in a
+// deferred call, so a panicking handler cannot leak them.
+func (cl *ConcurrencyLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cl.config.Exempt != nil && cl.config.Exempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := cl.proxies.clientIP(r)
+
+		logger := slog.With(
+			"middleware", "concurrency_limit",
+			"client_ip", ip,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+
+		if !cl.acquireIP(ip) {
+			cl.reject(w, logger, "per_ip")
+			return
+		}
+		defer cl.releaseIP(ip)
+
+		if !cl.acquireGlobal() {
+			cl.reject(w, logger, "global")
+			return
+		}
+		defer cl.releaseGlobal()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExemptRequestsHoldNoSlot(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{
+		MaxRequests: 1,
+		Exempt:      func(r *http.Request) bool { return r.URL.Path == "/stream" },
+	})
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(held)
+			<-release
+		}
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+	<-held
+
+	// The only slot is taken, so regular requests are refused while exempt ones still pass
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/other", http.StatusServiceUnavailable},
+		{"/stream", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+
+	close(release)
+	<-done
+}
@@ -27,9 +27,9 @@ func NewCORS() *CORSMiddleware {
 
 	corsConfig := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		ExposedHeaders:   []string{"Set-Cookie"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", RequestIDHeader},
+		ExposedHeaders:   []string{"Set-Cookie", RequestIDHeader},
 		AllowCredentials: true,
 		Debug:            cfg.Frontend.CORSDebug,
 	})
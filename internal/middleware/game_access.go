@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"database/sql"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -18,7 +19,28 @@ func NewGameAccessMiddleware(db *database.DB) *GameAccessMiddleware {
 	return &GameAccessMiddleware{db: db}
 }
 
+// Require checks game membership for routes keyed by a spatial entity ID
 func (m *GameAccessMiddleware) Require(next http.Handler) http.Handler {
+	return m.require(next, "spatial entity", `SELECT game_id FROM spatial_entities WHERE id = $1`)
+}
+
+// RequirePlanet checks game membership for routes keyed by a planet ID
+func (m *GameAccessMiddleware) RequirePlanet(next http.Handler) http.Handler {
+	return m.require(next, "planet", `
+		SELECT s.game_id
+		FROM planets p
+		JOIN spatial_entities s ON s.id = p.system_id
+		WHERE p.id = $1`)
+}
+
+// RequireGame checks game membership for routes keyed by a game ID
+func (m *GameAccessMiddleware) RequireGame(next http.Handler) http.Handler {
+	return m.require(next, "game", `SELECT id FROM games WHERE id = $1`)
+}
+
+// require resolves the game owning the {id} path value with gameQuery and
+// rejects players who haven't joined that game
+func (m *GameAccessMiddleware) require(next http.Handler, entityName, gameQuery string) http.Handler {
 	return JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := slog.With(
 			"middleware", "game_access",
@@ -32,13 +54,13 @@ func (m *GameAccessMiddleware) Require(next http.Handler) http.Handler {
 			return
 		}
 
-		// Admins can access all spatial entities
+		// Admins can access all games
 		if claims.Role == "admin" {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Parse spatial entity ID from path
+		// Parse entity ID from path
 		entityIDStr := r.PathValue("id")
 		if entityIDStr == "" {
 			response.Error(w, r, logger, errors.Validation("entity ID is required"))
@@ -51,13 +73,11 @@ func (m *GameAccessMiddleware) Require(next http.Handler) http.Handler {
 			return
 		}
 
-		// Look up game_id from spatial_entities table
+		// Look up the game the entity belongs to
 		var gameID int
-		err = m.db.QueryRowContext(r.Context(),
-			`SELECT game_id FROM spatial_entities WHERE id = $1`, entityID,
-		).Scan(&gameID)
+		err = m.db.QueryRowContext(r.Context(), gameQuery, entityID).Scan(&gameID)
 		if err != nil {
-			response.Error(w, r, logger, errors.NotFoundf("spatial entity not found with id: %d", entityID))
+			response.Error(w, r, logger, errors.NotFoundf("%s not found with id: %d", entityName, entityID))
 			return
 		}
 
@@ -80,3 +100,53 @@ func (m *GameAccessMiddleware) Require(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	}))
 }
+
+// RequireGameGM admits global admins and the game master of the game whose ID is the {id}
+// path value, for per-game management routes
+func (m *GameAccessMiddleware) RequireGameGM(next http.Handler) http.Handler {
+	return JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := slog.With(
+			"middleware", "game_gm",
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+
+		claims := GetUserFromContext(r)
+		if claims == nil {
+			response.Error(w, r, logger, errors.Unauthorized("authentication required"))
+			return
+		}
+
+		if claims.Role == "admin" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gameID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+			return
+		}
+
+		var gmPlayerID sql.NullInt64
+		err = m.db.QueryRowContext(r.Context(), `SELECT gm_player_id FROM games WHERE id = $1`, gameID).Scan(&gmPlayerID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				response.Error(w, r, logger, errors.NotFoundf("game not found with id: %d", gameID))
+				return
+			}
+			response.Error(w, r, logger, database.ClassifyError("failed to look up game master", err))
+			return
+		}
+
+		if !gmPlayerID.Valid || int(gmPlayerID.Int64) != claims.PlayerID {
+			logger.Warn("Non-GM player attempted game management",
+				"game_id", gameID,
+				"player_id", claims.PlayerID)
+			response.Error(w, r, logger, errors.WithCode(errors.Forbidden("game master access required"), errors.CodeNotGameMaster))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+)
+
+func TestRequireGameGM(t *testing.T) {
+	db := dbtest.Open(t)
+	useTestConfig(t, &config.Config{Auth: config.AuthConfig{TokenSource: config.TokenSourceHeader}})
+
+	gmID := dbtest.CreatePlayer(t, db)
+	playerID := dbtest.CreatePlayer(t, db)
+	adminID := dbtest.CreatePlayer(t, db)
+
+	var gameID int
+	if err := db.QueryRow("INSERT INTO games (name, seed, gm_player_id) VALUES ('gm test', 'seed', $1) RETURNING id", gmID).Scan(&gameID); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewGameAccessMiddleware(db).RequireGameGM(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name     string
+		playerID int
+		role     string
+		gameID   string
+		want     int
+		wantCode string
+	}{
+		{"game master", gmID, "user", strconv.Itoa(gameID), http.StatusOK, ""},
+		{"global admin", adminID, "admin", strconv.Itoa(gameID), http.StatusOK, ""},
+		{"other player", playerID, "user", strconv.Itoa(gameID), http.StatusForbidden, errors.CodeNotGameMaster},
+		{"unknown game", gmID, "user", strconv.Itoa(gameID + 1000), http.StatusNotFound, ""},
+		{"invalid game ID", gmID, "user", "abc", http.StatusBadRequest, ""},
+		{"anonymous", 0, "", strconv.Itoa(gameID), http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/games/"+tt.gameID+"/settings", nil)
+			r.SetPathValue("id", tt.gameID)
+			if tt.playerID != 0 {
+				token, err := auth.GenerateJWT(tt.playerID, "agent", "agent@example.com", tt.role)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.want, w.Body)
+			}
+			if tt.wantCode != "" {
+				var body struct {
+					ErrorCode string `json:"error_code"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.ErrorCode != tt.wantCode {
+					t.Fatalf("error_code = %q (%v), want %q", body.ErrorCode, err, tt.wantCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"planets-server/internal/shared/metrics"
+)
+
+// unmatchedRoute labels requests that match no registered pattern
+const unmatchedRoute = "unmatched"
+
+// Metrics records request count, duration and in-flight requests for Prometheus. Requests
+// are labeled with the mux pattern they match, such as /api/games/{id}/join, never the raw path.
+func Metrics(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := unmatchedRoute
+			if _, pattern := mux.Handler(r); pattern != "" {
+				route = pattern
+			}
+
+			inFlight := metrics.HTTPRequestsInFlight.WithLabelValues(r.Method, route)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+		})
+	}
+}
@@ -1,32 +1,75 @@
 package middleware
 
 import (
+	"container/list"
+	"context"
 	"log/slog"
-	"net"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
+	"planets-server/internal/auth"
+
 	"golang.org/x/time/rate"
 )
 
 type RateLimitConfig struct {
 	RequestsPerSecond float64
 	BurstSize         int
-	TrustProxy        bool
+	// TrustedProxies lists the CIDRs or IPs allowed to set the client IP through forwarding
+	// headers. Empty trusts no one, so the client IP is always the direct peer.
+	TrustedProxies []string
+	AdminBypass    bool
+	// MaxClients caps how many buckets are kept; the least recently seen is evicted first.
+	// 0 leaves the map unbounded.
+	MaxClients int
+	// CleanupInterval is how often idle clients are evicted, and ClientTTL how long a client
+	// may go unseen before it is. They default to one and three minutes.
+	CleanupInterval time.Duration
+	ClientTTL       time.Duration
+	// KeyFunc picks the bucket for a request. Claims from a valid token, if any, are
+	// available through GetUserFromContext. Defaults to the player ID, falling back to the client IP.
+	KeyFunc func(*http.Request) string
+}
+
+type rateLimitClient struct {
+	key      string
+	limiter  *rate.Limiter
+	lastSeen time.Time
 }
 
+const (
+	defaultRateLimitCleanupInterval = time.Minute
+	defaultRateLimitClientTTL       = 3 * time.Minute
+)
+
 type RateLimiter struct {
 	config  RateLimitConfig
-	clients map[string]*rate.Limiter
-	mu      sync.RWMutex
+	clients map[string]*list.Element
+	// recent orders clients from most to least recently seen, for eviction
+	recent   *list.List
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.KeyFunc == nil {
+		config.KeyFunc = PlayerOrIPKey(config.TrustedProxies)
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = defaultRateLimitCleanupInterval
+	}
+	if config.ClientTTL <= 0 {
+		config.ClientTTL = defaultRateLimitClientTTL
+	}
+
 	rl := &RateLimiter{
 		config:  config,
-		clients: make(map[string]*rate.Limiter),
+		clients: make(map[string]*list.Element),
+		recent:  list.New(),
+		stop:    make(chan struct{}),
 	}
 
 	go rl.cleanupClients()
@@ -34,46 +77,123 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 	return rl
 }
 
-func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
-	rl.mu.RLock()
-	limiter, exists := rl.clients[ip]
-	rl.mu.RUnlock()
+// PlayerOrIPKey keys authenticated requests by player, so players behind a shared NAT get
+// their own buckets and rotating IPs does not reset one, and everyone else by client IP
+func PlayerOrIPKey(trustedProxies []string) func(*http.Request) string {
+	proxies := parseTrustedProxies(trustedProxies)
+	return func(r *http.Request) string {
+		if claims := GetUserFromContext(r); claims != nil {
+			return "player:" + strconv.Itoa(claims.PlayerID)
+		}
+		return "ip:" + proxies.clientIP(r)
+	}
+}
 
-	if !exists {
-		limiter = rate.NewLimiter(rate.Limit(rl.config.RequestsPerSecond), rl.config.BurstSize)
+func (rl *RateLimiter) getLimiter(key string, now time.Time) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
 
-		rl.mu.Lock()
-		rl.clients[ip] = limiter
-		rl.mu.Unlock()
+	if element, exists := rl.clients[key]; exists {
+		rl.recent.MoveToFront(element)
+		client := element.Value.(*rateLimitClient)
+		client.lastSeen = now
+		return client.limiter
 	}
 
-	return limiter
+	if rl.config.MaxClients > 0 && len(rl.clients) >= rl.config.MaxClients {
+		oldest := rl.recent.Back()
+		rl.recent.Remove(oldest)
+		delete(rl.clients, oldest.Value.(*rateLimitClient).key)
+	}
+
+	client := &rateLimitClient{
+		key:      key,
+		limiter:  rate.NewLimiter(rate.Limit(rl.config.RequestsPerSecond), rl.config.BurstSize),
+		lastSeen: now,
+	}
+	rl.clients[key] = rl.recent.PushFront(client)
+
+	return client.limiter
 }
 
 func (rl *RateLimiter) cleanupClients() {
-	ticker := time.NewTicker(time.Minute)
+	ticker := time.NewTicker(rl.config.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rl.mu.Lock()
-		// Remove clients that haven't been used recently
-		for ip, limiter := range rl.clients {
-			if limiter.TokensAt(time.Now()) == float64(rl.config.BurstSize) {
-				delete(rl.clients, ip)
-			}
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case now := <-ticker.C:
+			evicted, remaining := rl.evictIdle(now)
+			slog.Debug("Rate limiter cleanup finished",
+				"middleware", "rate_limit",
+				"evicted_clients", evicted,
+				"clients", remaining)
 		}
-		rl.mu.Unlock()
 	}
 }
 
+// evictIdle drops clients not seen within ClientTTL of now. The recency list runs from most
+// to least recently seen, so the walk stops at the first client still inside the TTL.
+func (rl *RateLimiter) evictIdle(now time.Time) (evicted, remaining int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cutoff := now.Add(-rl.config.ClientTTL)
+	for element := rl.recent.Back(); element != nil; element = rl.recent.Back() {
+		client := element.Value.(*rateLimitClient)
+		if client.lastSeen.After(cutoff) {
+			break
+		}
+		rl.recent.Remove(element)
+		delete(rl.clients, client.key)
+		evicted++
+	}
+
+	return evicted, len(rl.clients)
+}
+
+// Clients returns how many client buckets the limiter currently holds
+func (rl *RateLimiter) Clients() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.clients)
+}
+
+// Stop ends the background cleanup of idle clients. The limiter keeps limiting.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
+// Close stops the limiter's background cleanup at shutdown
+func (rl *RateLimiter) Close(ctx context.Context) error {
+	rl.Stop()
+	return nil
+}
+
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := getClientIP(r, rl.config.TrustProxy)
-		limiter := rl.getLimiter(ip)
+		claims := requestClaims(r)
+
+		// A revoked admin token must not keep its bypass, so only this path pays for the lookup
+		if rl.config.AdminBypass && claims != nil && claims.Role == "admin" && auth.CheckRevoked(claims) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The limiter runs ahead of JWTMiddleware, so hand KeyFunc the claims validated here
+		// without exposing them to handlers that did not ask for authentication
+		keyRequest := r
+		if claims != nil && GetUserFromContext(r) == nil {
+			keyRequest = r.WithContext(context.WithValue(r.Context(), UserContextKey, claims))
+		}
+		key := rl.config.KeyFunc(keyRequest)
+		limiter := rl.getLimiter(key, time.Now())
 
 		logger := slog.With(
 			"middleware", "rate_limit",
-			"client_ip", ip,
+			"client_key", key,
 			"method", r.Method,
 			"path", r.URL.Path,
 		)
@@ -94,25 +214,24 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-func getClientIP(r *http.Request, trustProxy bool) string {
-	if trustProxy {
-		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-			// X-Forwarded-For can be comma-separated; first entry is the client
-			if i := strings.IndexByte(xff, ','); i != -1 {
-				return strings.TrimSpace(xff[:i])
-			}
-			return xff
-		}
+// requestClaims returns the claims already on the request context, or those of a validly
+// signed token on the request. The revocation list is not consulted, so keying costs no
+// database query; the admin bypass checks it separately. Invalid or missing tokens are
+// treated as anonymous traffic and left to the auth middleware, which does check revocation.
+func requestClaims(r *http.Request) *auth.Claims {
+	if claims := GetUserFromContext(r); claims != nil {
+		return claims
+	}
 
-		if xri := r.Header.Get("X-Real-IP"); xri != "" {
-			return xri
-		}
+	token, ok := auth.TokenFromRequest(r)
+	if !ok {
+		return nil
 	}
 
-	// Strip port from RemoteAddr (e.g. "192.168.1.1:12345" -> "192.168.1.1")
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	claims, err := auth.ParseJWT(token)
 	if err != nil {
-		return r.RemoteAddr
+		return nil
 	}
-	return host
+
+	return claims
 }
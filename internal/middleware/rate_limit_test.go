@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/shared/config"
+)
+
+// useTestConfig installs cfg, with a JWT secret filled in, for the rest of the test
+func useTestConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	cfg.Auth.JWTSecret = "a-test-secret-that-is-long-enough-for-hs256"
+	cfg.Auth.AccessTokenExpiration = 15 * time.Minute
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+type countingRevocationChecker struct {
+	revoked bool
+	calls   atomic.Int32
+}
+
+func (c *countingRevocationChecker) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	c.calls.Add(1)
+	return c.revoked, nil
+}
+
+func TestRequestClaimsSkipsTheRevocationLookup(t *testing.T) {
+	useTestConfig(t, &config.Config{Auth: config.AuthConfig{TokenSource: config.TokenSourceHeader}})
+
+	checker := &countingRevocationChecker{}
+	auth.InitRevocationChecker(checker)
+	t.Cleanup(func() { auth.InitRevocationChecker(nil) })
+
+	token, err := auth.GenerateJWT(7, "agent", "agent@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/api/games/1/public-stats", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	for i := 0; i < 3; i++ {
+		claims := requestClaims(r)
+		if claims == nil || claims.PlayerID != 7 {
+			t.Fatalf("requestClaims() = %+v, want the token's player", claims)
+		}
+	}
+	if calls := checker.calls.Load(); calls != 0 {
+		t.Fatalf("rate limiting made %d revocation lookups, want none", calls)
+	}
+
+	r.Header.Set("Authorization", "Bearer "+token+"x")
+	if claims := requestClaims(r); claims != nil {
+		t.Fatalf("requestClaims() = %+v for a badly signed token, want anonymous", claims)
+	}
+}
+
+func TestAdminBypassChecksRevocation(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		revoked bool
+		want    int
+	}{
+		{"live admin token", false, http.StatusOK},
+		{"revoked admin token", true, http.StatusTooManyRequests},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, &config.Config{Auth: config.AuthConfig{TokenSource: config.TokenSourceHeader}})
+
+			checker := &countingRevocationChecker{revoked: tt.revoked}
+			auth.InitRevocationChecker(checker)
+			t.Cleanup(func() { auth.InitRevocationChecker(nil) })
+
+			token, err := auth.GenerateJWT(1, "admin", "admin@example.com", "admin")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			limiter := NewRateLimiter(RateLimitConfig{
+				RequestsPerSecond: 1,
+				BurstSize:         1,
+				AdminBypass:       true,
+			})
+			t.Cleanup(limiter.Stop)
+			handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			// The burst allows one request, so the second only passes on the bypass
+			var w *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				r := httptest.NewRequest("GET", "/api/server/version", nil)
+				r.Header.Set("Authorization", "Bearer "+token)
+				w = httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+			}
+			if w.Code != tt.want {
+				t.Fatalf("second request status %d, want %d", w.Code, tt.want)
+			}
+			if checker.calls.Load() == 0 {
+				t.Fatal("admin bypass made no revocation lookup")
+			}
+		})
+	}
+}
+
+func TestEvictIdleDropsClientsPastTheTTL(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 1, BurstSize: 1, ClientTTL: 3 * time.Minute})
+	defer rl.Stop()
+
+	start := time.Now()
+	rl.getLimiter("ip:idle", start)
+	active := rl.getLimiter("ip:active", start)
+	rl.getLimiter("ip:active", start.Add(2*time.Minute))
+
+	// Nobody is idle for longer than the TTL yet
+	if evicted, remaining := rl.evictIdle(start.Add(time.Minute)); evicted != 0 || remaining != 2 {
+		t.Fatalf("evictIdle() = %d evicted, %d remaining; want 0 and 2", evicted, remaining)
+	}
+
+	evicted, remaining := rl.evictIdle(start.Add(3*time.Minute + time.Second))
+	if evicted != 1 || remaining != 1 || rl.Clients() != 1 {
+		t.Fatalf("evictIdle() = %d evicted, %d remaining, %d clients; want 1, 1 and 1", evicted, remaining, rl.Clients())
+	}
+
+	// The active client kept its bucket
+	if rl.getLimiter("ip:active", start.Add(4*time.Minute)) != active {
+		t.Fatal("active client lost its bucket")
+	}
+	if rl.Clients() != 1 {
+		t.Fatalf("Clients() = %d, want 1", rl.Clients())
+	}
+}
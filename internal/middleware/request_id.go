@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/utils"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds inbound IDs so a client cannot bloat every log line
+const maxRequestIDLength = 128
+
+// RequestID tags each request with an ID, reusing a well-formed inbound X-Request-ID so
+// a proxy's ID carries through. The ID is echoed in the response header and attached to
+// the logger returned by LoggerFromContext.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			var err error
+			requestID, err = utils.NewUUID()
+			if err != nil {
+				slog.Warn("Failed to generate request ID", "middleware", "request_id", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// LoggerFromContext returns the logger tagged with the current request's ID
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	return logger.FromContext(ctx)
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII without spaces, which is
+// enough for UUIDs and the IDs common proxies generate
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
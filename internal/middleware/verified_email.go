@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"planets-server/internal/player"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/response"
+)
+
+// VerifiedEmailMiddleware rejects players whose account still has a placeholder email,
+// created when their provider reported no verified email
+func VerifiedEmailMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := slog.With(
+			"middleware", "verified_email",
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+
+		claims := GetUserFromContext(r)
+		if claims == nil {
+			response.Error(w, r, logger, errors.Unauthorized("authentication required"))
+			return
+		}
+
+		if player.IsPlaceholderEmail(claims.Email) {
+			logger.Warn("Player without verified email attempted restricted action",
+				"player_id", claims.PlayerID)
+			response.Error(w, r, logger, errors.WithCode(errors.Forbidden("a verified email is required"), errors.CodeEmailUnverified))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireVerifiedEmail authenticates the request and requires a verified email
+func RequireVerifiedEmail(next http.Handler) http.Handler {
+	return JWTMiddleware(VerifiedEmailMiddleware(next))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"planets-server/internal/auth"
+	"planets-server/internal/player"
+	"planets-server/internal/shared/config"
+)
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	useTestConfig(t, &config.Config{Auth: config.AuthConfig{TokenSource: config.TokenSourceHeader}})
+
+	handler := RequireVerifiedEmail(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name  string
+		email string
+		want  int
+	}{
+		{"verified email", "agent@example.com", http.StatusOK},
+		{"placeholder email", player.PlaceholderEmail("github", "1001"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.GenerateJWT(7, "agent", tt.email, "user")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("POST", "/api/games/1/join", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
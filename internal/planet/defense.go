@@ -0,0 +1,42 @@
+package planet
+
+// All planet combat numbers live here so generation, fortification and combat agree.
+
+const (
+	// MaxDefense caps how far a planet can be fortified
+	MaxDefense = 500
+
+	// FortifyCostPerPoint is the population spent for each point of defense
+	FortifyCostPerPoint int64 = 1000
+
+	// shieldPopulationPerPoint is how much population backs one point of shields
+	shieldPopulationPerPoint int64 = 10000
+)
+
+var typeDefenseBonus = map[PlanetType]int{
+	PlanetTypeBarren:      5,
+	PlanetTypeTerrestrial: 10,
+	PlanetTypeGasGiant:    20,
+	PlanetTypeIce:         8,
+	PlanetTypeVolcanic:    15,
+}
+
+// BaseDefense returns the defense a planet starts with, scaling with size and type
+func BaseDefense(planetType PlanetType, size int) int {
+	return size/5 + typeDefenseBonus[planetType]
+}
+
+// FortifyCost returns the population needed to raise defense by points
+func FortifyCost(points int) int64 {
+	return int64(points) * FortifyCostPerPoint
+}
+
+// Shields returns the bonus protection provided by the planet's population
+func Shields(p Planet) int {
+	return int(p.Population / shieldPopulationPerPoint)
+}
+
+// EffectiveDefense is the total strength an attacker has to overcome
+func EffectiveDefense(p Planet) int {
+	return p.Defense + Shields(p)
+}
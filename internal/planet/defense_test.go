@@ -0,0 +1,58 @@
+package planet
+
+import "testing"
+
+func TestBaseDefense(t *testing.T) {
+	for _, tt := range []struct {
+		planetType PlanetType
+		size       int
+		want       int
+	}{
+		{PlanetTypeBarren, 50, 15},
+		{PlanetTypeTerrestrial, 100, 30},
+		{PlanetTypeGasGiant, 200, 60},
+		{PlanetTypeIce, 4, 8},
+		{PlanetTypeVolcanic, 99, 34},
+	} {
+		if got := BaseDefense(tt.planetType, tt.size); got != tt.want {
+			t.Errorf("BaseDefense(%s, %d) = %d, want %d", tt.planetType, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestShields(t *testing.T) {
+	for _, tt := range []struct {
+		population int64
+		want       int
+	}{
+		{0, 0},
+		{9_999, 0},
+		{10_000, 1},
+		{255_000, 25},
+	} {
+		if got := Shields(Planet{Population: tt.population}); got != tt.want {
+			t.Errorf("Shields(population %d) = %d, want %d", tt.population, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveDefenseAddsShieldsToDefense(t *testing.T) {
+	if got := EffectiveDefense(Planet{Defense: 40, Population: 30_000}); got != 43 {
+		t.Fatalf("EffectiveDefense() = %d, want 43", got)
+	}
+}
+
+func TestFortifyCost(t *testing.T) {
+	for _, tt := range []struct {
+		points int
+		want   int64
+	}{
+		{1, 1_000},
+		{25, 25_000},
+		{MaxDefense, 500_000},
+	} {
+		if got := FortifyCost(tt.points); got != tt.want {
+			t.Errorf("FortifyCost(%d) = %d, want %d", tt.points, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
-	"log/slog"
+	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"planets-server/internal/middleware"
 	"planets-server/internal/planet"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/query"
 	"planets-server/internal/shared/response"
+	"planets-server/internal/visibility"
 )
 
 type PlanetHandler struct {
@@ -20,7 +24,7 @@ func NewPlanetHandler(service *planet.Service) *PlanetHandler {
 
 func (h *PlanetHandler) GetBySystemID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "get_planets_by_system")
+	logger := logger.FromContext(r.Context()).With("handler", "get_planets_by_system")
 
 	if r.Method != http.MethodGet {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -39,7 +43,20 @@ func (h *PlanetHandler) GetBySystemID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	planets, err := h.service.GetBySystemID(ctx, systemID)
+	params, err := query.ParseListParams(r, planet.ListSortFields, planet.ListFilters)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+	viewer := visibility.Viewer{PlayerID: claims.PlayerID, Admin: claims.Role == "admin"}
+
+	planets, err := h.service.GetBySystemID(ctx, systemID, params, viewer)
 	if err != nil {
 		response.Error(w, r, logger, err)
 		return
@@ -49,5 +66,312 @@ func (h *PlanetHandler) GetBySystemID(w http.ResponseWriter, r *http.Request) {
 		planets = []planet.Planet{}
 	}
 
+	completed, err := h.service.IsGameCompletedBySystemID(ctx, systemID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, completed, true)
 	response.Success(w, http.StatusOK, planets)
 }
+
+func (h *PlanetHandler) GetMine(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_my_planets")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	params, err := query.ParseListParams(r, planet.ListSortFields, planet.OwnedListFilters)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	page, err := query.ParsePage(r)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	owned, err := h.service.GetOwned(ctx, gameID, claims.PlayerID, params, page)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, owned)
+}
+
+func (h *PlanetHandler) GetOwnershipHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_planet_ownership_history")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	history, err := h.service.GetOwnershipHistory(ctx, planetID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	if history == nil {
+		history = []planet.OwnershipHistoryEntry{}
+	}
+
+	response.Success(w, http.StatusOK, history)
+}
+
+type fortifyRequest struct {
+	Points int `json:"points"`
+}
+
+func (h *PlanetHandler) Fortify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "fortify_planet")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	var req fortifyRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	fortified, err := h.service.Fortify(ctx, planetID, claims.PlayerID, req.Points)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, fortified)
+}
+
+type terraformRequest struct {
+	TargetType planet.PlanetType `json:"target_type"`
+}
+
+func (h *PlanetHandler) Terraform(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "terraform_planet")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	var req terraformRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	terraformed, err := h.service.Terraform(ctx, claims.PlayerID, planetID, req.TargetType)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, terraformed)
+}
+
+type transferRequest struct {
+	ToPlayerID int `json:"to_player_id"`
+}
+
+func (h *PlanetHandler) Colonize(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "colonize_planet")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	colonized, err := h.service.Colonize(ctx, planetID, claims.PlayerID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, colonized)
+}
+
+func (h *PlanetHandler) Abandon(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "abandon_planet")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	abandoned, err := h.service.Abandon(ctx, claims.PlayerID, planetID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, abandoned)
+}
+
+func (h *PlanetHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "transfer_planet")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	planetIDStr := r.PathValue("id")
+	if planetIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("planet ID is required"))
+		return
+	}
+
+	planetID, err := strconv.Atoi(planetIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid planet ID format", err))
+		return
+	}
+
+	var req transferRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+		return
+	}
+
+	if req.ToPlayerID <= 0 {
+		response.Error(w, r, logger, errors.Validation("to_player_id is required"))
+		return
+	}
+
+	transferred, err := h.service.TransferToPlayer(ctx, planetID, claims.PlayerID, req.ToPlayerID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, transferred)
+}
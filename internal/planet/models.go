@@ -1,6 +1,7 @@
 package planet
 
 import (
+	"planets-server/internal/shared/query"
 	"time"
 )
 
@@ -14,6 +15,59 @@ const (
 	PlanetTypeVolcanic    PlanetType = "volcanic"
 )
 
+// ListSortFields and ListFilters are the sort and filter options accepted by the planet list of a system
+var (
+	ListSortFields = map[string]string{
+		"planet_index":   "planet_index",
+		"name":           "name",
+		"size":           "size",
+		"population":     "population",
+		"max_population": "max_population",
+		"defense":        "defense",
+	}
+	ListFilters = map[string]query.Filter{
+		"type": {Column: "type", Parse: query.OneOf(
+			string(PlanetTypeBarren), string(PlanetTypeTerrestrial), string(PlanetTypeGasGiant), string(PlanetTypeIce), string(PlanetTypeVolcanic),
+		)},
+		"owner_id": {Column: "owner_id", Parse: query.Int},
+	}
+	// OwnedListFilters are the filters accepted by a player's own planet list, which is always
+	// restricted to their planets and so has no owner filter
+	OwnedListFilters = map[string]query.Filter{
+		"type": {Column: "type", Parse: query.OneOf(
+			string(PlanetTypeBarren), string(PlanetTypeTerrestrial), string(PlanetTypeGasGiant), string(PlanetTypeIce), string(PlanetTypeVolcanic),
+		)},
+		"min_population": {Column: "population", Op: query.OpGreaterOrEqual, Parse: query.Int},
+		"max_population": {Column: "population", Op: query.OpLessOrEqual, Parse: query.Int},
+	}
+)
+
+// planetTypes lists every planet type in a stable order
+var planetTypes = []PlanetType{
+	PlanetTypeBarren,
+	PlanetTypeTerrestrial,
+	PlanetTypeGasGiant,
+	PlanetTypeIce,
+	PlanetTypeVolcanic,
+}
+
+// DefaultTypeWeights are the relative odds of each planet type, weighting terrestrial planets most heavily
+var DefaultTypeWeights = map[PlanetType]int{
+	PlanetTypeBarren:      15,
+	PlanetTypeTerrestrial: 40,
+	PlanetTypeGasGiant:    20,
+	PlanetTypeIce:         15,
+	PlanetTypeVolcanic:    10,
+}
+
+func (t PlanetType) IsValid() bool {
+	switch t {
+	case PlanetTypeBarren, PlanetTypeTerrestrial, PlanetTypeGasGiant, PlanetTypeIce, PlanetTypeVolcanic:
+		return true
+	}
+	return false
+}
+
 type Planet struct {
 	ID            int        `json:"id"`
 	SystemID      int        `json:"system_id"`
@@ -23,7 +77,65 @@ type Planet struct {
 	Size          int        `json:"size"`
 	Population    int64      `json:"population"`
 	MaxPopulation int64      `json:"max_population"`
+	Defense       int        `json:"defense"`
 	OwnerID       *int       `json:"owner_id"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
+	// Unexplored marks a planet in a system the viewer hasn't discovered, with its details withheld
+	Unexplored bool      `json:"unexplored,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// unexplored returns the planet as seen from outside its system: where it is, but not what it holds
+func (p Planet) unexplored() Planet {
+	return Planet{
+		ID:          p.ID,
+		SystemID:    p.SystemID,
+		PlanetIndex: p.PlanetIndex,
+		Name:        p.Name,
+		Unexplored:  true,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// OwnedPlanets is one page of the planets a player owns in a game
+type OwnedPlanets struct {
+	Planets []Planet `json:"planets"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"has_more"`
+	query.Page
+}
+
+type OwnershipChangeReason string
+
+const (
+	OwnershipChangeColonization OwnershipChangeReason = "colonization"
+	OwnershipChangeTransfer     OwnershipChangeReason = "transfer"
+	OwnershipChangeCombat       OwnershipChangeReason = "combat"
+	OwnershipChangeAbandonment  OwnershipChangeReason = "abandonment"
+	// OwnershipChangeTerraforming records a type change; the owner stays the same
+	OwnershipChangeTerraforming OwnershipChangeReason = "terraforming"
+)
+
+// OwnershipHistoryEntry is a single append-only record of a planet changing hands,
+// or of its owner terraforming it, in which case the old and new types are set
+type OwnershipHistoryEntry struct {
+	ID         int                   `json:"id"`
+	PlanetID   int                   `json:"planet_id"`
+	OldOwnerID *int                  `json:"old_owner_id"`
+	NewOwnerID *int                  `json:"new_owner_id"`
+	Turn       int                   `json:"turn"`
+	Reason     OwnershipChangeReason `json:"reason"`
+	OldType    *PlanetType           `json:"old_type,omitempty"`
+	NewType    *PlanetType           `json:"new_type,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// OwnershipChange is the payload of the planet ownership events
+type OwnershipChange struct {
+	PlanetID   int                   `json:"planet_id"`
+	OldOwnerID *int                  `json:"old_owner_id"`
+	NewOwnerID *int                  `json:"new_owner_id"`
+	Turn       int                   `json:"turn"`
+	Reason     OwnershipChangeReason `json:"reason"`
 }
@@ -0,0 +1,48 @@
+package planet
+
+import (
+	"math/rand"
+
+	"planets-server/internal/shared/config"
+)
+
+const (
+	defaultPopulationPerSize  = 5000
+	defaultPopulationVariance = 20
+)
+
+// homePopulationPercent is the share of its capacity a player's home planet starts with
+const homePopulationPercent = 10
+
+// typeHabitability scales population capacity by planet type, as a percentage
+var typeHabitability = map[PlanetType]int64{
+	PlanetTypeBarren:      50,
+	PlanetTypeTerrestrial: 150,
+	PlanetTypeGasGiant:    40,
+	PlanetTypeIce:         70,
+	PlanetTypeVolcanic:    60,
+}
+
+// MaxPopulationFor returns a population cap that grows with size and habitability,
+// spread by a random factor of up to ±PLANET_POPULATION_VARIANCE percent
+func MaxPopulationFor(planetType PlanetType, size int, rng *rand.Rand) int64 {
+	perSize := int64(defaultPopulationPerSize)
+	variance := defaultPopulationVariance
+	if cfg := config.GlobalConfig; cfg != nil {
+		perSize = cfg.Game.PopulationPerSize
+		variance = cfg.Game.PopulationVariance
+	}
+
+	base := int64(size) * perSize * typeHabitability[planetType] / 100
+	factor := int64(100 - variance + rng.Intn(2*variance+1))
+
+	return base * factor / 100
+}
+
+// populationDecayPercent is the share of population unowned planets lose each turn, 0 when disabled
+func populationDecayPercent() int {
+	if cfg := config.GlobalConfig; cfg != nil {
+		return cfg.Game.PopulationDecay
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package planet
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// averageMaxPopulation samples MaxPopulationFor often enough to smooth out the variance
+func averageMaxPopulation(planetType PlanetType, size int, rng *rand.Rand) int64 {
+	const samples = 2000
+
+	var total int64
+	for i := 0; i < samples; i++ {
+		total += MaxPopulationFor(planetType, size, rng)
+	}
+	return total / samples
+}
+
+func TestLargerPlanetsHoldMorePopulationOnAverage(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, planetType := range planetTypes {
+		previous := int64(0)
+		for _, size := range []int{50, 100, 150, 200} {
+			average := averageMaxPopulation(planetType, size, rng)
+			if average <= previous {
+				t.Fatalf("%s: average cap at size %d is %d, not above %d for the smaller size", planetType, size, average, previous)
+			}
+			previous = average
+		}
+	}
+}
+
+func TestTerrestrialPlanetsHoldTheMostPopulation(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	terrestrial := averageMaxPopulation(PlanetTypeTerrestrial, 120, rng)
+
+	for _, planetType := range planetTypes {
+		if planetType == PlanetTypeTerrestrial {
+			continue
+		}
+		if average := averageMaxPopulation(planetType, 120, rng); average >= terrestrial {
+			t.Fatalf("%s averages %d, not below terrestrial's %d", planetType, average, terrestrial)
+		}
+	}
+}
+
+func TestMaxPopulationStaysWithinVariance(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	base := int64(100) * defaultPopulationPerSize * typeHabitability[PlanetTypeIce] / 100
+	low := base * (100 - defaultPopulationVariance) / 100
+	high := base * (100 + defaultPopulationVariance) / 100
+
+	seen := map[int64]bool{}
+	for i := 0; i < 1000; i++ {
+		got := MaxPopulationFor(PlanetTypeIce, 100, rng)
+		if got < low || got > high {
+			t.Fatalf("cap %d outside [%d, %d]", got, low, high)
+		}
+		seen[got] = true
+	}
+
+	if len(seen) < 2 {
+		t.Fatal("caps never varied, the random factor is not applied")
+	}
+}
+
+func TestMaxPopulationIsDeterministicForASeed(t *testing.T) {
+	a := rand.New(rand.NewSource(42))
+	b := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100; i++ {
+		if x, y := MaxPopulationFor(PlanetTypeVolcanic, 80+i, a), MaxPopulationFor(PlanetTypeVolcanic, 80+i, b); x != y {
+			t.Fatalf("same seed gave %d and %d", x, y)
+		}
+	}
+}
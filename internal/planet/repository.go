@@ -2,21 +2,37 @@ package planet
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
 )
 
 type Repository struct {
 	db *database.DB
+	// copyThreshold is the batch size from which inserts switch to COPY; zero disables COPY
+	copyThreshold int
 }
 
 func NewRepository(db *database.DB) *Repository {
+	var copyThreshold int
+	if cfg := config.GlobalConfig; cfg != nil {
+		copyThreshold = cfg.Database.CopyThreshold
+	}
+
 	return &Repository{
-		db: db,
+		db:            db,
+		copyThreshold: copyThreshold,
 	}
 }
 
+// useCopy reports whether a batch of n rows should be inserted with COPY, which needs a transaction
+func (r *Repository) useCopy(n int, tx *database.Tx) bool {
+	return tx != nil && r.copyThreshold > 0 && n >= r.copyThreshold
+}
+
 func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
 	if tx != nil {
 		return tx
@@ -32,14 +48,27 @@ type BatchInsertRequest struct {
 	Type          PlanetType
 	Size          int
 	MaxPopulation int64
+	Defense       int
 }
 
-// CreatePlanetsBatch creates multiple planets in a single database operation using JSON
+// CreatePlanetsBatch creates multiple planets in a single database operation using JSON,
+// or with COPY for batches above the configured threshold
 func (r *Repository) CreatePlanetsBatch(ctx context.Context, planets []BatchInsertRequest, tx *database.Tx) (int, error) {
 	if len(planets) == 0 {
 		return 0, nil
 	}
 
+	// Reject unknown types here rather than surfacing Postgres' enum cast error
+	for i, planet := range planets {
+		if !planet.Type.IsValid() {
+			return 0, errors.Validationf("planet %d in batch has unknown type %q", i, planet.Type)
+		}
+	}
+
+	if r.useCopy(len(planets), tx) {
+		return r.copyPlanets(ctx, planets, tx)
+	}
+
 	exec := r.getExecutor(tx)
 
 	// Convert planets to JSON
@@ -49,7 +78,7 @@ func (r *Repository) CreatePlanetsBatch(ctx context.Context, planets []BatchInse
 	}
 
 	query := `
-		INSERT INTO planets (system_id, planet_index, name, type, size, population, max_population, owner_id)
+		INSERT INTO planets (system_id, planet_index, name, type, size, population, max_population, defense, owner_id)
 		SELECT
 			(data->>'SystemID')::integer,
 			(data->>'PlanetIndex')::integer,
@@ -58,6 +87,7 @@ func (r *Repository) CreatePlanetsBatch(ctx context.Context, planets []BatchInse
 			(data->>'Size')::integer,
 			0,
 			(data->>'MaxPopulation')::bigint,
+			(data->>'Defense')::integer,
 			NULL
 		FROM json_array_elements($1::json) AS data`
 
@@ -74,21 +104,52 @@ func (r *Repository) CreatePlanetsBatch(ctx context.Context, planets []BatchInse
 	return int(count), nil
 }
 
-const planetColumns = `id, system_id, planet_index, name, type, size, population, max_population, owner_id, created_at, updated_at`
+func (r *Repository) copyPlanets(ctx context.Context, planets []BatchInsertRequest, tx *database.Tx) (int, error) {
+	columns := []string{"system_id", "planet_index", "name", "type", "size", "population", "max_population", "defense"}
+
+	rows := make([][]any, len(planets))
+	for i, planet := range planets {
+		rows[i] = []any{
+			planet.SystemID, planet.PlanetIndex, planet.Name, string(planet.Type),
+			planet.Size, 0, planet.MaxPopulation, planet.Defense,
+		}
+	}
+
+	if err := database.CopyIn(ctx, tx, "planets", columns, rows); err != nil {
+		return 0, errors.WrapInternal("failed to copy planets", err)
+	}
+
+	return len(planets), nil
+}
+
+const planetColumns = `id, system_id, planet_index, name, type, size, population, max_population, defense, owner_id, created_at, updated_at`
 
 func (r *Repository) scanPlanet(scanner interface{ Scan(...any) error }) (Planet, error) {
 	var p Planet
 	err := scanner.Scan(
 		&p.ID, &p.SystemID, &p.PlanetIndex, &p.Name, &p.Type,
-		&p.Size, &p.Population, &p.MaxPopulation, &p.OwnerID, &p.CreatedAt, &p.UpdatedAt,
+		&p.Size, &p.Population, &p.MaxPopulation, &p.Defense, &p.OwnerID, &p.CreatedAt, &p.UpdatedAt,
 	)
 	return p, err
 }
 
-func (r *Repository) GetBySystemID(ctx context.Context, systemID int) ([]Planet, error) {
-	query := `SELECT ` + planetColumns + ` FROM planets WHERE system_id = $1 ORDER BY planet_index`
+func (r *Repository) GetBySystemID(ctx context.Context, systemID int, params query.ListParams) ([]Planet, error) {
+	return database.Read(ctx, r.db, func() ([]Planet, error) {
+		return r.getBySystemID(ctx, systemID, params)
+	})
+}
 
-	rows, err := r.db.QueryContext(ctx, query, systemID)
+func (r *Repository) getBySystemID(ctx context.Context, systemID int, params query.ListParams) ([]Planet, error) {
+	where, filterArgs := params.Where(2)
+	if where != "" {
+		where = " AND " + where
+	}
+
+	sqlQuery := `SELECT ` + planetColumns + ` FROM planets WHERE system_id = $1` + where +
+		` ORDER BY ` + params.OrderBy("planet_index") + `, id`
+	args := append([]any{systemID}, filterArgs...)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, errors.WrapInternal("failed to query planets by system", err)
 	}
@@ -109,3 +170,377 @@ func (r *Repository) GetBySystemID(ctx context.Context, systemID int) ([]Planet,
 
 	return planets, nil
 }
+
+// GetOwnedByGame returns one page of the player's planets in the game, filtered and
+// sorted as requested, together with the number of planets matching the filters
+func (r *Repository) GetOwnedByGame(ctx context.Context, gameID, playerID int, params query.ListParams, page query.Page) ([]Planet, int, error) {
+	where, filterArgs := params.Where(3)
+	if where != "" {
+		where = " AND " + where
+	}
+
+	from := ` FROM planets
+		WHERE owner_id = $2
+			AND system_id IN (SELECT id FROM spatial_entities WHERE game_id = $1 AND entity_type = 'system')` + where
+	args := append([]any{gameID, playerID}, filterArgs...)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, errors.WrapInternal("failed to count owned planets", err)
+	}
+
+	limit, pageArgs := page.Clause(len(args) + 1)
+	sqlQuery := `SELECT ` + planetColumns + from +
+		` ORDER BY ` + params.OrderBy("system_id, planet_index") + `, id ` + limit
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, append(args, pageArgs...)...)
+	if err != nil {
+		return nil, 0, errors.WrapInternal("failed to query owned planets", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var planets []Planet
+	for rows.Next() {
+		planet, err := r.scanPlanet(rows)
+		if err != nil {
+			return nil, 0, errors.WrapInternal("failed to scan planet", err)
+		}
+		planets = append(planets, planet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.WrapInternal("error iterating owned planets", err)
+	}
+
+	return planets, total, nil
+}
+
+// GetByIDForUpdate loads a planet and locks its row for the rest of the transaction
+func (r *Repository) GetByIDForUpdate(ctx context.Context, planetID int, tx *database.Tx) (*Planet, error) {
+	exec := r.getExecutor(tx)
+
+	query := `SELECT ` + planetColumns + ` FROM planets WHERE id = $1 FOR UPDATE`
+
+	planet, err := r.scanPlanet(exec.QueryRowContext(ctx, query, planetID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, errors.WrapInternal("failed to get planet by id", err)
+	}
+
+	return &planet, nil
+}
+
+// ApplyFortification raises a planet's defense and deducts the population spent on it
+func (r *Repository) ApplyFortification(ctx context.Context, planetID int, points int, cost int64, tx *database.Tx) (*Planet, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets
+		SET defense = defense + $2, population = population - $3
+		WHERE id = $1
+		RETURNING ` + planetColumns
+
+	planet, err := r.scanPlanet(exec.QueryRowContext(ctx, query, planetID, points, cost))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, errors.WrapInternal("failed to fortify planet", err)
+	}
+
+	return &planet, nil
+}
+
+// ApplyTerraforming changes a planet's type and population cap and deducts the population
+// spent on it, trimming the remaining population to the new cap
+func (r *Repository) ApplyTerraforming(ctx context.Context, planetID int, planetType PlanetType, maxPopulation, cost int64, tx *database.Tx) (*Planet, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets
+		SET type = $2, max_population = $3, population = LEAST(population - $4, $3)
+		WHERE id = $1
+		RETURNING ` + planetColumns
+
+	planet, err := r.scanPlanet(exec.QueryRowContext(ctx, query, planetID, planetType, maxPopulation, cost))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, errors.WrapInternal("failed to terraform planet", err)
+	}
+
+	return &planet, nil
+}
+
+// ApplyDecay removes percent of the population, rounded up, from every unowned but
+// populated planet in the game, never going below zero. It returns the planets affected.
+func (r *Repository) ApplyDecay(ctx context.Context, gameID int, percent int, tx *database.Tx) (int64, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets p
+		SET population = GREATEST(p.population - CEIL(p.population * $2 / 100.0)::BIGINT, 0)
+		FROM spatial_entities s
+		WHERE s.id = p.system_id
+			AND s.game_id = $1
+			AND p.owner_id IS NULL
+			AND p.population > 0`
+
+	result, err := exec.ExecContext(ctx, query, gameID, percent)
+	if err != nil {
+		return 0, errors.WrapInternal("failed to apply population decay", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapInternal("failed to count decayed planets", err)
+	}
+
+	return affected, nil
+}
+
+// FindUnownedPlanetForSpawn picks an unowned terrestrial planet in the game as far as possible
+// from the planets players already own, or returns nil when none is left. Distance is measured
+// down the spatial hierarchy: another galaxy is farther than any sector, and another sector
+// farther than any system, with grid distance breaking ties at each level.
+// The planet is locked for the rest of the transaction. Planets other joins are claiming
+// are skipped rather than waited on, so concurrent joins land on different planets.
+func (r *Repository) FindUnownedPlanetForSpawn(ctx context.Context, gameID int, tx *database.Tx) (*int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		WITH located AS (
+			SELECT p.id, p.owner_id, p.type,
+				sys.x_coord AS sys_x, sys.y_coord AS sys_y,
+				sec.id AS sector_id, sec.x_coord AS sec_x, sec.y_coord AS sec_y,
+				sec.parent_id AS galaxy_id
+			FROM planets p
+			JOIN spatial_entities sys ON sys.id = p.system_id
+			JOIN spatial_entities sec ON sec.id = sys.parent_id
+			WHERE sys.game_id = $1
+		),
+		homes AS (
+			SELECT galaxy_id, sector_id, sec_x, sec_y, sys_x, sys_y
+			FROM located
+			WHERE owner_id IS NOT NULL
+		),
+		ranked AS (
+			SELECT c.id, MIN(CASE
+				WHEN h.galaxy_id <> c.galaxy_id THEN 1000000
+				WHEN h.sector_id <> c.sector_id THEN 1000 * GREATEST(ABS(h.sec_x - c.sec_x), ABS(h.sec_y - c.sec_y))
+				ELSE GREATEST(ABS(h.sys_x - c.sys_x), ABS(h.sys_y - c.sys_y))
+			END) AS distance
+			FROM located c
+			LEFT JOIN homes h ON TRUE
+			WHERE c.owner_id IS NULL AND c.type = 'terrestrial'
+			GROUP BY c.id
+		)
+		SELECT p.id
+		FROM planets p
+		JOIN ranked r ON r.id = p.id
+		WHERE p.owner_id IS NULL
+		ORDER BY r.distance DESC NULLS FIRST, p.id
+		LIMIT 1
+		FOR UPDATE OF p SKIP LOCKED`
+
+	var planetID int
+	err := exec.QueryRowContext(ctx, query, gameID).Scan(&planetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WrapInternal("failed to find a spawn planet", err)
+	}
+
+	return &planetID, nil
+}
+
+// FindRandomPlanetForSpawn picks any unowned terrestrial planet in the game, or returns nil
+// when none is left. Like FindUnownedPlanetForSpawn, it locks the planet and skips planets
+// other joins are claiming.
+func (r *Repository) FindRandomPlanetForSpawn(ctx context.Context, gameID int, tx *database.Tx) (*int, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT p.id
+		FROM planets p
+		JOIN spatial_entities sys ON sys.id = p.system_id
+		WHERE sys.game_id = $1 AND p.owner_id IS NULL AND p.type = 'terrestrial'
+		ORDER BY random()
+		LIMIT 1
+		FOR UPDATE OF p SKIP LOCKED`
+
+	var planetID int
+	err := exec.QueryRowContext(ctx, query, gameID).Scan(&planetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WrapInternal("failed to find a spawn planet", err)
+	}
+
+	return &planetID, nil
+}
+
+// SeedHomePlanet gives an unowned planet to the player and raises its population to
+// populationPercent of its capacity, unless it already holds more
+func (r *Repository) SeedHomePlanet(ctx context.Context, planetID, ownerID, populationPercent int, tx *database.Tx) (*Planet, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets
+		SET owner_id = $2, population = GREATEST(population, max_population * $3 / 100)
+		WHERE id = $1 AND owner_id IS NULL
+		RETURNING ` + planetColumns
+
+	planet, err := r.scanPlanet(exec.QueryRowContext(ctx, query, planetID, ownerID, populationPercent))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithCode(errors.Conflictf("planet %d is already owned", planetID), errors.CodePlanetAlreadyOwned)
+		}
+		return nil, errors.WrapInternal("failed to seed home planet", err)
+	}
+
+	return &planet, nil
+}
+
+// ownershipLock is the planet state read while its row is locked
+type ownershipLock struct {
+	OwnerID  *int
+	SystemID int
+	GameID   int
+	Turn     int
+}
+
+// LockOwnership locks the planet row for the rest of the transaction and returns
+// its current owner and system together with its game and the game's current turn
+func (r *Repository) LockOwnership(ctx context.Context, planetID int, tx *database.Tx) (*ownershipLock, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT p.owner_id, p.system_id, g.id, g.current_turn
+		FROM planets p
+		JOIN spatial_entities s ON s.id = p.system_id
+		JOIN games g ON g.id = s.game_id
+		WHERE p.id = $1
+		FOR UPDATE OF p`
+
+	var lock ownershipLock
+	err := exec.QueryRowContext(ctx, query, planetID).Scan(&lock.OwnerID, &lock.SystemID, &lock.GameID, &lock.Turn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, errors.WrapInternal("failed to lock planet ownership", err)
+	}
+
+	return &lock, nil
+}
+
+// IsPlayerInPlanetGame reports whether the player has joined the game the planet belongs to
+func (r *Repository) IsPlayerInPlanetGame(ctx context.Context, planetID, playerID int, tx *database.Tx) (bool, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM planets p
+			JOIN spatial_entities s ON s.id = p.system_id
+			JOIN game_players gp ON gp.game_id = s.game_id
+			WHERE p.id = $1 AND gp.player_id = $2
+		)`
+
+	var exists bool
+	if err := exec.QueryRowContext(ctx, query, planetID, playerID).Scan(&exists); err != nil {
+		return false, errors.WrapInternal("failed to check game membership for planet", err)
+	}
+
+	return exists, nil
+}
+
+func (r *Repository) UpdateOwner(ctx context.Context, planetID int, ownerID *int, tx *database.Tx) (*Planet, error) {
+	exec := r.getExecutor(tx)
+
+	query := `UPDATE planets SET owner_id = $2 WHERE id = $1 RETURNING ` + planetColumns
+
+	planet, err := r.scanPlanet(exec.QueryRowContext(ctx, query, planetID, ownerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, errors.WrapInternal("failed to update planet owner", err)
+	}
+
+	return &planet, nil
+}
+
+func (r *Repository) RecordOwnershipChange(ctx context.Context, entry OwnershipHistoryEntry, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		INSERT INTO planet_ownership_history (planet_id, old_owner_id, new_owner_id, turn, reason, old_type, new_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := exec.ExecContext(ctx, query, entry.PlanetID, entry.OldOwnerID, entry.NewOwnerID, entry.Turn, entry.Reason, entry.OldType, entry.NewType)
+	if err != nil {
+		return errors.WrapInternal("failed to record planet ownership change", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetOwnershipHistory(ctx context.Context, planetID int) ([]OwnershipHistoryEntry, error) {
+	query := `
+		SELECT id, planet_id, old_owner_id, new_owner_id, turn, reason, old_type, new_type, created_at
+		FROM planet_ownership_history
+		WHERE planet_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, planetID)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query planet ownership history", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var history []OwnershipHistoryEntry
+	for rows.Next() {
+		var entry OwnershipHistoryEntry
+		err := rows.Scan(
+			&entry.ID, &entry.PlanetID, &entry.OldOwnerID, &entry.NewOwnerID,
+			&entry.Turn, &entry.Reason, &entry.OldType, &entry.NewType, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.WrapInternal("failed to scan planet ownership history", err)
+		}
+		history = append(history, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating planet ownership history", err)
+	}
+
+	return history, nil
+}
+
+// IsGameCompletedBySystemID reports whether the game owning the system has finished
+func (r *Repository) IsGameCompletedBySystemID(ctx context.Context, systemID int) (bool, error) {
+	query := `
+		SELECT g.status = 'completed'
+		FROM spatial_entities s
+		JOIN games g ON g.id = s.game_id
+		WHERE s.id = $1`
+
+	var completed bool
+	err := r.db.QueryRowContext(ctx, query, systemID).Scan(&completed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors.NotFoundf("system not found with id: %d", systemID)
+		}
+		return false, errors.WrapInternal("failed to get game status for system", err)
+	}
+
+	return completed, nil
+}
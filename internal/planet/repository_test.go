@@ -0,0 +1,266 @@
+package planet
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
+)
+
+// createTestSystem inserts a game with a universe holding a single system and returns their IDs
+func createTestSystem(t testing.TB, db *database.DB) (gameID, systemID int) {
+	t.Helper()
+
+	if err := db.QueryRow("INSERT INTO games (name, seed) VALUES ('planet test', 'seed') RETURNING id").Scan(&gameID); err != nil {
+		t.Fatal(err)
+	}
+
+	var universeID int
+	err := db.QueryRow(`
+		INSERT INTO spatial_entities (game_id, entity_type, level, x_coord, y_coord, name)
+		VALUES ($1, 'universe', 0, 0, 0, 'Universe') RETURNING id`, gameID).Scan(&universeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.QueryRow(`
+		INSERT INTO spatial_entities (game_id, parent_id, entity_type, level, x_coord, y_coord, name)
+		VALUES ($1, $2, 'system', 3, 0, 0, 'System') RETURNING id`, gameID, universeID).Scan(&systemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gameID, systemID
+}
+
+// createTestPlanets inserts count planets into the system and returns their IDs in index order
+func createTestPlanets(t testing.TB, db *database.DB, systemID, count int) []int {
+	t.Helper()
+
+	ctx := context.Background()
+	if _, err := NewRepository(db).CreatePlanetsBatch(ctx, batchOf(systemID, count), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM planets WHERE system_id = $1 ORDER BY planet_index", systemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+// joinTestPlayer creates a player and adds them to the game
+func joinTestPlayer(t testing.TB, db *database.DB, gameID int) int {
+	t.Helper()
+
+	playerID := dbtest.CreatePlayer(t, db)
+	if _, err := db.Exec("INSERT INTO game_players (game_id, player_id) VALUES ($1, $2)", gameID, playerID); err != nil {
+		t.Fatal(err)
+	}
+	return playerID
+}
+
+func batchOf(systemID, count int) []BatchInsertRequest {
+	planets := make([]BatchInsertRequest, count)
+	for i := range planets {
+		planets[i] = BatchInsertRequest{
+			SystemID:      systemID,
+			PlanetIndex:   i,
+			Name:          fmt.Sprintf("Planet %d", i),
+			Type:          PlanetTypeTerrestrial,
+			Size:          100,
+			MaxPopulation: 1_000_000,
+		}
+	}
+	return planets
+}
+
+// BenchmarkCreatePlanetsBatch compares the JSON array insert with COPY for a 500k planet
+// universe, 10k with -short. Every iteration rolls its transaction back.
+func BenchmarkCreatePlanetsBatch(b *testing.B) {
+	db := dbtest.Open(b)
+	ctx := context.Background()
+	_, systemID := createTestSystem(b, db)
+
+	count := 500_000
+	if testing.Short() {
+		count = 10_000
+	}
+	planets := batchOf(systemID, count)
+
+	for _, bm := range []struct {
+		name          string
+		copyThreshold int
+	}{
+		{"array", 0},
+		{"copy", 1},
+	} {
+		b.Run(fmt.Sprintf("%s/%d", bm.name, count), func(b *testing.B) {
+			repo := &Repository{db: db, copyThreshold: bm.copyThreshold}
+
+			for b.Loop() {
+				tx, err := db.BeginTx(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				inserted, err := repo.CreatePlanetsBatch(ctx, planets, tx)
+				_ = tx.Rollback()
+				if err != nil {
+					b.Fatal(err)
+				}
+				if inserted != count {
+					b.Fatalf("inserted %d planets, want %d", inserted, count)
+				}
+			}
+		})
+	}
+}
+
+func parseOwnedListParams(t *testing.T, values url.Values) (query.ListParams, error) {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", "/api/games/1/planets/mine?"+values.Encode(), nil)
+	return query.ParseListParams(r, ListSortFields, OwnedListFilters)
+}
+
+func TestOwnedListFiltersAreAllowlisted(t *testing.T) {
+	for _, values := range []url.Values{
+		{"sort": {"owner_id"}},
+		{"sort": {"population; DROP TABLE planets"}},
+		{"type": {"lava"}},
+		{"min_population": {"many"}},
+	} {
+		if _, err := parseOwnedListParams(t, values); errors.GetType(err) != errors.ErrorTypeValidation {
+			t.Errorf("%s: got %v, want a validation error", values.Encode(), err)
+		}
+	}
+
+	// The list is always the caller's own planets, so an owner filter is not applied
+	params, err := parseOwnedListParams(t, url.Values{"owner_id": {"1"}, "system_id": {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where, args := params.Where(1); where != "" || args != nil {
+		t.Fatalf("Where() = %q, %v; want unlisted parameters ignored", where, args)
+	}
+}
+
+func TestGetOwnedByGameFilters(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	ids := createTestPlanets(t, db, systemID, 7)
+	playerID := joinTestPlayer(t, db, gameID)
+	otherID := joinTestPlayer(t, db, gameID)
+
+	planets := []struct {
+		planetType PlanetType
+		population int64
+		ownerID    *int
+	}{
+		{PlanetTypeTerrestrial, 100, &playerID},
+		{PlanetTypeTerrestrial, 500, &playerID},
+		{PlanetTypeIce, 300, &playerID},
+		{PlanetTypeIce, 900, &playerID},
+		{PlanetTypeVolcanic, 700, &playerID},
+		{PlanetTypeTerrestrial, 800, &otherID},
+		{PlanetTypeTerrestrial, 600, nil},
+	}
+	for i, p := range planets {
+		_, err := db.Exec("UPDATE planets SET type = $2, population = $3, owner_id = $4 WHERE id = $1", ids[i], p.planetType, p.population, p.ownerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		page   query.Page
+		want   []int
+		total  int
+	}{
+		{"no filters", url.Values{}, query.Page{Limit: 10}, []int{ids[0], ids[1], ids[2], ids[3], ids[4]}, 5},
+		{"type", url.Values{"type": {"ice"}}, query.Page{Limit: 10}, []int{ids[2], ids[3]}, 2},
+		{"min population", url.Values{"min_population": {"500"}}, query.Page{Limit: 10}, []int{ids[1], ids[3], ids[4]}, 3},
+		{"max population", url.Values{"max_population": {"300"}}, query.Page{Limit: 10}, []int{ids[0], ids[2]}, 2},
+		{"population range", url.Values{"min_population": {"300"}, "max_population": {"700"}}, query.Page{Limit: 10}, []int{ids[1], ids[2], ids[4]}, 3},
+		{"type and population", url.Values{"type": {"terrestrial"}, "min_population": {"200"}}, query.Page{Limit: 10}, []int{ids[1]}, 1},
+		{"no match", url.Values{"type": {"gas_giant"}}, query.Page{Limit: 10}, nil, 0},
+		{"sorted by population", url.Values{"sort": {"population"}, "order": {"desc"}}, query.Page{Limit: 10}, []int{ids[3], ids[4], ids[1], ids[2], ids[0]}, 5},
+		{"filtered, sorted and paged", url.Values{"min_population": {"300"}, "sort": {"population"}}, query.Page{Limit: 2, Offset: 1}, []int{ids[1], ids[4]}, 4},
+	}
+
+	for _, tt := range tests {
+		params, err := parseOwnedListParams(t, tt.values)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got, total, err := repo.GetOwnedByGame(ctx, gameID, playerID, params, tt.page)
+		if err != nil {
+			t.Fatalf("%s: GetOwnedByGame() error = %v", tt.name, err)
+		}
+
+		var gotIDs []int
+		for _, p := range got {
+			gotIDs = append(gotIDs, p.ID)
+		}
+		if !reflect.DeepEqual(gotIDs, tt.want) || total != tt.total {
+			t.Fatalf("%s: got %v of %d, want %v of %d", tt.name, gotIDs, total, tt.want, tt.total)
+		}
+	}
+}
+
+func TestCreatePlanetsBatchRejectsUnknownTypes(t *testing.T) {
+	planets := batchOf(1, 3)
+	planets[1].Type = "lava"
+
+	// Validation runs before the database is touched, so no connection is needed
+	inserted, err := (&Repository{}).CreatePlanetsBatch(context.Background(), planets, nil)
+	if errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("CreatePlanetsBatch() error = %v, want a validation error", err)
+	}
+	if !strings.Contains(err.Error(), `"lava"`) {
+		t.Fatalf("error %q does not name the bad type", err)
+	}
+	if inserted != 0 {
+		t.Fatalf("inserted %d planets, want none", inserted)
+	}
+}
+
+func TestPlanetTypeIsValid(t *testing.T) {
+	for _, planetType := range planetTypes {
+		if !planetType.IsValid() {
+			t.Errorf("%q is not valid", planetType)
+		}
+	}
+	for _, planetType := range []PlanetType{"", "lava", "Terrestrial", "gas giant"} {
+		if planetType.IsValid() {
+			t.Errorf("%q is valid", planetType)
+		}
+	}
+}
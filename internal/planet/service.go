@@ -2,24 +2,486 @@ package planet
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"math/rand"
+	"planets-server/internal/combat"
+	"planets-server/internal/events"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/metrics"
+	"planets-server/internal/shared/query"
+	"planets-server/internal/shared/validate"
+	"planets-server/internal/visibility"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type Service struct {
-	repo *Repository
+	repo       *Repository
+	publisher  events.Publisher
+	visibility *visibility.Service
+	// systemGroup collapses concurrent GetBySystemID calls for the same system into one query
+	systemGroup singleflight.Group
+	// randomSpawns hands out any free home planet instead of spreading players apart
+	randomSpawns bool
 }
 
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, publisher events.Publisher, visibility *visibility.Service) *Service {
+	var randomSpawns bool
+	if cfg := config.GlobalConfig; cfg != nil {
+		randomSpawns = cfg.Game.SpawnStrategy == config.SpawnStrategyRandom
+	}
+
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		publisher:    publisher,
+		visibility:   visibility,
+		randomSpawns: randomSpawns,
 	}
 }
 
-func (s *Service) GetBySystemID(ctx context.Context, systemID int) ([]Planet, error) {
-	return s.repo.GetBySystemID(ctx, systemID)
+// GetBySystemID lists the system's planets. Under fog of war, a viewer who hasn't discovered
+// the system only sees where its planets are.
+func (s *Service) GetBySystemID(ctx context.Context, systemID int, params query.ListParams, viewer visibility.Viewer) ([]Planet, error) {
+	scope, err := s.visibility.Scope(ctx, viewer, systemID, []int{systemID})
+	if err != nil {
+		return nil, err
+	}
+
+	explored := scope.Sees(systemID)
+	if !explored {
+		// Filtering or sorting on hidden fields would reveal them through the result order
+		params = query.ListParams{}
+	}
+
+	planets, err := s.getBySystemID(ctx, systemID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The slice is shared with concurrent callers, so each gets a copy of its own
+	if explored {
+		return slices.Clone(planets), nil
+	}
+
+	redacted := make([]Planet, len(planets))
+	for i, planet := range planets {
+		redacted[i] = planet.unexplored()
+	}
+	return redacted, nil
+}
+
+func (s *Service) getBySystemID(ctx context.Context, systemID int, params query.ListParams) ([]Planet, error) {
+	// The shared query must not be cancelled when only the first caller goes away
+	sharedCtx := context.WithoutCancel(ctx)
+
+	key := strconv.Itoa(systemID) + "|" + params.Key()
+	result, err, _ := s.systemGroup.Do(key, func() (any, error) {
+		return s.repo.GetBySystemID(sharedCtx, systemID, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Planet), nil
+}
+
+// GetOwned lists one page of the planets the player owns in the game
+func (s *Service) GetOwned(ctx context.Context, gameID, playerID int, params query.ListParams, page query.Page) (*OwnedPlanets, error) {
+	planets, total, err := s.repo.GetOwnedByGame(ctx, gameID, playerID, params, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if planets == nil {
+		planets = []Planet{}
+	}
+
+	return &OwnedPlanets{Planets: planets, Total: total, HasMore: page.HasMore(total), Page: page}, nil
+}
+
+func (s *Service) IsGameCompletedBySystemID(ctx context.Context, systemID int) (bool, error) {
+	return s.repo.IsGameCompletedBySystemID(ctx, systemID)
+}
+
+func (s *Service) GetOwnershipHistory(ctx context.Context, planetID int) ([]OwnershipHistoryEntry, error) {
+	return s.repo.GetOwnershipHistory(ctx, planetID)
+}
+
+// ApplyDecay shrinks the population of the game's unowned planets by PLANET_POPULATION_DECAY
+// percent, stopping at zero. Meant to run once per turn inside the turn's transaction;
+// it does nothing while decay is disabled.
+func (s *Service) ApplyDecay(ctx context.Context, gameID int, tx *database.Tx) (int64, error) {
+	percent := populationDecayPercent()
+	if percent <= 0 {
+		return 0, nil
+	}
+
+	return s.repo.ApplyDecay(ctx, gameID, percent, tx)
+}
+
+// Colonize assigns an unowned planet to a player who has joined the planet's game
+func (s *Service) Colonize(ctx context.Context, planetID, playerID int) (*Planet, error) {
+	return s.changeOwner(ctx, planetID, &playerID, OwnershipChangeColonization, func(oldOwnerID *int, tx *database.Tx) error {
+		inGame, err := s.repo.IsPlayerInPlanetGame(ctx, planetID, playerID, tx)
+		if err != nil {
+			return err
+		}
+		if !inGame {
+			return errors.WithCode(errors.Forbidden("only players in this planet's game can colonize it"), errors.CodePlayerNotInGame)
+		}
+
+		return nil
+	})
+}
+
+// TransferOwnership hands a planet to a new owner, or releases it when newOwnerID is nil.
+// Planets change hands in combat only through Conquer, which resolves the attack first.
+func (s *Service) TransferOwnership(ctx context.Context, planetID int, newOwnerID *int, reason OwnershipChangeReason) (*Planet, error) {
+	switch reason {
+	case OwnershipChangeTransfer:
+	default:
+		return nil, errors.Validationf("invalid ownership change reason: %s", reason)
+	}
+	return s.changeOwner(ctx, planetID, newOwnerID, reason, nil)
+}
+
+// errAttackRepelled aborts the ownership change of an attack the defender won
+var errAttackRepelled = stderrors.New("attack repelled")
+
+// Conquer resolves an attack of attackStrength by attackerID on another player's planet, and
+// hands the planet to the attacker when the attack beats its effective defense. The outcome is
+// returned either way; a repelled attack leaves the planet as it was.
+func (s *Service) Conquer(ctx context.Context, planetID, attackerID, attackStrength int) (*Planet, *combat.Result, error) {
+	if err := validate.Positive("attack strength", attackStrength); err != nil {
+		return nil, nil, err
+	}
+
+	var result combat.Result
+	planet, err := s.changeOwner(ctx, planetID, &attackerID, OwnershipChangeCombat, func(oldOwnerID *int, tx *database.Tx) error {
+		if oldOwnerID == nil {
+			return errors.Validation("unowned planets are colonized, not attacked")
+		}
+		if *oldOwnerID == attackerID {
+			return errors.WithCode(errors.Conflictf("planet %d already belongs to the attacker", planetID), errors.CodeSameOwner)
+		}
+
+		inGame, err := s.repo.IsPlayerInPlanetGame(ctx, planetID, attackerID, tx)
+		if err != nil {
+			return err
+		}
+		if !inGame {
+			return errors.WithCode(errors.Forbidden("only players in this planet's game can attack it"), errors.CodePlayerNotInGame)
+		}
+
+		target, err := s.repo.GetByIDForUpdate(ctx, planetID, tx)
+		if err != nil {
+			return err
+		}
+
+		result = combat.Resolve(attackStrength, EffectiveDefense(*target))
+		if !result.AttackerWins {
+			return errAttackRepelled
+		}
+		return nil
+	})
+	if stderrors.Is(err, errAttackRepelled) {
+		return nil, &result, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return planet, &result, nil
+}
+
+// TransferToPlayer lets a planet owner gift the planet to another player in the same game
+func (s *Service) TransferToPlayer(ctx context.Context, planetID, fromPlayerID, toPlayerID int) (*Planet, error) {
+	if fromPlayerID == toPlayerID {
+		return nil, errors.Validation("cannot transfer a planet to yourself")
+	}
+
+	return s.changeOwner(ctx, planetID, &toPlayerID, OwnershipChangeTransfer, func(oldOwnerID *int, tx *database.Tx) error {
+		if oldOwnerID == nil || *oldOwnerID != fromPlayerID {
+			return errors.WithCode(errors.Forbidden("only the planet owner can transfer it"), errors.CodeNotPlanetOwner)
+		}
+
+		inGame, err := s.repo.IsPlayerInPlanetGame(ctx, planetID, toPlayerID, tx)
+		if err != nil {
+			return err
+		}
+		if !inGame {
+			return errors.WithCode(errors.Conflictf("player %d is not in this planet's game", toPlayerID), errors.CodePlayerNotInGame)
+		}
+
+		return nil
+	})
+}
+
+// Abandon releases a planet its owner no longer wants. The planet keeps its population,
+// which shrinks each turn while unowned when PLANET_POPULATION_DECAY is enabled.
+func (s *Service) Abandon(ctx context.Context, playerID, planetID int) (*Planet, error) {
+	return s.changeOwner(ctx, planetID, nil, OwnershipChangeAbandonment, func(oldOwnerID *int, tx *database.Tx) error {
+		if oldOwnerID == nil || *oldOwnerID != playerID {
+			return errors.WithCode(errors.Forbidden("only the planet owner can abandon it"), errors.CodeNotPlanetOwner)
+		}
+		return nil
+	})
+}
+
+// ClaimHomePlanet gives the player a starting planet in the game, spread out from the other
+// players unless SPAWN_STRATEGY is random, seeded with homePopulationPercent of its capacity. It runs in the caller's
+// transaction and returns nil when no suitable planet is left. The claim is recorded as a
+// colonization, but no event is published since the caller's transaction may still roll back.
+func (s *Service) ClaimHomePlanet(ctx context.Context, gameID, playerID int, tx *database.Tx) (*Planet, error) {
+	find := s.repo.FindUnownedPlanetForSpawn
+	if s.randomSpawns {
+		find = s.repo.FindRandomPlanetForSpawn
+	}
+
+	planetID, err := find(ctx, gameID, tx)
+	if err != nil || planetID == nil {
+		return nil, err
+	}
+
+	lock, err := s.repo.LockOwnership(ctx, *planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	planet, err := s.repo.SeedHomePlanet(ctx, *planetID, playerID, homePopulationPercent, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.visibility.Discover(ctx, playerID, lock.SystemID, tx); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.RecordOwnershipChange(ctx, OwnershipHistoryEntry{
+		PlanetID:   *planetID,
+		NewOwnerID: &playerID,
+		Turn:       lock.Turn,
+		Reason:     OwnershipChangeColonization,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return planet, nil
+}
+
+// changeOwner updates the planet owner and appends to the ownership history in one transaction.
+// check, when set, runs against the locked current owner before anything is written.
+func (s *Service) changeOwner(ctx context.Context, planetID int, newOwnerID *int, reason OwnershipChangeReason, check func(oldOwnerID *int, tx *database.Tx) error) (*Planet, error) {
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for ownership change", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	lock, err := s.repo.LockOwnership(ctx, planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+	oldOwnerID := lock.OwnerID
+
+	if check != nil {
+		if err = check(oldOwnerID, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if reason == OwnershipChangeColonization && oldOwnerID != nil {
+		err = errors.WithCode(errors.Conflictf("planet %d is already owned", planetID), errors.CodePlanetAlreadyOwned)
+		return nil, err
+	}
+
+	if sameOwner(oldOwnerID, newOwnerID) {
+		err = errors.WithCode(errors.Conflictf("planet %d already belongs to the requested owner", planetID), errors.CodeSameOwner)
+		return nil, err
+	}
+
+	planet, err := s.repo.UpdateOwner(ctx, planetID, newOwnerID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if newOwnerID != nil {
+		if err = s.visibility.Discover(ctx, *newOwnerID, lock.SystemID, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.repo.RecordOwnershipChange(ctx, OwnershipHistoryEntry{
+		PlanetID:   planetID,
+		OldOwnerID: oldOwnerID,
+		NewOwnerID: newOwnerID,
+		Turn:       lock.Turn,
+		Reason:     reason,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit ownership change", err)
+	}
+
+	// Delivery is best-effort and the publisher logs its own failures; the change is already committed
+	_ = s.publisher.Publish(ctx, events.New(ownershipEventTypes[reason], lock.GameID, OwnershipChange{
+		PlanetID:   planetID,
+		OldOwnerID: oldOwnerID,
+		NewOwnerID: newOwnerID,
+		Turn:       lock.Turn,
+		Reason:     reason,
+	}))
+
+	return planet, nil
+}
+
+var ownershipEventTypes = map[OwnershipChangeReason]string{
+	OwnershipChangeColonization: events.TypePlanetColonized,
+	OwnershipChangeTransfer:     events.TypePlanetTransferred,
+	OwnershipChangeCombat:       events.TypePlanetConquered,
+	OwnershipChangeAbandonment:  events.TypePlanetAbandoned,
+}
+
+// Fortify spends the owner's planet population to raise its defense
+func (s *Service) Fortify(ctx context.Context, planetID, playerID, points int) (*Planet, error) {
+	if err := validate.Positive("fortify points", points); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for fortification", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	current, err := s.repo.GetByIDForUpdate(ctx, planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.OwnerID == nil || *current.OwnerID != playerID {
+		err = errors.WithCode(errors.Forbidden("only the planet owner can fortify it"), errors.CodeNotPlanetOwner)
+		return nil, err
+	}
+
+	if current.Defense+points > MaxDefense {
+		err = errors.WithCode(errors.Validationf("defense cannot exceed %d", MaxDefense), errors.CodeMaxDefenseExceeded)
+		return nil, err
+	}
+
+	cost := FortifyCost(points)
+	if current.Population < cost {
+		err = errors.WithCode(errors.Validationf("insufficient population: fortifying %d points costs %d", points, cost), errors.CodeInsufficientPopulation)
+		return nil, err
+	}
+
+	planet, err := s.repo.ApplyFortification(ctx, planetID, points, cost, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit fortification", err)
+	}
+
+	return planet, nil
+}
+
+// Terraform spends the owner's planet population to change the planet's type, rescaling
+// its population cap. Only the transitions priced in TERRAFORM_COSTS are allowed.
+func (s *Service) Terraform(ctx context.Context, playerID, planetID int, targetType PlanetType) (*Planet, error) {
+	if !targetType.IsValid() {
+		return nil, errors.Validationf("invalid planet type: %s", targetType)
+	}
+
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for terraforming", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	lock, err := s.repo.LockOwnership(ctx, planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if lock.OwnerID == nil || *lock.OwnerID != playerID {
+		err = errors.WithCode(errors.Forbidden("only the planet owner can terraform it"), errors.CodeNotPlanetOwner)
+		return nil, err
+	}
+
+	current, err := s.repo.GetByIDForUpdate(ctx, planetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, ok := TerraformCost(current.Type, targetType)
+	if !ok {
+		err = errors.WithCode(errors.Validationf("a %s planet cannot be terraformed into %s", current.Type, targetType), errors.CodeTerraformNotAllowed)
+		return nil, err
+	}
+
+	if current.Population < cost {
+		err = errors.WithCode(errors.Validationf("insufficient population: terraforming into %s costs %d", targetType, cost), errors.CodeInsufficientPopulation)
+		return nil, err
+	}
+
+	planet, err := s.repo.ApplyTerraforming(ctx, planetID, targetType, TerraformedMaxPopulation(*current, targetType), cost, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	oldType := current.Type
+	err = s.repo.RecordOwnershipChange(ctx, OwnershipHistoryEntry{
+		PlanetID:   planetID,
+		OldOwnerID: lock.OwnerID,
+		NewOwnerID: lock.OwnerID,
+		Turn:       lock.Turn,
+		Reason:     OwnershipChangeTerraforming,
+		OldType:    &oldType,
+		NewType:    &targetType,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit terraforming", err)
+	}
+
+	return planet, nil
+}
+
+func sameOwner(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
 
 // generatePlanetNames returns a list of planet suffixes
@@ -30,40 +492,59 @@ func (s *Service) generatePlanetNames() []string {
 	}
 }
 
-// generateRandomPlanetType returns a random planet type using the provided RNG
-func (s *Service) generateRandomPlanetType(rng *rand.Rand) PlanetType {
-	types := []PlanetType{
-		PlanetTypeBarren,
-		PlanetTypeTerrestrial,
-		PlanetTypeGasGiant,
-		PlanetTypeIce,
-		PlanetTypeVolcanic,
+// generateRandomPlanetType returns a random planet type using the provided RNG and weights.
+// Types are walked in a fixed order so the same seed always yields the same planets.
+func (s *Service) generateRandomPlanetType(rng *rand.Rand, weights map[PlanetType]int) PlanetType {
+	totalWeight := 0
+	for _, planetType := range planetTypes {
+		totalWeight += weights[planetType]
 	}
 
-	// Weight terrestrial planets more heavily
-	weights := []int{15, 40, 20, 15, 10} // Terrestrial is 40% chance
-	totalWeight := 0
-	for _, w := range weights {
-		totalWeight += w
+	if totalWeight <= 0 {
+		return PlanetTypeTerrestrial
 	}
 
 	roll := rng.Intn(totalWeight)
 	currentWeight := 0
-	for i, weight := range weights {
-		currentWeight += weight
+	for _, planetType := range planetTypes {
+		currentWeight += weights[planetType]
 		if roll < currentWeight {
-			return types[i]
+			return planetType
 		}
 	}
 
 	return PlanetTypeTerrestrial // fallback
 }
 
-func (s *Service) GeneratePlanets(ctx context.Context, systemIDs []int, minPlanets, maxPlanets int, rng *rand.Rand, tx *database.Tx) (int, error) {
+// GeneratePlanets creates planets for each system, picking types by typeWeights. Systems in spawnSystems
+// always get at least one planet, and their first planet is terrestrial so every starting location is habitable.
+func (s *Service) GeneratePlanets(ctx context.Context, systemIDs []int, minPlanets, maxPlanets int, typeWeights map[PlanetType]int, spawnSystems map[int]bool, rng *rand.Rand, tx *database.Tx) (int, error) {
 	if len(systemIDs) == 0 {
 		return 0, nil
 	}
 
+	defer metrics.ObserveSince(metrics.GenerationDuration.WithLabelValues(metrics.StagePlanets), time.Now())
+
+	batchRequests, err := s.planPlanets(ctx, systemIDs, minPlanets, maxPlanets, typeWeights, spawnSystems, rng)
+	if err != nil {
+		return 0, err
+	}
+
+	// Perform batch insert for all planets
+	if len(batchRequests) == 0 {
+		return 0, nil
+	}
+
+	count, err := s.repo.CreatePlanetsBatch(ctx, batchRequests, tx)
+	if err != nil {
+		return 0, errors.WrapInternal("failed to batch create planets", err)
+	}
+
+	return count, nil
+}
+
+// planPlanets rolls the planets of every system, in system order, without touching the database
+func (s *Service) planPlanets(ctx context.Context, systemIDs []int, minPlanets, maxPlanets int, typeWeights map[PlanetType]int, spawnSystems map[int]bool, rng *rand.Rand) ([]BatchInsertRequest, error) {
 	planetNames := s.generatePlanetNames()
 	var batchRequests []BatchInsertRequest
 
@@ -71,34 +552,35 @@ func (s *Service) GeneratePlanets(ctx context.Context, systemIDs []int, minPlane
 	for _, systemID := range systemIDs {
 		// Check for context cancellation
 		if err := ctx.Err(); err != nil {
-			return 0, errors.WrapInternal("planet generation cancelled", err)
+			return nil, errors.WrapInternal("planet generation cancelled", err)
 		}
 
+		isSpawn := spawnSystems[systemID]
+
 		planetCount := minPlanets + rng.Intn(maxPlanets-minPlanets+1)
+		if isSpawn && planetCount < 1 {
+			planetCount = 1
+		}
 
 		for i := 0; i < planetCount; i++ {
 			planetName := fmt.Sprintf("Planet %s", planetNames[i%len(planetNames)])
+			planetType := s.generateRandomPlanetType(rng, typeWeights)
+			if isSpawn && i == 0 {
+				planetType = PlanetTypeTerrestrial
+			}
+			size := 50 + rng.Intn(151)
 
 			batchRequests = append(batchRequests, BatchInsertRequest{
 				SystemID:      systemID,
 				PlanetIndex:   i,
 				Name:          planetName,
-				Type:          s.generateRandomPlanetType(rng),
-				Size:          50 + rng.Intn(151),
-				MaxPopulation: int64(100000 + rng.Intn(900000)),
+				Type:          planetType,
+				Size:          size,
+				MaxPopulation: MaxPopulationFor(planetType, size, rng),
+				Defense:       BaseDefense(planetType, size),
 			})
 		}
 	}
 
-	// Perform batch insert for all planets
-	if len(batchRequests) == 0 {
-		return 0, nil
-	}
-
-	count, err := s.repo.CreatePlanetsBatch(ctx, batchRequests, tx)
-	if err != nil {
-		return 0, errors.WrapInternal("failed to batch create planets", err)
-	}
-
-	return count, nil
+	return batchRequests, nil
 }
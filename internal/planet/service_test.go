@@ -0,0 +1,556 @@
+package planet
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"planets-server/internal/events"
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
+	"planets-server/internal/visibility"
+)
+
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+func newTestService(t *testing.T) (*Service, *database.DB) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	service := NewService(NewRepository(db), events.NoopPublisher{}, visibility.NewService(visibility.NewRepository(db)))
+	return service, db
+}
+
+func TestSpawnSystemsAlwaysHaveAColonizablePlanet(t *testing.T) {
+	service := &Service{}
+	ctx := context.Background()
+
+	systemIDs := make([]int, 200)
+	spawns := map[int]bool{}
+	for i := range systemIDs {
+		systemIDs[i] = i + 1
+		if i%4 == 0 {
+			spawns[systemIDs[i]] = true
+		}
+	}
+
+	// No terrestrial weight and possibly empty systems: only the guarantee can produce one
+	weights := map[PlanetType]int{PlanetTypeGasGiant: 1, PlanetTypeIce: 1}
+
+	for seed := int64(0); seed < 20; seed++ {
+		planned, err := service.planPlanets(ctx, systemIDs, 0, 3, weights, spawns, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		terrestrial := map[int]bool{}
+		for _, p := range planned {
+			if p.Type == PlanetTypeTerrestrial {
+				terrestrial[p.SystemID] = true
+				if !spawns[p.SystemID] {
+					t.Fatalf("seed %d: non-spawn system %d got a terrestrial planet from zero weight", seed, p.SystemID)
+				}
+				if p.PlanetIndex != 0 {
+					t.Fatalf("seed %d: terrestrial planet of system %d is planet %d, want the first", seed, p.SystemID, p.PlanetIndex)
+				}
+			}
+		}
+
+		for systemID := range spawns {
+			if !terrestrial[systemID] {
+				t.Fatalf("seed %d: spawn system %d has no terrestrial planet", seed, systemID)
+			}
+		}
+	}
+}
+
+func TestSpawnGuaranteeWithNoPlanetsConfigured(t *testing.T) {
+	service := &Service{}
+
+	planned, err := service.planPlanets(context.Background(), []int{1, 2, 3}, 0, 0, DefaultTypeWeights, map[int]bool{2: true}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(planned) != 1 || planned[0].SystemID != 2 || planned[0].Type != PlanetTypeTerrestrial {
+		t.Fatalf("planned %+v, want a single terrestrial planet in spawn system 2", planned)
+	}
+}
+
+func TestPlanPlanetsIsDeterministicForASeed(t *testing.T) {
+	service := &Service{}
+	systemIDs := []int{10, 11, 12, 13}
+	spawns := map[int]bool{10: true}
+
+	a, err := service.planPlanets(context.Background(), systemIDs, 1, 5, DefaultTypeWeights, spawns, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := service.planPlanets(context.Background(), systemIDs, 1, 5, DefaultTypeWeights, spawns, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a) != len(b) {
+		t.Fatalf("same seed planned %d and %d planets", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("planet %d differs for the same seed: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestConcurrentColonizationHasExactlyOneWinner(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	planetID := createTestPlanets(t, db, systemID, 1)[0]
+
+	const colonizers = 8
+	playerIDs := make([]int, colonizers)
+	for i := range playerIDs {
+		playerIDs[i] = joinTestPlayer(t, db, gameID)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, colonizers)
+	start := make(chan struct{})
+	for i, playerID := range playerIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = service.Colonize(ctx, planetID, playerID)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if winner >= 0 {
+				t.Fatalf("players %d and %d both colonized planet %d", playerIDs[winner], playerIDs[i], planetID)
+			}
+			winner = i
+		case errors.GetCode(err) != errors.CodePlanetAlreadyOwned:
+			t.Fatalf("player %d: Colonize() error = %v, want %s", playerIDs[i], err, errors.CodePlanetAlreadyOwned)
+		}
+	}
+	if winner < 0 {
+		t.Fatal("no player colonized the planet")
+	}
+
+	var ownerID, history int
+	err := db.QueryRow(`
+		SELECT p.owner_id, (SELECT COUNT(*) FROM planet_ownership_history h WHERE h.planet_id = p.id)
+		FROM planets p WHERE p.id = $1`, planetID).Scan(&ownerID, &history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ownerID != playerIDs[winner] || history != 1 {
+		t.Fatalf("owner = %d with %d history rows, want the winner %d with 1", ownerID, history, playerIDs[winner])
+	}
+}
+
+func TestFortify(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	ownerID := joinTestPlayer(t, db, gameID)
+	otherID := joinTestPlayer(t, db, gameID)
+
+	cases := []struct {
+		name       string
+		playerID   int
+		defense    int
+		population int64
+		points     int
+		wantCode   string
+		wantType   errors.ErrorType
+	}{
+		{name: "owner fortifies", playerID: ownerID, defense: 30, population: 50_000, points: 20},
+		{name: "spends the whole population", playerID: ownerID, population: 5_000, points: 5},
+		{name: "no points", playerID: ownerID, population: 50_000, points: 0, wantType: errors.ErrorTypeValidation},
+		{name: "not the owner", playerID: otherID, population: 50_000, points: 1, wantCode: errors.CodeNotPlanetOwner},
+		{name: "past the cap", playerID: ownerID, defense: MaxDefense - 5, population: 50_000, points: 6, wantCode: errors.CodeMaxDefenseExceeded},
+		{name: "too little population", playerID: ownerID, population: 4_999, points: 5, wantCode: errors.CodeInsufficientPopulation},
+	}
+	planetIDs := createTestPlanets(t, db, systemID, len(cases))
+
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			planetID := planetIDs[i]
+			if _, err := db.Exec("UPDATE planets SET owner_id = $2, defense = $3, population = $4 WHERE id = $1", planetID, ownerID, tt.defense, tt.population); err != nil {
+				t.Fatal(err)
+			}
+
+			fortified, err := service.Fortify(ctx, planetID, tt.playerID, tt.points)
+
+			if tt.wantCode != "" || tt.wantType != "" {
+				if err == nil {
+					t.Fatal("Fortify() succeeded, want an error")
+				}
+				if tt.wantCode != "" && errors.GetCode(err) != tt.wantCode {
+					t.Fatalf("Fortify() error = %v, want %s", err, tt.wantCode)
+				}
+				if tt.wantType != "" && errors.GetType(err) != tt.wantType {
+					t.Fatalf("Fortify() error = %v, want a %s error", err, tt.wantType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fortified.Defense != tt.defense+tt.points || fortified.Population != tt.population-FortifyCost(tt.points) {
+				t.Fatalf("defense %d, population %d; want %d, %d", fortified.Defense, fortified.Population, tt.defense+tt.points, tt.population-FortifyCost(tt.points))
+			}
+		})
+	}
+}
+
+func TestConquer(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	defenderID := joinTestPlayer(t, db, gameID)
+	attackerID := joinTestPlayer(t, db, gameID)
+
+	// Defense 40 plus 2 points of shields from the population
+	planetIDs := createTestPlanets(t, db, systemID, 2)
+	planetID, unowned := planetIDs[0], planetIDs[1]
+	if _, err := db.Exec("UPDATE planets SET owner_id = $2, defense = 40, population = 20000 WHERE id = $1", planetID, defenderID); err != nil {
+		t.Fatal(err)
+	}
+	owner := func() int {
+		var ownerID int
+		if err := db.QueryRow("SELECT owner_id FROM planets WHERE id = $1", planetID).Scan(&ownerID); err != nil {
+			t.Fatal(err)
+		}
+		return ownerID
+	}
+
+	// A tie goes to the defender and leaves the planet untouched
+	conquered, result, err := service.Conquer(ctx, planetID, attackerID, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conquered != nil || result.AttackerWins || result.DefenseStrength != 42 {
+		t.Fatalf("Conquer() = %v, %+v; want the attack repelled against defense 42", conquered, result)
+	}
+	if owner() != defenderID {
+		t.Fatal("repelled attack changed the owner")
+	}
+
+	conquered, result, err = service.Conquer(ctx, planetID, attackerID, 43)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.AttackerWins || conquered == nil || *conquered.OwnerID != attackerID || owner() != attackerID {
+		t.Fatalf("Conquer() = %v, %+v; want the attacker to take the planet", conquered, result)
+	}
+
+	var reason string
+	if err := db.QueryRow("SELECT reason FROM planet_ownership_history WHERE planet_id = $1", planetID).Scan(&reason); err != nil {
+		t.Fatal(err)
+	}
+	if reason != string(OwnershipChangeCombat) {
+		t.Fatalf("ownership history reason = %s, want %s", reason, OwnershipChangeCombat)
+	}
+
+	// The attacker now owns it, and an unowned planet is colonized rather than attacked
+	if _, _, err := service.Conquer(ctx, planetID, attackerID, 100); errors.GetCode(err) != errors.CodeSameOwner {
+		t.Fatalf("attacking your own planet: error = %v, want %s", err, errors.CodeSameOwner)
+	}
+	if _, _, err := service.Conquer(ctx, unowned, attackerID, 100); errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("attacking an unowned planet: error = %v, want a validation error", err)
+	}
+}
+
+func TestConcurrentSystemReadsShareOneQuery(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	createTestPlanets(t, db, systemID, 3)
+	if _, err := db.Exec(`UPDATE games SET settings = '{"fog_of_war": true}' WHERE id = $1`, gameID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Half the readers discovered the system and see it in full, the other half only see where its planets are
+	const readers = 8
+	viewers := make([]visibility.Viewer, readers)
+	for i := range viewers {
+		viewers[i] = visibility.Viewer{PlayerID: joinTestPlayer(t, db, gameID)}
+		if i%2 == 0 {
+			if _, err := db.Exec("INSERT INTO player_visibility (player_id, system_id) VALUES ($1, $2)", viewers[i].PlayerID, systemID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Every reader queues behind the lock, so they all overlap with the first query
+	holder, release := dbtest.LockTable(t, db, "planets")
+
+	var wg sync.WaitGroup
+	results := make([][]Planet, readers)
+	errs := make([]error, readers)
+	start := make(chan struct{})
+	for i, viewer := range viewers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = service.GetBySystemID(ctx, systemID, query.ListParams{}, viewer)
+		}()
+	}
+	close(start)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for dbtest.Blocked(t, db, holder) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no planet query reached the database")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give the other readers time to arrive; any that query on their own show up as blocked too
+	time.Sleep(200 * time.Millisecond)
+	blocked := dbtest.Blocked(t, db, holder)
+	release()
+	wg.Wait()
+
+	if blocked != 1 {
+		t.Fatalf("%d readers queried planets, want them all to share one query", blocked)
+	}
+
+	for i, planets := range results {
+		if errs[i] != nil {
+			t.Fatalf("reader %d: %v", i, errs[i])
+		}
+		if len(planets) != 3 {
+			t.Fatalf("reader %d got %d planets, want 3", i, len(planets))
+		}
+		for _, planet := range planets {
+			if explored := i%2 == 0; planet.Unexplored == explored {
+				t.Fatalf("reader %d got planet %d with unexplored = %v, want %v", i, planet.ID, planet.Unexplored, !explored)
+			}
+		}
+	}
+
+	// No two readers share a slice, so one caller changing its result cannot leak into another's
+	for i := range results {
+		for j := i + 1; j < readers; j++ {
+			if &results[i][0] == &results[j][0] {
+				t.Fatalf("readers %d and %d got the same slice", i, j)
+			}
+		}
+	}
+}
+
+func TestApplyDecayShrinksUnownedPopulationsDownToZero(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	gameID, systemID := createTestSystem(t, db)
+	planetIDs := createTestPlanets(t, db, systemID, 3)
+	ownerID := joinTestPlayer(t, db, gameID)
+
+	setPopulation := func(planetID int, population int64, ownerID *int) {
+		if _, err := db.Exec("UPDATE planets SET population = $2, owner_id = $3 WHERE id = $1", planetID, population, ownerID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	populations := func() []int64 {
+		result := make([]int64, len(planetIDs))
+		for i, id := range planetIDs {
+			if err := db.QueryRow("SELECT population FROM planets WHERE id = $1", id).Scan(&result[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return result
+	}
+
+	setPopulation(planetIDs[0], 1000, nil)
+	setPopulation(planetIDs[1], 5, nil)
+	setPopulation(planetIDs[2], 1000, &ownerID)
+
+	// Decay is off by default
+	useConfig(t, &config.Config{})
+	if affected, err := service.ApplyDecay(ctx, gameID, nil); err != nil || affected != 0 {
+		t.Fatalf("ApplyDecay() = %d, %v; want nothing while disabled", affected, err)
+	}
+	if got := populations(); got[0] != 1000 || got[1] != 5 {
+		t.Fatalf("populations = %v, want them untouched while disabled", got)
+	}
+
+	useConfig(t, &config.Config{Game: config.GameConfig{PopulationDecay: 10}})
+	affected, err := service.ApplyDecay(ctx, gameID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10% of 5 rounds up to 1, so even small populations keep shrinking
+	if got := populations(); affected != 2 || got[0] != 900 || got[1] != 4 || got[2] != 1000 {
+		t.Fatalf("after one turn: %d planets affected, populations %v; want 2 and [900 4 1000]", affected, got)
+	}
+
+	for turn := 0; affected > 0; turn++ {
+		if turn > 100 {
+			t.Fatalf("population still decaying after %d turns: %v", turn, populations())
+		}
+		if affected, err = service.ApplyDecay(ctx, gameID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := populations(); got[0] != 0 || got[1] != 0 || got[2] != 1000 {
+		t.Fatalf("populations = %v, want [0 0 1000] once decay runs out", got)
+	}
+}
+
+// claimHomesConcurrently has every player claim a home planet at once, each in its own transaction
+func claimHomesConcurrently(t *testing.T, service *Service, gameID int, playerIDs []int) []*Planet {
+	t.Helper()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	homes := make([]*Planet, len(playerIDs))
+	errs := make([]error, len(playerIDs))
+	start := make(chan struct{})
+	for i, playerID := range playerIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			tx, err := service.repo.db.BeginTx(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if homes[i], errs[i] = service.ClaimHomePlanet(ctx, gameID, playerID, tx); errs[i] != nil {
+				_ = tx.Rollback()
+				return
+			}
+			errs[i] = tx.Commit()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("player %d: ClaimHomePlanet() error = %v", playerIDs[i], err)
+		}
+	}
+	return homes
+}
+
+func TestConcurrentJoinsClaimDifferentHomePlanets(t *testing.T) {
+	for _, strategy := range []string{config.SpawnStrategySpread, config.SpawnStrategyRandom} {
+		t.Run(strategy, func(t *testing.T) {
+			service, db := newTestService(t)
+			service.randomSpawns = strategy == config.SpawnStrategyRandom
+
+			gameID, systemID := createTestSystem(t, db)
+			createTestPlanets(t, db, systemID, 4)
+
+			playerIDs := make([]int, 4)
+			for i := range playerIDs {
+				playerIDs[i] = joinTestPlayer(t, db, gameID)
+			}
+
+			claimed := map[int]int{}
+			for i, home := range claimHomesConcurrently(t, service, gameID, playerIDs) {
+				if home == nil {
+					t.Fatalf("player %d got no home planet with one free for every player", playerIDs[i])
+				}
+				if other, ok := claimed[home.ID]; ok {
+					t.Fatalf("players %d and %d both got planet %d", other, playerIDs[i], home.ID)
+				}
+				claimed[home.ID] = playerIDs[i]
+			}
+		})
+	}
+}
+
+func TestSpreadSpawnsKeepPlayersApart(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+
+	// One sector with a row of ten single-planet systems at x = 0..9
+	gameID, sectorID := 0, 0
+	err := db.QueryRow(`
+		WITH game AS (
+			INSERT INTO games (name, seed) VALUES ('spawn test', 'seed') RETURNING id
+		), universe AS (
+			INSERT INTO spatial_entities (game_id, entity_type, level, x_coord, y_coord, name)
+			SELECT id, 'universe', 0, 0, 0, 'Universe' FROM game RETURNING id, game_id
+		), galaxy AS (
+			INSERT INTO spatial_entities (game_id, parent_id, entity_type, level, x_coord, y_coord, name)
+			SELECT game_id, id, 'galaxy', 1, 0, 0, 'Galaxy' FROM universe RETURNING id, game_id
+		)
+		INSERT INTO spatial_entities (game_id, parent_id, entity_type, level, x_coord, y_coord, name)
+		SELECT game_id, id, 'sector', 2, 0, 0, 'Sector' FROM galaxy RETURNING game_id, id`).Scan(&gameID, &sectorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	systemX := map[int]int{}
+	for x := 0; x < 10; x++ {
+		var systemID int
+		err := db.QueryRow(`
+			INSERT INTO spatial_entities (game_id, parent_id, entity_type, level, x_coord, y_coord, name)
+			VALUES ($1, $2, 'system', 3, $3, 0, 'System') RETURNING id`, gameID, sectorID, x).Scan(&systemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		createTestPlanets(t, db, systemID, 1)
+		systemX[systemID] = x
+	}
+
+	// Players join one after another, each placed as far as possible from those before
+	var homeX []int
+	for i := 0; i < 3; i++ {
+		playerID := joinTestPlayer(t, db, gameID)
+
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		home, err := service.ClaimHomePlanet(ctx, gameID, playerID, tx)
+		if err != nil || home == nil {
+			_ = tx.Rollback()
+			t.Fatalf("ClaimHomePlanet() = %v, %v", home, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		homeX = append(homeX, systemX[home.SystemID])
+	}
+
+	// The best three of ten evenly spaced systems are at least 4 apart
+	const minDistance = 4
+	for i := range homeX {
+		for j := i + 1; j < len(homeX); j++ {
+			if distance := max(homeX[i]-homeX[j], homeX[j]-homeX[i]); distance < minDistance {
+				t.Fatalf("home systems at x = %v, players %d and %d only %d apart, want at least %d", homeX, i, j, distance, minDistance)
+			}
+		}
+	}
+}
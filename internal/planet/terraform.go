@@ -0,0 +1,23 @@
+package planet
+
+import "planets-server/internal/shared/config"
+
+// TerraformCost returns the population needed to change a planet from one type to another,
+// and false when TERRAFORM_COSTS does not allow that transition
+func TerraformCost(from, to PlanetType) (int64, bool) {
+	var costs map[string]map[string]int64
+	if cfg := config.GlobalConfig; cfg != nil && cfg.Game.TerraformCosts != nil {
+		costs = cfg.Game.TerraformCosts
+	} else {
+		costs = config.DefaultTerraformCosts()
+	}
+
+	cost, ok := costs[string(from)][string(to)]
+	return cost, ok
+}
+
+// TerraformedMaxPopulation rescales the planet's population cap by the habitability of the
+// new type, keeping the random spread it was generated with
+func TerraformedMaxPopulation(p Planet, to PlanetType) int64 {
+	return p.MaxPopulation * typeHabitability[to] / typeHabitability[p.Type]
+}
@@ -0,0 +1,121 @@
+package player
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// maxListCacheEntries bounds how many distinct player list queries are cached, since the key
+// comes from client-controlled pagination and filters
+const maxListCacheEntries = 256
+
+// listCache holds recent player list results keyed by their query parameters, evicting the
+// least recently used entry once full. A zero TTL disables caching entirely.
+type listCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	// recent orders entries from most to least recently used, for eviction
+	recent *list.List
+}
+
+type listCacheEntry struct {
+	key       string
+	list      *PlayerList
+	expiresAt time.Time
+}
+
+func newListCache(ttl time.Duration, maxEntries int) *listCache {
+	return &listCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+func (c *listCache) get(key string, now time.Time) (*PlayerList, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*listCacheEntry)
+	if now.After(entry.expiresAt) {
+		c.recent.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.recent.MoveToFront(element)
+	return entry.list, true
+}
+
+func (c *listCache) set(key string, list *PlayerList, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*listCacheEntry)
+		entry.list = list
+		entry.expiresAt = now.Add(c.ttl)
+		c.recent.MoveToFront(element)
+		return
+	}
+
+	c.evictExpired(now)
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*listCacheEntry).key)
+	}
+
+	c.entries[key] = c.recent.PushFront(&listCacheEntry{
+		key:       key,
+		list:      list,
+		expiresAt: now.Add(c.ttl),
+	})
+}
+
+// evictExpired drops entries past their TTL. Every entry shares the same TTL, so the least
+// recently used end holds the oldest writes, but a read can move an old entry forward, so
+// the whole list is walked.
+func (c *listCache) evictExpired(now time.Time) {
+	for element := c.recent.Back(); element != nil; {
+		previous := element.Prev()
+		entry := element.Value.(*listCacheEntry)
+		if now.After(entry.expiresAt) {
+			c.recent.Remove(element)
+			delete(c.entries, entry.key)
+		}
+		element = previous
+	}
+}
+
+// len returns how many lists are cached, expired or not
+func (c *listCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// invalidate drops every cached list, since any player change can affect all pages
+func (c *listCache) invalidate() {
+	c.mu.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.recent.Init()
+	c.mu.Unlock()
+}
@@ -0,0 +1,125 @@
+package player
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestListCacheHitAndMiss(t *testing.T) {
+	cache := newListCache(time.Minute, 10)
+	now := time.Now()
+	list := &PlayerList{Total: 1}
+
+	if _, ok := cache.get("limit=10;offset=0", now); ok {
+		t.Fatal("empty cache reported a hit")
+	}
+
+	cache.set("limit=10;offset=0", list, now)
+
+	got, ok := cache.get("limit=10;offset=0", now.Add(30*time.Second))
+	if !ok || got != list {
+		t.Fatalf("get() = %v, %v; want the cached list", got, ok)
+	}
+
+	if _, ok := cache.get("limit=10;offset=10", now); ok {
+		t.Fatal("a different page was served from another page's entry")
+	}
+}
+
+func TestListCacheExpiredEntryIsDropped(t *testing.T) {
+	cache := newListCache(time.Minute, 10)
+	now := time.Now()
+
+	cache.set("key", &PlayerList{}, now)
+
+	if _, ok := cache.get("key", now.Add(2*time.Minute)); ok {
+		t.Fatal("expired entry was served")
+	}
+	if n := cache.len(); n != 0 {
+		t.Fatalf("len() = %d after reading an expired entry, want 0", n)
+	}
+}
+
+func TestListCacheSetSweepsExpiredEntries(t *testing.T) {
+	cache := newListCache(time.Minute, 10)
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		cache.set(fmt.Sprintf("offset=%d", i), &PlayerList{}, now)
+	}
+
+	cache.set("fresh", &PlayerList{}, now.Add(2*time.Minute))
+
+	if n := cache.len(); n != 1 {
+		t.Fatalf("len() = %d, want only the fresh entry left", n)
+	}
+}
+
+func TestListCacheIsBounded(t *testing.T) {
+	cache := newListCache(time.Minute, 3)
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		cache.set(fmt.Sprintf("offset=%d", i), &PlayerList{}, now)
+	}
+
+	if n := cache.len(); n != 3 {
+		t.Fatalf("len() = %d, want the cache capped at 3", n)
+	}
+	if _, ok := cache.get("offset=0", now); ok {
+		t.Fatal("the least recently used entry was not evicted")
+	}
+	if _, ok := cache.get("offset=99", now); !ok {
+		t.Fatal("the newest entry was evicted")
+	}
+}
+
+func TestListCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newListCache(time.Minute, 2)
+	now := time.Now()
+
+	cache.set("a", &PlayerList{}, now)
+	cache.set("b", &PlayerList{}, now)
+	cache.get("a", now)
+	cache.set("c", &PlayerList{}, now)
+
+	if _, ok := cache.get("a", now); !ok {
+		t.Fatal("recently read entry was evicted")
+	}
+	if _, ok := cache.get("b", now); ok {
+		t.Fatal("least recently used entry was kept")
+	}
+}
+
+func TestListCacheInvalidate(t *testing.T) {
+	cache := newListCache(time.Minute, 10)
+	now := time.Now()
+
+	cache.set("a", &PlayerList{}, now)
+	cache.set("b", &PlayerList{}, now)
+	cache.invalidate()
+
+	if _, ok := cache.get("a", now); ok {
+		t.Fatal("entry survived invalidate")
+	}
+	if n := cache.len(); n != 0 {
+		t.Fatalf("len() = %d after invalidate, want 0", n)
+	}
+
+	cache.set("a", &PlayerList{}, now)
+	if _, ok := cache.get("a", now); !ok {
+		t.Fatal("cache unusable after invalidate")
+	}
+}
+
+func TestListCacheDisabledByZeroTTL(t *testing.T) {
+	cache := newListCache(0, 10)
+	now := time.Now()
+
+	cache.set("a", &PlayerList{}, now)
+
+	if _, ok := cache.get("a", now); ok {
+		t.Fatal("cache with zero TTL served an entry")
+	}
+}
@@ -1,22 +1,27 @@
 package handlers
 
 import (
-	"log/slog"
 	"net/http"
 
 	"planets-server/internal/middleware"
+	"planets-server/internal/player"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
 	"planets-server/internal/shared/response"
 )
 
-type MeHandler struct{}
+type MeHandler struct {
+	service *player.Service
+}
 
-func NewMeHandler() *MeHandler {
-	return &MeHandler{}
+func NewMeHandler(service *player.Service) *MeHandler {
+	return &MeHandler{service: service}
 }
 
+// ServeHTTP returns the authenticated player's current record rather than the token's
+// claims, which go stale when the player is renamed or their role changes
 func (h *MeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	logger := slog.With("handler", "me")
+	logger := logger.FromContext(r.Context()).With("handler", "me")
 
 	claims := middleware.GetUserFromContext(r)
 	if claims == nil {
@@ -24,11 +29,25 @@ func (h *MeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	p, err := h.service.GetPlayerByID(r.Context(), claims.PlayerID)
+	if err != nil {
+		// The player was deleted after the token was issued; make the client log out
+		if errors.GetType(err) == errors.ErrorTypeNotFound {
+			response.Error(w, r, logger, errors.Unauthorized("player no longer exists"))
+			return
+		}
+		response.Error(w, r, logger, err)
+		return
+	}
+
 	resp := map[string]interface{}{
-		"player_id": claims.PlayerID,
-		"username":  claims.Username,
-		"email":     claims.Email,
-		"role":      claims.Role,
+		"player_id":      p.ID,
+		"username":       p.Username,
+		"email":          p.Email,
+		"role":           p.Role,
+		"display_name":   p.DisplayName,
+		"avatar_url":     p.AvatarURL,
+		"email_verified": !player.IsPlaceholderEmail(p.Email),
 	}
 
 	response.Success(w, http.StatusOK, resp)
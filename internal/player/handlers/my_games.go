@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/middleware"
+	"planets-server/internal/player"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/query"
+	"planets-server/internal/shared/response"
+)
+
+type MyGamesHandler struct {
+	service *player.Service
+}
+
+func NewMyGamesHandler(service *player.Service) *MyGamesHandler {
+	return &MyGamesHandler{service: service}
+}
+
+// ServeHTTP lists the games the authenticated player has joined, filtered by ?status=
+func (h *MyGamesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "my_games")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	params, err := query.ParseListParams(r, nil, player.GameListFilters)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	games, err := h.service.GetPlayerGames(r.Context(), claims.PlayerID, params)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, games)
+}
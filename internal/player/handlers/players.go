@@ -1,10 +1,11 @@
 package handlers
 
 import (
-	"log/slog"
 	"net/http"
 
 	"planets-server/internal/player"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/query"
 	"planets-server/internal/shared/response"
 )
 
@@ -18,16 +19,26 @@ func NewPlayersHandler(service *player.Service) *PlayersHandler {
 
 func (h *PlayersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "players")
+	logger := logger.FromContext(r.Context()).With("handler", "players")
 
-	players, err := h.service.GetAllPlayers(ctx)
+	bypassCache := r.URL.Query().Get("nocache") == "true"
+
+	params, err := query.ParseListParams(r, player.ListSortFields, player.ListFilters)
 	if err != nil {
 		response.Error(w, r, logger, err)
 		return
 	}
 
-	if players == nil {
-		players = []player.Player{}
+	page, err := query.ParsePage(r)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	players, err := h.service.GetAllPlayers(ctx, params, page, bypassCache)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
 	}
 
 	response.Success(w, http.StatusOK, players)
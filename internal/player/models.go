@@ -1,6 +1,7 @@
 package player
 
 import (
+	"planets-server/internal/shared/query"
 	"time"
 )
 
@@ -11,6 +12,23 @@ const (
 	PlayerRoleAdmin PlayerRole = "admin"
 )
 
+// ListSortFields and ListFilters are the sort and filter options accepted by the player list
+var (
+	ListSortFields = map[string]string{
+		"username":     "username",
+		"display_name": "display_name",
+		"created_at":   "created_at",
+	}
+	ListFilters = map[string]query.Filter{
+		"role": {Column: "role", Parse: query.OneOf(string(PlayerRoleUser), string(PlayerRoleAdmin))},
+	}
+)
+
+// GameListFilters are the filters accepted by a player's own game list
+var GameListFilters = map[string]query.Filter{
+	"status": {Column: "g.status", Parse: query.OneOf("scheduled", "active", "paused", "completed", "cancelled")},
+}
+
 type Player struct {
 	ID          int        `json:"id"`
 	Username    string     `json:"username"`
@@ -22,6 +40,26 @@ type Player struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// PlayerList is one page of the player list
+type PlayerList struct {
+	Players []Player `json:"players"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"has_more"`
+	query.Page
+}
+
+// PlayerGame is a game the player has joined, with how many planets they hold in it
+type PlayerGame struct {
+	GameID      int        `json:"game_id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	CurrentTurn int        `json:"current_turn"`
+	NextTurnAt  *time.Time `json:"next_turn_at"`
+	PlayerCount int        `json:"player_count"`
+	PlanetCount int        `json:"planet_count"`
+	JoinedAt    time.Time  `json:"joined_at"`
+}
+
 func (r PlayerRole) String() string {
 	return string(r)
 }
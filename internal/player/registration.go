@@ -0,0 +1,67 @@
+package player
+
+import (
+	"slices"
+	"strings"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/errors"
+)
+
+// checkRegistrationAllowed applies the registration policy to a new player's email.
+// It only runs when creating players, so existing accounts are never locked out.
+func checkRegistrationAllowed(email string) error {
+	cfg := config.GlobalConfig
+	if cfg == nil {
+		return nil
+	}
+
+	registration := cfg.Registration
+	domain := emailDomain(email)
+
+	if domain != "" && slices.Contains(registration.DeniedEmailDomains, domain) {
+		return errors.WithCode(errors.Forbidden("registration is not allowed for email domain "+domain), errors.CodeRegistrationDenied)
+	}
+
+	if registration.Mode == config.RegistrationModeAllowlist {
+		allowed := slices.Contains(registration.AllowedEmails, strings.ToLower(email)) ||
+			(domain != "" && slices.Contains(registration.AllowedEmailDomains, domain))
+		if !allowed {
+			return errors.WithCode(errors.Forbidden("registration is invite-only"), errors.CodeRegistrationDenied)
+		}
+	}
+
+	return nil
+}
+
+// CheckUnverifiedEmail applies UNVERIFIED_EMAIL_POLICY to a login whose provider reported no
+// verified email. Only the placeholder policy lets such logins through.
+func CheckUnverifiedEmail() error {
+	cfg := config.GlobalConfig
+	if cfg != nil && cfg.Registration.UnverifiedEmailPolicy == config.UnverifiedEmailPlaceholder {
+		return nil
+	}
+	return errors.WithCode(errors.Forbidden("a verified email is required to sign in"), errors.CodeEmailUnverified)
+}
+
+func emailDomain(email string) string {
+	idx := strings.LastIndex(email, "@")
+	if idx < 0 {
+		return ""
+	}
+	return strings.ToLower(email[idx+1:])
+}
+
+// placeholderEmailDomain is used for accounts whose provider gave no verified email. The
+// .invalid TLD is reserved, so these addresses can never belong to anyone.
+const placeholderEmailDomain = "placeholder.invalid"
+
+// PlaceholderEmail is the synthetic email of an account keyed on its provider identity
+func PlaceholderEmail(provider, providerUserID string) string {
+	return provider + "-" + providerUserID + "@" + placeholderEmailDomain
+}
+
+// IsPlaceholderEmail reports whether email was generated by PlaceholderEmail
+func IsPlaceholderEmail(email string) bool {
+	return emailDomain(email) == placeholderEmailDomain
+}
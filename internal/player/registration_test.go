@@ -0,0 +1,242 @@
+package player
+
+import (
+	"context"
+	"testing"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+)
+
+// useConfig installs cfg as the global config for the duration of the test
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+func TestRegistrationDeniedDomains(t *testing.T) {
+	useConfig(t, &config.Config{Registration: config.RegistrationConfig{
+		Mode:               config.RegistrationModeOpen,
+		DeniedEmailDomains: []string{"mailinator.com", "trash.example"},
+	}})
+
+	tests := []struct {
+		email   string
+		allowed bool
+	}{
+		{"someone@example.com", true},
+		{"someone@gmail.com", true},
+		{"someone@mailinator.com", false},
+		{"Someone@MAILINATOR.COM", false},
+		{"someone@trash.example", false},
+		// Only the exact domain is denied, not lookalikes or parents
+		{"someone@notmailinator.com", true},
+		{"someone@sub.trash.example", true},
+	}
+
+	for _, tt := range tests {
+		err := checkRegistrationAllowed(tt.email)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: got %v, want allowed", tt.email, err)
+		}
+		if !tt.allowed {
+			if errors.GetType(err) != errors.ErrorTypeForbidden {
+				t.Errorf("%s: got %v, want forbidden", tt.email, err)
+			} else if errors.GetCode(err) != errors.CodeRegistrationDenied {
+				t.Errorf("%s: code = %q, want %q", tt.email, errors.GetCode(err), errors.CodeRegistrationDenied)
+			}
+		}
+	}
+}
+
+func TestDeniedDomainDoesNotLockOutExistingPlayers(t *testing.T) {
+	db := dbtest.Open(t)
+	service := NewService(NewRepository(db))
+	ctx := context.Background()
+
+	existing, err := service.CreatePlayer(ctx, "before_denylist", "before@mailinator.com", "Before", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useConfig(t, &config.Config{Registration: config.RegistrationConfig{
+		Mode:               config.RegistrationModeOpen,
+		DeniedEmailDomains: []string{"mailinator.com"},
+	}})
+
+	player, err := service.FindOrCreatePlayerByOAuth(ctx, "google", "1", "before@mailinator.com", "Before", nil)
+	if err != nil {
+		t.Fatalf("existing player was refused: %v", err)
+	}
+	if player.ID != existing.ID {
+		t.Fatalf("got player %d, want the existing player %d", player.ID, existing.ID)
+	}
+
+	_, err = service.FindOrCreatePlayerByOAuth(ctx, "google", "2", "after@mailinator.com", "After", nil)
+	if errors.GetType(err) != errors.ErrorTypeForbidden {
+		t.Fatalf("new player on a denied domain: got %v, want forbidden", err)
+	}
+
+	if _, err := service.repo.FindPlayerByEmail(ctx, "after@mailinator.com"); errors.GetType(err) != errors.ErrorTypeNotFound {
+		t.Fatalf("denied player was created anyway: %v", err)
+	}
+}
+
+func TestRegistrationOpenMode(t *testing.T) {
+	useConfig(t, &config.Config{Registration: config.RegistrationConfig{
+		Mode:          config.RegistrationModeOpen,
+		AllowedEmails: []string{"invited@example.com"},
+	}})
+
+	// The allowlist is ignored until the mode asks for it
+	for _, email := range []string{"invited@example.com", "anyone@example.org"} {
+		if err := checkRegistrationAllowed(email); err != nil {
+			t.Errorf("%s: got %v, want allowed in open mode", email, err)
+		}
+	}
+}
+
+func TestRegistrationAllowlistMode(t *testing.T) {
+	useConfig(t, &config.Config{Registration: config.RegistrationConfig{
+		Mode:                config.RegistrationModeAllowlist,
+		AllowedEmails:       []string{"invited@example.com"},
+		AllowedEmailDomains: []string{"beta.example"},
+		DeniedEmailDomains:  []string{"blocked.example"},
+	}})
+
+	tests := []struct {
+		email   string
+		allowed bool
+	}{
+		{"invited@example.com", true},
+		{"Invited@Example.com", true},
+		{"tester@beta.example", true},
+		{"uninvited@example.com", false},
+		{"someone@other.example", false},
+		{"someone@sub.beta.example", false},
+		// The denylist still applies to allowlisted addresses
+		{"invited@blocked.example", false},
+	}
+
+	for _, tt := range tests {
+		err := checkRegistrationAllowed(tt.email)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: got %v, want allowed", tt.email, err)
+		}
+		if !tt.allowed && errors.GetType(err) != errors.ErrorTypeForbidden {
+			t.Errorf("%s: got %v, want forbidden", tt.email, err)
+		}
+	}
+}
+
+func TestAllowlistModeKeepsExistingPlayersAndAdmin(t *testing.T) {
+	db := dbtest.Open(t)
+	service := NewService(NewRepository(db))
+	ctx := context.Background()
+
+	existing, err := service.CreatePlayer(ctx, "early_player", "early@example.com", "Early", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useConfig(t, &config.Config{
+		Admin: config.AdminConfig{Email: "admin@example.com", Username: "admin", DisplayName: "Admin"},
+		Registration: config.RegistrationConfig{
+			Mode:          config.RegistrationModeAllowlist,
+			AllowedEmails: []string{"invited@example.com"},
+		},
+	})
+
+	player, err := service.FindOrCreatePlayerByOAuth(ctx, "google", "1", "early@example.com", "Early", nil)
+	if err != nil || player.ID != existing.ID {
+		t.Fatalf("existing player: got %v, %v; want player %d", player, err, existing.ID)
+	}
+
+	if _, err := service.FindOrCreatePlayerByOAuth(ctx, "google", "2", "invited@example.com", "Invited", nil); err != nil {
+		t.Fatalf("invited player was refused: %v", err)
+	}
+
+	admin, err := service.FindOrCreatePlayerByOAuth(ctx, "google", "3", "admin@example.com", "Admin", nil)
+	if err != nil {
+		t.Fatalf("configured admin was refused: %v", err)
+	}
+	if admin.Role != PlayerRoleAdmin {
+		t.Fatalf("admin role = %s, want admin", admin.Role)
+	}
+
+	_, err = service.FindOrCreatePlayerByOAuth(ctx, "google", "4", "stranger@example.com", "Stranger", nil)
+	if errors.GetType(err) != errors.ErrorTypeForbidden {
+		t.Fatalf("uninvited player: got %v, want forbidden", err)
+	}
+}
+
+func TestCheckUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		allowed bool
+	}{
+		{"reject policy", &config.Config{Registration: config.RegistrationConfig{UnverifiedEmailPolicy: config.UnverifiedEmailReject}}, false},
+		{"placeholder policy", &config.Config{Registration: config.RegistrationConfig{UnverifiedEmailPolicy: config.UnverifiedEmailPlaceholder}}, true},
+		{"no config", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.cfg)
+
+			err := CheckUnverifiedEmail()
+			if tt.allowed && err != nil {
+				t.Fatalf("got %v, want allowed", err)
+			}
+			if !tt.allowed && errors.GetCode(err) != errors.CodeEmailUnverified {
+				t.Fatalf("got %v, want %s", err, errors.CodeEmailUnverified)
+			}
+		})
+	}
+}
+
+func TestCreateOAuthPlayerUnverifiedEmailPolicies(t *testing.T) {
+	db := dbtest.Open(t)
+	service := NewService(NewRepository(db))
+	ctx := context.Background()
+
+	useConfig(t, &config.Config{Registration: config.RegistrationConfig{
+		Mode:                  config.RegistrationModeOpen,
+		UnverifiedEmailPolicy: config.UnverifiedEmailReject,
+	}})
+
+	_, err := service.CreateOAuthPlayer(ctx, "github", "1001", "hidden@example.com", false, "Hidden", nil)
+	if errors.GetCode(err) != errors.CodeEmailUnverified {
+		t.Fatalf("reject policy: got %v, want %s", err, errors.CodeEmailUnverified)
+	}
+	for _, email := range []string{"hidden@example.com", PlaceholderEmail("github", "1001")} {
+		if _, err := service.repo.FindPlayerByEmail(ctx, email); errors.GetType(err) != errors.ErrorTypeNotFound {
+			t.Fatalf("reject policy created a player with %s (err: %v)", email, err)
+		}
+	}
+
+	// A verified email signs in under either policy
+	verified, err := service.CreateOAuthPlayer(ctx, "github", "1002", "verified@example.com", true, "Verified", nil)
+	if err != nil || verified.Email != "verified@example.com" {
+		t.Fatalf("reject policy with a verified email: got %+v, %v", verified, err)
+	}
+
+	config.GlobalConfig.Registration.UnverifiedEmailPolicy = config.UnverifiedEmailPlaceholder
+
+	// The unverified email is never stored, so it can't be used to link accounts later
+	placeholder, err := service.CreateOAuthPlayer(ctx, "github", "1001", "hidden@example.com", false, "Hidden", nil)
+	if err != nil {
+		t.Fatalf("placeholder policy: %v", err)
+	}
+	if placeholder.Email != PlaceholderEmail("github", "1001") || !IsPlaceholderEmail(placeholder.Email) {
+		t.Fatalf("placeholder policy: email = %q, want the placeholder", placeholder.Email)
+	}
+	if placeholder.Username != "github_1001" || placeholder.DisplayName != "Hidden" {
+		t.Fatalf("placeholder policy: got %s (%s), want github_1001 (Hidden)", placeholder.Username, placeholder.DisplayName)
+	}
+}
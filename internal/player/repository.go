@@ -6,6 +6,8 @@ import (
 	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
+	"time"
 )
 
 type Repository struct {
@@ -25,20 +27,49 @@ func (r *Repository) GetPlayerCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (r *Repository) GetAllPlayers(ctx context.Context) ([]Player, error) {
-	query := `
+// CountCreatedSince returns how many players registered after since
+func (r *Repository) CountCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM players WHERE created_at > $1", since).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapInternal("failed to count new players", err)
+	}
+	return count, nil
+}
+
+// GetAllPlayers returns one page of the players matching params, with the total number of matches
+func (r *Repository) GetAllPlayers(ctx context.Context, params query.ListParams, page query.Page) (*PlayerList, error) {
+	return database.Read(ctx, r.db, func() (*PlayerList, error) {
+		return r.getAllPlayers(ctx, params, page)
+	})
+}
+
+func (r *Repository) getAllPlayers(ctx context.Context, params query.ListParams, page query.Page) (*PlayerList, error) {
+	total, err := r.CountPlayers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := params.Where(1)
+	if where != "" {
+		where = "WHERE " + where
+	}
+
+	limit, pageArgs := page.Clause(len(args) + 1)
+	sqlQuery := `
 		SELECT id, username, email, display_name, avatar_url, role, created_at, updated_at
 		FROM players
-		ORDER BY created_at DESC
-	`
+		` + where + `
+		ORDER BY ` + params.OrderBy("created_at DESC") + `, id DESC
+		` + limit
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, append(args, pageArgs...)...)
 	if err != nil {
 		return nil, errors.WrapInternal("failed to query players", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	var players []Player
+	players := []Player{}
 	for rows.Next() {
 		var player Player
 		var roleStr string
@@ -63,11 +94,89 @@ func (r *Repository) GetAllPlayers(ctx context.Context) ([]Player, error) {
 		return nil, errors.WrapInternal("error iterating players", err)
 	}
 
-	return players, nil
+	return &PlayerList{Players: players, Total: total, HasMore: page.HasMore(total), Page: page}, nil
+}
+
+// CountPlayers returns how many players match the filters in params
+func (r *Repository) CountPlayers(ctx context.Context, params query.ListParams) (int, error) {
+	where, args := params.Where(1)
+	if where != "" {
+		where = "WHERE " + where
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM players `+where, args...).Scan(&count); err != nil {
+		return 0, errors.WrapInternal("failed to count players", err)
+	}
+
+	return count, nil
+}
+
+// GetPlayerGames lists the games the player has joined, most recently joined first
+func (r *Repository) GetPlayerGames(ctx context.Context, playerID int, params query.ListParams) ([]PlayerGame, error) {
+	where, args := params.Where(2)
+	if where != "" {
+		where = "AND " + where
+	}
+
+	sqlQuery := `
+		SELECT g.id, g.name, g.status, g.current_turn, g.next_turn_at, g.player_count, gp.joined_at,
+			(SELECT COUNT(*)
+				FROM planets p
+				JOIN spatial_entities s ON s.id = p.system_id
+				WHERE s.game_id = g.id AND p.owner_id = gp.player_id) AS planet_count
+		FROM game_players gp
+		JOIN games g ON g.id = gp.game_id
+		WHERE gp.player_id = $1 ` + where + `
+		ORDER BY gp.joined_at DESC, g.id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, append([]any{playerID}, args...)...)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query player games", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	games := []PlayerGame{}
+	for rows.Next() {
+		var game PlayerGame
+		err := rows.Scan(
+			&game.GameID,
+			&game.Name,
+			&game.Status,
+			&game.CurrentTurn,
+			&game.NextTurnAt,
+			&game.PlayerCount,
+			&game.JoinedAt,
+			&game.PlanetCount,
+		)
+		if err != nil {
+			return nil, errors.WrapInternal("failed to scan player game", err)
+		}
+		games = append(games, game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating player games", err)
+	}
+
+	return games, nil
 }
 
+// bootstrapAdminLockID is the advisory lock key that serializes bootstrap admin assignment
+const bootstrapAdminLockID = 727_002
+
 func (r *Repository) CreatePlayer(ctx context.Context, username, email, displayName string, avatarURL *string) (*Player, error) {
-	role := r.determinePlayerRole(email)
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for player creation", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	role, err := r.determinePlayerRole(ctx, email, tx)
+	if err != nil {
+		return nil, err
+	}
 
 	query := `
 		INSERT INTO players (username, email, display_name, avatar_url, role)
@@ -77,7 +186,7 @@ func (r *Repository) CreatePlayer(ctx context.Context, username, email, displayN
 
 	var player Player
 	var roleStr string
-	err := r.db.QueryRowContext(ctx, query, username, email, displayName, avatarURL, role.String()).Scan(
+	err = tx.QueryRowContext(ctx, query, username, email, displayName, avatarURL, role.String()).Scan(
 		&player.ID,
 		&player.Username,
 		&player.Email,
@@ -89,19 +198,48 @@ func (r *Repository) CreatePlayer(ctx context.Context, username, email, displayN
 	)
 
 	if err != nil {
-		return nil, errors.WrapInternal("failed to create player", err)
+		return nil, database.ClassifyError("failed to create player", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit player creation", err)
 	}
 
 	player.Role = ParsePlayerRole(roleStr)
 	return &player, nil
 }
 
-func (r *Repository) determinePlayerRole(email string) PlayerRole {
+// determinePlayerRole grants admin to the configured admin email and, while fewer than
+// BOOTSTRAP_ADMIN_COUNT players exist, to every new player. The count is read under a
+// transaction-scoped advisory lock so concurrent sign-ups can't both claim the last slot.
+func (r *Repository) determinePlayerRole(ctx context.Context, email string, tx *database.Tx) (PlayerRole, error) {
 	cfg := config.GlobalConfig
-	if cfg != nil && email == cfg.Admin.Email {
-		return PlayerRoleAdmin
+	if cfg == nil {
+		return PlayerRoleUser, nil
+	}
+
+	if email == cfg.Admin.Email {
+		return PlayerRoleAdmin, nil
+	}
+
+	if cfg.Admin.BootstrapCount <= 0 {
+		return PlayerRoleUser, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", bootstrapAdminLockID); err != nil {
+		return "", errors.WrapInternal("failed to acquire bootstrap admin lock", err)
 	}
-	return PlayerRoleUser
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM players").Scan(&count); err != nil {
+		return "", errors.WrapInternal("failed to get player count", err)
+	}
+
+	if count < cfg.Admin.BootstrapCount {
+		return PlayerRoleAdmin, nil
+	}
+
+	return PlayerRoleUser, nil
 }
 
 func (r *Repository) FindPlayerByEmail(ctx context.Context, email string) (*Player, error) {
@@ -136,6 +274,12 @@ func (r *Repository) FindPlayerByEmail(ctx context.Context, email string) (*Play
 }
 
 func (r *Repository) GetPlayerByID(ctx context.Context, id int) (*Player, error) {
+	return database.Read(ctx, r.db, func() (*Player, error) {
+		return r.getPlayerByID(ctx, id)
+	})
+}
+
+func (r *Repository) getPlayerByID(ctx context.Context, id int) (*Player, error) {
 	query := `
 		SELECT id, username, email, display_name, avatar_url, role, created_at, updated_at
 		FROM players
@@ -166,6 +310,24 @@ func (r *Repository) GetPlayerByID(ctx context.Context, id int) (*Player, error)
 	return &player, nil
 }
 
+// UpdatePlayerEmail changes a player's email; an email already in use is a conflict
+func (r *Repository) UpdatePlayerEmail(ctx context.Context, playerID int, email string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE players SET email = $1, updated_at = NOW() WHERE id = $2`, email, playerID)
+	if err != nil {
+		return database.ClassifyError("failed to update player email", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapInternal("failed to get rows affected after email update", err)
+	}
+	if rowsAffected == 0 {
+		return errors.NotFoundf("player not found with id: %d", playerID)
+	}
+
+	return nil
+}
+
 func (r *Repository) UpdatePlayerRole(ctx context.Context, playerID int, role PlayerRole) error {
 	if !role.IsValid() {
 		return errors.Validationf("invalid role: %s", role)
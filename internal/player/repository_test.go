@@ -0,0 +1,160 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/query"
+)
+
+func TestGetAllPlayersOrdersSameTimestampByID(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	// NOW() is fixed for the whole transaction, so all three rows share created_at
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, name := range []string{"same_ts_a", "same_ts_b", "same_ts_c"} {
+		var id int
+		err := tx.QueryRow(
+			"INSERT INTO players (username, email, display_name) VALUES ($1, $1 || '@example.com', $1) RETURNING id",
+			name,
+		).Scan(&id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	for run := 0; run < 3; run++ {
+		list, err := repo.GetAllPlayers(ctx, query.ListParams{}, query.Page{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Players) != 3 {
+			t.Fatalf("got %d players, want 3", len(list.Players))
+		}
+		for i, player := range list.Players {
+			if want := ids[len(ids)-1-i]; player.ID != want {
+				t.Fatalf("run %d: position %d has player %d, want %d (id DESC)", run, i, player.ID, want)
+			}
+		}
+	}
+
+	// Paging through one row at a time must visit every player exactly once
+	seen := map[int]bool{}
+	for offset := 0; offset < 3; offset++ {
+		list, err := repo.GetAllPlayers(ctx, query.ListParams{}, query.Page{Limit: 1, Offset: offset})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Players) != 1 || seen[list.Players[0].ID] {
+			t.Fatalf("offset %d returned %v, a repeat or nothing", offset, list.Players)
+		}
+		seen[list.Players[0].ID] = true
+	}
+}
+
+func TestGetAllPlayersOffsetBeyondTotal(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		dbtest.CreatePlayer(t, db)
+	}
+	all, err := repo.GetAllPlayers(ctx, query.ListParams{}, query.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := repo.GetAllPlayers(ctx, query.ListParams{}, query.Page{Limit: 10, Offset: all.Total + 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The total still comes back so clients can tell they paged past the end
+	if list.Players == nil || len(list.Players) != 0 {
+		t.Fatalf("got players %v, want an empty list", list.Players)
+	}
+	if list.Total != all.Total || list.HasMore {
+		t.Fatalf("total = %d, has_more = %v; want %d and false", list.Total, list.HasMore, all.Total)
+	}
+}
+
+func TestBootstrapAdminCount(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	useConfig(t, &config.Config{Admin: config.AdminConfig{BootstrapCount: 2}})
+
+	var roles []PlayerRole
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("bootstrap_%d", i)
+		player, err := repo.CreatePlayer(ctx, name, name+"@example.com", name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roles = append(roles, player.Role)
+	}
+
+	want := []PlayerRole{PlayerRoleAdmin, PlayerRoleAdmin, PlayerRoleUser, PlayerRoleUser}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Fatalf("player %d got role %s, want %s (roles: %v)", i+1, roles[i], want[i], roles)
+		}
+	}
+}
+
+func TestBootstrapAdminCountUnderConcurrentSignups(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	const bootstrapCount = 3
+	useConfig(t, &config.Config{Admin: config.AdminConfig{BootstrapCount: bootstrapCount}})
+
+	const signups = 12
+	var wg sync.WaitGroup
+	errs := make([]error, signups)
+	start := make(chan struct{})
+
+	for i := 0; i < signups; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			name := fmt.Sprintf("concurrent_%d", i)
+			_, errs[i] = repo.CreatePlayer(ctx, name, name+"@example.com", name, nil)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("signup %d failed: %v", i, err)
+		}
+	}
+
+	var admins int
+	if err := db.QueryRow("SELECT COUNT(*) FROM players WHERE role = 'admin'").Scan(&admins); err != nil {
+		t.Fatal(err)
+	}
+	if admins != bootstrapCount {
+		t.Fatalf("%d admins after %d concurrent signups, want exactly %d", admins, signups, bootstrapCount)
+	}
+}
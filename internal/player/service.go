@@ -2,18 +2,28 @@ package player
 
 import (
 	"context"
+	"fmt"
 	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/query"
 	"strings"
+	"time"
 )
 
 type Service struct {
-	repo *Repository
+	repo      *Repository
+	listCache *listCache
 }
 
 func NewService(repo *Repository) *Service {
+	var cacheTTL time.Duration
+	if cfg := config.GlobalConfig; cfg != nil {
+		cacheTTL = cfg.Cache.PlayersTTL
+	}
+
 	return &Service{
-		repo: repo,
+		repo:      repo,
+		listCache: newListCache(cacheTTL, maxListCacheEntries),
 	}
 }
 
@@ -21,16 +31,62 @@ func (s *Service) GetPlayerCount(ctx context.Context) (int, error) {
 	return s.repo.GetPlayerCount(ctx)
 }
 
-func (s *Service) GetAllPlayers(ctx context.Context) ([]Player, error) {
-	return s.repo.GetAllPlayers(ctx)
+// CountCreatedSince returns how many players registered after since
+func (s *Service) CountCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	return s.repo.CountCreatedSince(ctx, since)
+}
+
+// GetAllPlayers serves a page of the player list from cache when possible; bypassCache forces a database read
+func (s *Service) GetAllPlayers(ctx context.Context, params query.ListParams, page query.Page, bypassCache bool) (*PlayerList, error) {
+	cacheKey := fmt.Sprintf("%s;limit=%d;offset=%d", params.Key(), page.Limit, page.Offset)
+
+	if !bypassCache {
+		if list, ok := s.listCache.get(cacheKey, time.Now()); ok {
+			return list, nil
+		}
+	}
+
+	list, err := s.repo.GetAllPlayers(ctx, params, page)
+	if err != nil {
+		return nil, err
+	}
+
+	s.listCache.set(cacheKey, list, time.Now())
+	return list, nil
 }
 
 func (s *Service) GetPlayerByID(ctx context.Context, id int) (*Player, error) {
 	return s.repo.GetPlayerByID(ctx, id)
 }
 
+// GetPlayerGames lists the games the player is in, optionally filtered by status
+func (s *Service) GetPlayerGames(ctx context.Context, playerID int, params query.ListParams) ([]PlayerGame, error) {
+	return s.repo.GetPlayerGames(ctx, playerID, params)
+}
+
 func (s *Service) CreatePlayer(ctx context.Context, username, email, displayName string, avatarURL *string) (*Player, error) {
-	return s.repo.CreatePlayer(ctx, username, email, displayName, avatarURL)
+	player, err := s.repo.CreatePlayer(ctx, username, email, displayName, avatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s.listCache.invalidate()
+	return player, nil
+}
+
+// CreateOAuthPlayer finds or creates the account for a provider login not yet linked to one.
+// A login without a verified email gets a placeholder account when UNVERIFIED_EMAIL_POLICY
+// allows it, and is rejected otherwise.
+func (s *Service) CreateOAuthPlayer(ctx context.Context, provider, providerUserID, email string, emailVerified bool, displayName string, avatarURL *string) (*Player, error) {
+	if emailVerified && email != "" {
+		return s.FindOrCreatePlayerByOAuth(ctx, provider, providerUserID, email, displayName, avatarURL)
+	}
+
+	if err := CheckUnverifiedEmail(); err != nil {
+		return nil, err
+	}
+
+	return s.CreatePlaceholderPlayer(ctx, provider, providerUserID, displayName, avatarURL)
 }
 
 func (s *Service) FindOrCreatePlayerByOAuth(ctx context.Context, provider, providerUserID, email, displayName string, avatarURL *string) (*Player, error) {
@@ -47,11 +103,18 @@ func (s *Service) FindOrCreatePlayerByOAuth(ctx context.Context, provider, provi
 			if err := s.repo.UpdatePlayerRole(ctx, player.ID, PlayerRoleAdmin); err != nil {
 				return nil, errors.WrapInternal("failed to upgrade player to admin role", err)
 			}
+			s.listCache.invalidate()
 			player.Role = PlayerRoleAdmin
 		}
 		return player, nil
 	}
 
+	if !isAdminEmail {
+		if err := checkRegistrationAllowed(email); err != nil {
+			return nil, err
+		}
+	}
+
 	username := s.generateUsernameFromEmail(email)
 
 	if isAdminEmail && cfg != nil {
@@ -59,14 +122,57 @@ func (s *Service) FindOrCreatePlayerByOAuth(ctx context.Context, provider, provi
 		displayName = cfg.Admin.DisplayName
 	}
 
-	player, err = s.repo.CreatePlayer(ctx, username, email, displayName, avatarURL)
+	player, err = s.CreatePlayer(ctx, username, email, displayName, avatarURL)
 	if err != nil {
-		return nil, errors.WrapInternal("failed to create player", err)
+		return nil, err
 	}
 
 	return player, nil
 }
 
+// maxUsernameLength matches the players.username column
+const maxUsernameLength = 50
+
+// CreatePlaceholderPlayer creates an account for a provider login that came without a
+// verified email. The account gets a placeholder email, so it is never linked to another
+// account by email, and registration rules apply to that placeholder.
+func (s *Service) CreatePlaceholderPlayer(ctx context.Context, provider, providerUserID, displayName string, avatarURL *string) (*Player, error) {
+	email := PlaceholderEmail(provider, providerUserID)
+	if err := checkRegistrationAllowed(email); err != nil {
+		return nil, err
+	}
+
+	username := provider + "_" + providerUserID
+	if len(username) > maxUsernameLength {
+		username = username[:maxUsernameLength]
+	}
+	if displayName == "" {
+		displayName = username
+	}
+
+	return s.CreatePlayer(ctx, username, email, displayName, avatarURL)
+}
+
+// ReplacePlaceholderEmail gives a placeholder account the verified email its provider now
+// reports. It returns the player unchanged when another account already uses that email.
+func (s *Service) ReplacePlaceholderEmail(ctx context.Context, player *Player, email string) (*Player, error) {
+	if !IsPlaceholderEmail(player.Email) {
+		return player, nil
+	}
+
+	if err := s.repo.UpdatePlayerEmail(ctx, player.ID, email); err != nil {
+		if errors.GetType(err) == errors.ErrorTypeConflict {
+			return player, nil
+		}
+		return nil, err
+	}
+
+	s.listCache.invalidate()
+	updated := *player
+	updated.Email = email
+	return &updated, nil
+}
+
 func (s *Service) generateUsernameFromEmail(email string) string {
 	if idx := strings.Index(email, "@"); idx > 0 {
 		return email[:idx]
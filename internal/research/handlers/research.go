@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"planets-server/internal/middleware"
+	"planets-server/internal/research"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type ResearchHandler struct {
+	service *research.Service
+}
+
+func NewResearchHandler(service *research.Service) *ResearchHandler {
+	return &ResearchHandler{service: service}
+}
+
+// ServeHTTP lists the tech tree with the player's progress on GET and starts research on POST
+func (h *ResearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "research", "method", r.Method)
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	gameIDStr := r.PathValue("id")
+	if gameIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("game ID is required"))
+		return
+	}
+
+	gameID, err := strconv.Atoi(gameIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid game ID format", err))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		techs, err := h.service.GetTechs(r.Context(), gameID, claims.PlayerID)
+		if err != nil {
+			response.Error(w, r, logger, err)
+			return
+		}
+
+		response.Success(w, http.StatusOK, techs)
+	case http.MethodPost:
+		var req research.StartRequest
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10) // 1 KB
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, logger, errors.WrapValidation("invalid JSON in request body", err))
+			return
+		}
+
+		started, err := h.service.Start(r.Context(), gameID, claims.PlayerID, req)
+		if err != nil {
+			response.Error(w, r, logger, err)
+			return
+		}
+
+		logger.Info("Research started",
+			"game_id", gameID,
+			"player_id", claims.PlayerID,
+			"tech_id", started.ID,
+			"planet_id", req.PlanetID)
+
+		response.Success(w, http.StatusCreated, started)
+	default:
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+	}
+}
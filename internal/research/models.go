@@ -0,0 +1,36 @@
+package research
+
+type Status string
+
+const (
+	StatusLocked     Status = "locked"
+	StatusAvailable  Status = "available"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// TechView is a tech as one player sees it in one game
+type TechView struct {
+	Tech
+	Status   Status `json:"status"`
+	Progress int    `json:"progress"`
+}
+
+// StartRequest starts researching a tech, paid for by the population of one of the player's planets
+type StartRequest struct {
+	TechID   string `json:"tech_id"`
+	PlanetID int    `json:"planet_id"`
+}
+
+// TurnResult is what one turn of research changed in a game
+type TurnResult struct {
+	Completed    int64
+	PlanetsGrown int64
+}
+
+// progress is a player's stored research on one tech
+type progress struct {
+	TechID    string
+	Progress  int
+	Completed bool
+}
@@ -0,0 +1,162 @@
+package research
+
+import (
+	"context"
+	"database/sql"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
+	if tx != nil {
+		return tx
+	}
+	return r.db
+}
+
+// GetProgress returns the player's research in the game, keyed by tech
+func (r *Repository) GetProgress(ctx context.Context, gameID, playerID int, tx *database.Tx) (map[string]progress, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT tech_id, progress, completed_at IS NOT NULL
+		FROM player_research
+		WHERE game_id = $1 AND player_id = $2`
+
+	rows, err := exec.QueryContext(ctx, query, gameID, playerID)
+	if err != nil {
+		return nil, database.ClassifyError("failed to get player research", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	research := make(map[string]progress)
+	for rows.Next() {
+		var p progress
+		if err := rows.Scan(&p.TechID, &p.Progress, &p.Completed); err != nil {
+			return nil, errors.WrapInternal("failed to scan player research", err)
+		}
+		research[p.TechID] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating player research", err)
+	}
+
+	return research, nil
+}
+
+// LockFundingPlanet locks the planet paying for research and returns its owner, population
+// and game, so its population can't be spent twice
+func (r *Repository) LockFundingPlanet(ctx context.Context, planetID int, tx *database.Tx) (ownerID *int, population int64, gameID int, err error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		SELECT p.owner_id, p.population, s.game_id
+		FROM planets p
+		JOIN spatial_entities s ON s.id = p.system_id
+		WHERE p.id = $1
+		FOR UPDATE OF p`
+
+	err = exec.QueryRowContext(ctx, query, planetID).Scan(&ownerID, &population, &gameID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, 0, errors.NotFoundf("planet not found with id: %d", planetID)
+		}
+		return nil, 0, 0, database.ClassifyError("failed to lock planet for research", err)
+	}
+
+	return ownerID, population, gameID, nil
+}
+
+func (r *Repository) SpendPopulation(ctx context.Context, planetID int, cost int64, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	if _, err := exec.ExecContext(ctx, `UPDATE planets SET population = population - $2 WHERE id = $1`, planetID, cost); err != nil {
+		return database.ClassifyError("failed to pay for research", err)
+	}
+
+	return nil
+}
+
+// StartResearch records a new research. A player already researching another tech in the
+// game violates the one-active-research index and surfaces as a conflict.
+func (r *Repository) StartResearch(ctx context.Context, gameID, playerID int, techID string, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `INSERT INTO player_research (game_id, player_id, tech_id) VALUES ($1, $2, $3)`
+
+	if _, err := exec.ExecContext(ctx, query, gameID, playerID, techID); err != nil {
+		return database.ClassifyError("research is already in progress", err)
+	}
+
+	return nil
+}
+
+// AdvanceResearch moves every unfinished research in the game forward by one turn, completing
+// those that reach their tech's duration. It returns the number of research completed.
+func (r *Repository) AdvanceResearch(ctx context.Context, gameID int, techIDs []string, turns []int, tx *database.Tx) (int64, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		WITH advanced AS (
+			UPDATE player_research r
+			SET progress = r.progress + 1,
+				completed_at = CASE WHEN r.progress + 1 >= t.turns THEN NOW() END
+			FROM unnest($2::text[], $3::int[]) AS t(tech_id, turns)
+			WHERE r.game_id = $1 AND r.completed_at IS NULL AND r.tech_id = t.tech_id
+			RETURNING r.completed_at
+		)
+		SELECT COUNT(*) FROM advanced WHERE completed_at IS NOT NULL`
+
+	var completed int64
+	err := exec.QueryRowContext(ctx, query, gameID, pq.Array(techIDs), pq.Array(turns)).Scan(&completed)
+	if err != nil {
+		return 0, database.ClassifyError("failed to advance research", err)
+	}
+
+	return completed, nil
+}
+
+// ApplyGrowth grows the population of every owned planet in the game by the summed growth
+// bonus of its owner's completed techs, never past max_population
+func (r *Repository) ApplyGrowth(ctx context.Context, gameID int, techIDs []string, percents []int, tx *database.Tx) (int64, error) {
+	exec := r.getExecutor(tx)
+
+	query := `
+		UPDATE planets p
+		SET population = LEAST(p.max_population, p.population + p.max_population * g.percent / 100)
+		FROM (
+			SELECT r.player_id, SUM(t.percent) AS percent
+			FROM player_research r
+			JOIN unnest($2::text[], $3::int[]) AS t(tech_id, percent) ON t.tech_id = r.tech_id
+			WHERE r.game_id = $1 AND r.completed_at IS NOT NULL
+			GROUP BY r.player_id
+		) g, spatial_entities s
+		WHERE p.owner_id = g.player_id
+			AND s.id = p.system_id
+			AND s.game_id = $1
+			AND p.population < p.max_population`
+
+	result, err := exec.ExecContext(ctx, query, gameID, pq.Array(techIDs), pq.Array(percents))
+	if err != nil {
+		return 0, database.ClassifyError("failed to apply research growth", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapInternal("failed to count grown planets", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,142 @@
+package research
+
+import (
+	"context"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+)
+
+type Service struct {
+	repo *Repository
+	tree *Tree
+}
+
+func NewService(repo *Repository, tree *Tree) *Service {
+	return &Service{repo: repo, tree: tree}
+}
+
+// GetTechs returns the whole tech tree with the player's status on each tech
+func (s *Service) GetTechs(ctx context.Context, gameID, playerID int) ([]TechView, error) {
+	research, err := s.repo.GetProgress(ctx, gameID, playerID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]TechView, len(s.tree.techs))
+	for i, tech := range s.tree.techs {
+		views[i] = TechView{Tech: tech, Status: statusOf(tech, research)}
+		if p, ok := research[tech.ID]; ok {
+			views[i].Progress = p.Progress
+		}
+	}
+
+	return views, nil
+}
+
+// Start begins researching a tech whose prerequisites the player has completed, paying its
+// cost from one of their planets in the game. Players research one tech at a time.
+func (s *Service) Start(ctx context.Context, gameID, playerID int, req StartRequest) (view *TechView, err error) {
+	tech, ok := s.tree.Get(req.TechID)
+	if !ok {
+		return nil, errors.Validationf("unknown tech: %s", req.TechID)
+	}
+
+	tx, err := s.repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, database.ClassifyError("failed to begin transaction for research", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ownerID, population, planetGameID, err := s.repo.LockFundingPlanet(ctx, req.PlanetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID == nil || *ownerID != playerID || planetGameID != gameID {
+		return nil, errors.WithCode(errors.Forbidden("research must be paid for by one of your planets in this game"), errors.CodeNotPlanetOwner)
+	}
+
+	research, err := s.repo.GetProgress(ctx, gameID, playerID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch statusOf(tech, research) {
+	case StatusLocked:
+		return nil, errors.WithCode(errors.Validationf("%s requires researching %v first", tech.ID, tech.Requires), errors.CodeTechLocked)
+	case StatusInProgress, StatusCompleted:
+		return nil, errors.WithCode(errors.Conflictf("%s has already been researched", tech.ID), errors.CodeAlreadyExists)
+	}
+
+	if population < tech.Cost {
+		return nil, errors.WithCode(errors.Validationf("insufficient population: researching %s costs %d", tech.ID, tech.Cost), errors.CodeInsufficientPopulation)
+	}
+
+	if err = s.repo.SpendPopulation(ctx, req.PlanetID, tech.Cost, tx); err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.StartResearch(ctx, gameID, playerID, tech.ID, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.WrapInternal("failed to commit research start", err)
+	}
+
+	return &TechView{Tech: tech, Status: StatusInProgress}, nil
+}
+
+// ApplyTurn advances all research in the game by one turn and then applies the bonuses of
+// completed techs, so a tech finishing this turn pays off straight away. Meant to run once
+// per turn inside the turn's transaction.
+func (s *Service) ApplyTurn(ctx context.Context, gameID int, tx *database.Tx) (*TurnResult, error) {
+	result := &TurnResult{}
+
+	ids, turns := s.tree.durations()
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	completed, err := s.repo.AdvanceResearch(ctx, gameID, ids, turns, tx)
+	if err != nil {
+		return nil, err
+	}
+	result.Completed = completed
+
+	growthIDs, percents := s.tree.growthRates()
+	if len(growthIDs) == 0 {
+		return result, nil
+	}
+
+	grown, err := s.repo.ApplyGrowth(ctx, gameID, growthIDs, percents, tx)
+	if err != nil {
+		return nil, err
+	}
+	result.PlanetsGrown = grown
+
+	return result, nil
+}
+
+func statusOf(tech Tech, research map[string]progress) Status {
+	if p, ok := research[tech.ID]; ok {
+		if p.Completed {
+			return StatusCompleted
+		}
+		return StatusInProgress
+	}
+
+	for _, required := range tech.Requires {
+		if !research[required].Completed {
+			return StatusLocked
+		}
+	}
+
+	return StatusAvailable
+}
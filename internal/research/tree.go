@@ -0,0 +1,113 @@
+package research
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed techs.json
+var defaultTree []byte
+
+// Tech is one node of the tech tree. Research costs Cost population, paid up front, and
+// completes after Turns turns once every tech in Requires is complete.
+type Tech struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Cost     int64    `json:"cost"`
+	Turns    int      `json:"turns"`
+	Requires []string `json:"requires,omitempty"`
+	Effects  Effects  `json:"effects"`
+}
+
+// Effects are the bonuses a completed tech grants its player
+type Effects struct {
+	// GrowthPercent is the share of max_population each of the player's planets gains per turn
+	GrowthPercent int `json:"growth_percent,omitempty"`
+}
+
+// Tree is a validated tech tree, in definition order
+type Tree struct {
+	techs []Tech
+	byID  map[string]Tech
+}
+
+// LoadTree reads the tech tree from the JSON file at path, or the built-in tree when path
+// is empty, so the tree can be tuned per deployment without rebuilding the server
+func LoadTree(path string) (*Tree, error) {
+	data := defaultTree
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RESEARCH_TREE_FILE: %w", err)
+		}
+		data = content
+	}
+
+	var techs []Tech
+	if err := json.Unmarshal(data, &techs); err != nil {
+		return nil, fmt.Errorf("invalid tech tree: %w", err)
+	}
+
+	return newTree(techs)
+}
+
+func newTree(techs []Tech) (*Tree, error) {
+	tree := &Tree{techs: techs, byID: make(map[string]Tech, len(techs))}
+
+	for _, tech := range techs {
+		if tech.ID == "" {
+			return nil, fmt.Errorf("invalid tech tree: every tech needs an id")
+		}
+		if _, exists := tree.byID[tech.ID]; exists {
+			return nil, fmt.Errorf("invalid tech tree: duplicate tech %q", tech.ID)
+		}
+		if tech.Cost < 0 || tech.Turns < 1 {
+			return nil, fmt.Errorf("invalid tech tree: tech %q needs a non-negative cost and at least 1 turn", tech.ID)
+		}
+		tree.byID[tech.ID] = tech
+	}
+
+	// Prerequisites must exist and, since techs may only require techs defined before
+	// them, the tree cannot contain a cycle
+	seen := make(map[string]bool, len(techs))
+	for _, tech := range techs {
+		for _, required := range tech.Requires {
+			if _, exists := tree.byID[required]; !exists {
+				return nil, fmt.Errorf("invalid tech tree: tech %q requires unknown tech %q", tech.ID, required)
+			}
+			if !seen[required] {
+				return nil, fmt.Errorf("invalid tech tree: tech %q must be defined after its prerequisite %q", tech.ID, required)
+			}
+		}
+		seen[tech.ID] = true
+	}
+
+	return tree, nil
+}
+
+func (t *Tree) Get(id string) (Tech, bool) {
+	tech, ok := t.byID[id]
+	return tech, ok
+}
+
+// durations lists the research time of every tech, for the turn processor
+func (t *Tree) durations() (ids []string, turns []int) {
+	for _, tech := range t.techs {
+		ids = append(ids, tech.ID)
+		turns = append(turns, tech.Turns)
+	}
+	return ids, turns
+}
+
+// growthRates lists the growth bonus of every tech that grants one
+func (t *Tree) growthRates() (ids []string, percents []int) {
+	for _, tech := range t.techs {
+		if tech.Effects.GrowthPercent > 0 {
+			ids = append(ids, tech.ID)
+			percents = append(percents, tech.Effects.GrowthPercent)
+		}
+	}
+	return ids, percents
+}
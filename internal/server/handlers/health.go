@@ -1,18 +1,29 @@
 package handlers
 
 import (
-	"log/slog"
+	"context"
 	"net/http"
 	"time"
 
+	"planets-server/internal/shared/buildinfo"
 	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/logger"
 	"planets-server/internal/shared/response"
 )
 
+// healthPingTimeout bounds the database ping, so a hung database reports unhealthy instead of hanging the check
+const healthPingTimeout = 2 * time.Second
+
 type HealthResponse struct {
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Database  string `json:"database"`
+	Status      string `json:"status"`
+	Timestamp   string `json:"timestamp"`
+	Database    string `json:"database"`
+	DBOk        bool   `json:"db_ok"`
+	DBLatencyMs int64  `json:"db_latency_ms"`
+	OpenConns   int    `json:"open_conns"`
+	InUse       int    `json:"in_use"`
+	Idle        int    `json:"idle"`
+	Version     string `json:"version"`
 }
 
 type HealthHandler struct {
@@ -23,21 +34,50 @@ func NewHealthHandler(db *database.DB) *HealthHandler {
 	return &HealthHandler{db: db}
 }
 
+// ServeHTTP pings the database and reports the connection pool. It answers 503 when the
+// ping fails, so load balancers can route away from the instance.
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	logger := slog.With("handler", "health")
+	logger := logger.FromContext(r.Context()).With("handler", "health")
 
-	dbStatus := "disconnected"
-	if err := h.db.Ping(); err == nil {
-		dbStatus = "connected"
-	} else {
-		logger.Warn("Database ping failed", "error", err)
-	}
+	ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	pingErr := h.db.PingContext(ctx)
+	latency := time.Since(start)
 
+	stats := h.db.Stats()
 	resp := HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Database:  dbStatus,
+		Status:      "healthy",
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Database:    "connected",
+		DBOk:        pingErr == nil,
+		DBLatencyMs: latency.Milliseconds(),
+		OpenConns:   stats.OpenConnections,
+		InUse:       stats.InUse,
+		Idle:        stats.Idle,
+		Version:     buildinfo.Get().Version,
+	}
+
+	status := http.StatusOK
+	if pingErr != nil {
+		logger.Warn("Database ping failed", "error", pingErr, "latency_ms", resp.DBLatencyMs)
+		resp.Status = "unhealthy"
+		resp.Database = "disconnected"
+		status = http.StatusServiceUnavailable
 	}
 
-	response.Success(w, http.StatusOK, resp)
+	response.Success(w, status, resp)
+}
+
+type LivenessHandler struct{}
+
+func NewLivenessHandler() *LivenessHandler {
+	return &LivenessHandler{}
+}
+
+// ServeHTTP answers as long as the process can serve requests. It never touches the
+// database, so a database outage does not get the instance restarted.
+func (h *LivenessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, http.StatusOK, map[string]string{"status": "alive"})
 }
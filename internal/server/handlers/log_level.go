@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+type LogLevelHandler struct{}
+
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+// ServeHTTP reports the active log level on GET and changes it on POST.
+// The change lasts until the next restart.
+func (h *LogLevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context()).With("handler", "log_level")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req LogLevelRequest
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10) // 1 KB
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, log, errors.WrapValidation("invalid JSON in request body", err))
+			return
+		}
+
+		previous := logger.Level()
+		if err := logger.SetLevel(req.Level); err != nil {
+			response.Error(w, r, log, errors.WrapValidation("invalid log level", err))
+			return
+		}
+
+		log.Warn("Log level changed at runtime", "from", previous, "to", req.Level)
+	default:
+		response.Error(w, r, log, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	response.Success(w, http.StatusOK, LogLevelResponse{Level: logger.Level()})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type MigrationsResponse struct {
+	Applied []string `json:"applied"`
+}
+
+type MigrationsHandler struct {
+	db *database.DB
+}
+
+func NewMigrationsHandler(db *database.DB) *MigrationsHandler {
+	return &MigrationsHandler{db: db}
+}
+
+func (h *MigrationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "run_migrations")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	applied, err := h.db.RunMigrations(r.Context())
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapInternal("failed to run migrations", err))
+		return
+	}
+
+	logger.Info("Migrations run on demand", "applied", applied)
+
+	response.Success(w, http.StatusOK, MigrationsResponse{Applied: applied})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type ReadinessResponse struct {
+	Status     string `json:"status"`
+	Database   string `json:"database"`
+	Migrations string `json:"migrations"`
+}
+
+type ReadinessHandler struct {
+	db *database.DB
+}
+
+func NewReadinessHandler(db *database.DB) *ReadinessHandler {
+	return &ReadinessHandler{db: db}
+}
+
+// ServeHTTP reports whether this instance should receive traffic. It answers 503 while the
+// database is unreachable and, when READINESS_REQUIRE_MIGRATIONS is set, while the schema
+// is behind the migrations this binary ships.
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "readiness")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	ctx := r.Context()
+	resp := ReadinessResponse{Status: "ready", Database: "connected", Migrations: "current"}
+
+	if err := h.db.PingContext(ctx); err != nil {
+		logger.Warn("Database ping failed", "error", err)
+		resp.Status = "not_ready"
+		resp.Database = "disconnected"
+		resp.Migrations = "unknown"
+		response.Success(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	current, err := h.db.SchemaCurrent(ctx)
+	switch {
+	case err != nil:
+		logger.Warn("Failed to check schema version", "error", err)
+		resp.Migrations = "unknown"
+	case !current:
+		resp.Migrations = "pending"
+	}
+
+	status := http.StatusOK
+	if resp.Migrations != "current" && config.GlobalConfig.Server.ReadinessRequiresMigrations {
+		resp.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+	}
+
+	response.Success(w, status, resp)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"planets-server/internal/game"
+	"planets-server/internal/middleware"
+	"planets-server/internal/player"
+	"planets-server/internal/shared/buildinfo"
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type PlayerSummary struct {
+	Total      int `json:"total"`
+	NewLast24h int `json:"new_last_24h"`
+}
+
+type PoolSummary struct {
+	MaxOpen        int   `json:"max_open"`
+	Open           int   `json:"open"`
+	InUse          int   `json:"in_use"`
+	Idle           int   `json:"idle"`
+	WaitCount      int64 `json:"wait_count"`
+	WaitDurationMs int64 `json:"wait_duration_ms"`
+}
+
+// RateLimitSummary counts the client buckets held by the global and public rate limiters
+type RateLimitSummary struct {
+	Clients       int `json:"clients"`
+	PublicClients int `json:"public_clients"`
+}
+
+type AdminSummaryResponse struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Players     PlayerSummary    `json:"players"`
+	Games       *game.Overview   `json:"games"`
+	Database    PoolSummary      `json:"database"`
+	RateLimit   RateLimitSummary `json:"rate_limit"`
+	Build       buildinfo.Info   `json:"build"`
+}
+
+type AdminSummaryHandler struct {
+	db            *database.DB
+	playerService *player.Service
+	gameService   *game.Service
+	rateLimiter   *middleware.RateLimiter
+	publicLimiter *middleware.RateLimiter
+}
+
+func NewAdminSummaryHandler(db *database.DB, playerService *player.Service, gameService *game.Service, rateLimiter, publicLimiter *middleware.RateLimiter) *AdminSummaryHandler {
+	return &AdminSummaryHandler{
+		db:            db,
+		playerService: playerService,
+		gameService:   gameService,
+		rateLimiter:   rateLimiter,
+		publicLimiter: publicLimiter,
+	}
+}
+
+// ServeHTTP returns a one-call overview of players, games, the connection pool, rate limiter
+// memory and the build
+func (h *AdminSummaryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "admin_summary")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	ctx := r.Context()
+	now := time.Now()
+
+	totalPlayers, err := h.playerService.GetPlayerCount(ctx)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	newPlayers, err := h.playerService.CountCreatedSince(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	games, err := h.gameService.GetOverview(ctx)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	stats := h.db.Stats()
+
+	response.Success(w, http.StatusOK, AdminSummaryResponse{
+		GeneratedAt: now.UTC(),
+		Players:     PlayerSummary{Total: totalPlayers, NewLast24h: newPlayers},
+		Games:       games,
+		Database: PoolSummary{
+			MaxOpen:        stats.MaxOpenConnections,
+			Open:           stats.OpenConnections,
+			InUse:          stats.InUse,
+			Idle:           stats.Idle,
+			WaitCount:      stats.WaitCount,
+			WaitDurationMs: stats.WaitDuration.Milliseconds(),
+		},
+		RateLimit: RateLimitSummary{
+			Clients:       h.rateLimiter.Clients(),
+			PublicClients: h.publicLimiter.Clients(),
+		},
+		Build: buildinfo.Get(),
+	})
+}
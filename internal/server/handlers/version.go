@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"planets-server/internal/shared/buildinfo"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+)
+
+type VersionHandler struct{}
+
+func NewVersionHandler() *VersionHandler {
+	return &VersionHandler{}
+}
+
+// ServeHTTP reports which build is running
+func (h *VersionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("handler", "version")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	response.Success(w, http.StatusOK, buildinfo.Get())
+}
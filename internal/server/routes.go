@@ -1,11 +1,16 @@
 package server
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
 	"planets-server/internal/auth"
 	authHandlers "planets-server/internal/auth/handlers"
+	"planets-server/internal/building"
+	buildingHandlers "planets-server/internal/building/handlers"
+	"planets-server/internal/events"
+	eventsHandlers "planets-server/internal/events/handlers"
 	"planets-server/internal/game"
 	gameHandlers "planets-server/internal/game/handlers"
 	"planets-server/internal/middleware"
@@ -13,33 +18,55 @@ import (
 	planetHandlers "planets-server/internal/planet/handlers"
 	"planets-server/internal/player"
 	playerHandler "planets-server/internal/player/handlers"
+	"planets-server/internal/research"
+	researchHandlers "planets-server/internal/research/handlers"
 	serverHandlers "planets-server/internal/server/handlers"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/spatial"
 	spatialHandlers "planets-server/internal/spatial/handlers"
+	"planets-server/internal/visibility"
+	visibilityHandlers "planets-server/internal/visibility/handlers"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// EventStreamRoute serves a game's live events. Streams stay open as long as the client
+// listens, so they are capped by the event hub instead of the concurrency limiter.
+const EventStreamRoute = "/api/games/{id}/events"
+
 type Routes struct {
-	db             *database.DB
-	playerService  *player.Service
-	authService    *auth.Service
-	gameService    *game.Service
-	spatialService *spatial.Service
-	planetService  *planet.Service
-	oauthConfig    *auth.OAuthConfig
-	logger         *slog.Logger
+	db                *database.DB
+	playerService     *player.Service
+	authService       *auth.Service
+	gameService       *game.Service
+	spatialService    *spatial.Service
+	planetService     *planet.Service
+	visibilityService *visibility.Service
+	buildingService   *building.Service
+	researchService   *research.Service
+	eventHub          *events.Hub
+	oauthConfig       *auth.OAuthConfig
+	logger            *slog.Logger
+	rateLimiter       *middleware.RateLimiter
+	publicRateLimiter *middleware.RateLimiter
 }
 
-func NewRoutes(db *database.DB, playerService *player.Service, authService *auth.Service, gameService *game.Service, spatialService *spatial.Service, planetService *planet.Service, oauthConfig *auth.OAuthConfig, logger *slog.Logger) *Routes {
+func NewRoutes(db *database.DB, playerService *player.Service, authService *auth.Service, gameService *game.Service, spatialService *spatial.Service, planetService *planet.Service, visibilityService *visibility.Service, buildingService *building.Service, researchService *research.Service, eventHub *events.Hub, oauthConfig *auth.OAuthConfig, rateLimiter *middleware.RateLimiter, logger *slog.Logger) *Routes {
 	return &Routes{
-		db:             db,
-		playerService:  playerService,
-		authService:    authService,
-		gameService:    gameService,
-		spatialService: spatialService,
-		planetService:  planetService,
-		oauthConfig:    oauthConfig,
-		logger:         logger,
+		db:                db,
+		playerService:     playerService,
+		authService:       authService,
+		gameService:       gameService,
+		spatialService:    spatialService,
+		planetService:     planetService,
+		visibilityService: visibilityService,
+		buildingService:   buildingService,
+		researchService:   researchService,
+		eventHub:          eventHub,
+		oauthConfig:       oauthConfig,
+		rateLimiter:       rateLimiter,
+		logger:            logger,
 	}
 }
 
@@ -50,15 +77,39 @@ func (r *Routes) Setup() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	healthHandler := serverHandlers.NewHealthHandler(r.db)
+	readinessHandler := serverHandlers.NewReadinessHandler(r.db)
+	livenessHandler := serverHandlers.NewLivenessHandler()
+	versionHandler := serverHandlers.NewVersionHandler()
+	migrationsHandler := serverHandlers.NewMigrationsHandler(r.db)
+	logLevelHandler := serverHandlers.NewLogLevelHandler()
 	playersHandler := playerHandler.NewPlayersHandler(r.playerService)
-	meHandler := playerHandler.NewMeHandler()
-	logoutHandler := authHandlers.NewLogoutHandler()
+	meHandler := playerHandler.NewMeHandler(r.playerService)
+	myGamesHandler := playerHandler.NewMyGamesHandler(r.playerService)
+	logoutHandler := authHandlers.NewLogoutHandler(r.authService)
+	refreshHandler := authHandlers.NewRefreshHandler(r.authService)
+	providersHandler := authHandlers.NewProvidersHandler(r.oauthConfig)
 
 	gameHandler := gameHandlers.NewGameHandler(r.gameService)
 	spatialHandler := spatialHandlers.NewSpatialHandler(r.spatialService)
 	planetHandler := planetHandlers.NewPlanetHandler(r.planetService)
+	visibilityHandler := visibilityHandlers.NewVisibilityHandler(r.visibilityService)
+	buildingHandler := buildingHandlers.NewBuildingHandler(r.buildingService)
+	researchHandler := researchHandlers.NewResearchHandler(r.researchService)
+	eventStreamHandler := eventsHandlers.NewStreamHandler(r.eventHub)
 	gameAccess := middleware.NewGameAccessMiddleware(r.db)
 
+	// Public endpoints get a stricter limiter on top of the global one
+	r.publicRateLimiter = middleware.NewRateLimiter(middleware.RateLimitConfig{
+		RequestsPerSecond: config.GlobalConfig.RateLimit.PublicRequestsPerSecond,
+		BurstSize:         config.GlobalConfig.RateLimit.PublicBurstSize,
+		TrustedProxies:    config.GlobalConfig.RateLimit.TrustedProxies,
+		AdminBypass:       config.GlobalConfig.RateLimit.AdminBypass,
+		MaxClients:        config.GlobalConfig.RateLimit.MaxClients,
+		CleanupInterval:   config.GlobalConfig.RateLimit.CleanupInterval,
+		ClientTTL:         config.GlobalConfig.RateLimit.ClientTTL,
+	})
+	adminSummaryHandler := serverHandlers.NewAdminSummaryHandler(r.db, r.playerService, r.gameService, r.rateLimiter, r.publicRateLimiter)
+
 	googleAuthHandler := authHandlers.NewOAuthHandler(
 		r.oauthConfig.GoogleProvider,
 		r.playerService,
@@ -78,21 +129,57 @@ func (r *Routes) Setup() *http.ServeMux {
 		r.oauthConfig.DiscordConfigured,
 	)
 
+	// Public endpoints (no authentication)
+	mux.Handle("/api/auth/providers", providersHandler)
+	mux.Handle("/api/server/version", r.publicRateLimiter.Middleware(versionHandler))
+	mux.Handle("/api/server/health", r.publicRateLimiter.Middleware(healthHandler))
+	// Orchestrator probes stay off the public limiter so a busy server is not restarted
+	mux.Handle("/readyz", readinessHandler)
+	mux.Handle("/api/server/live", livenessHandler)
+	mux.Handle("/api/games/{id}/public-stats", r.publicRateLimiter.Middleware(http.HandlerFunc(gameHandler.GetPublicGameStats)))
+
 	// Protected endpoints (authenticated users)
 	mux.Handle("/api/players", middleware.JWTMiddleware(playersHandler))
 	mux.Handle("/api/games", middleware.JWTMiddleware(http.HandlerFunc(gameHandler.GetGames)))
 	mux.Handle("/api/games/{id}/stats", middleware.JWTMiddleware(http.HandlerFunc(gameHandler.GetGameStats)))
+	mux.Handle("/api/games/{id}/join", middleware.RequireVerifiedEmail(http.HandlerFunc(gameHandler.JoinGame)))
 	mux.Handle("/api/players/me", middleware.JWTMiddleware(meHandler))
+	mux.Handle("/api/players/me/games", middleware.JWTMiddleware(myGamesHandler))
 
 	// Spatial browsing endpoints (authenticated + game access)
 	mux.Handle("/api/spatial/{id}/children", gameAccess.Require(http.HandlerFunc(spatialHandler.GetChildren)))
 	mux.Handle("/api/spatial/{id}/ancestors", gameAccess.Require(http.HandlerFunc(spatialHandler.GetAncestors)))
+	mux.Handle("/api/spatial/{id}/at", gameAccess.Require(http.HandlerFunc(spatialHandler.GetEntityAtCoord)))
 	mux.Handle("/api/spatial/{id}/planets", gameAccess.Require(http.HandlerFunc(planetHandler.GetBySystemID)))
+	mux.Handle("/api/games/{id}/turn-timer", gameAccess.RequireGame(http.HandlerFunc(gameHandler.GetTurnTimer)))
+	mux.Handle("/api/games/{id}/turns/{n}", gameAccess.RequireGame(http.HandlerFunc(gameHandler.GetTurnLog)))
+	mux.Handle("/api/games/{id}/planets/mine", gameAccess.RequireGame(http.HandlerFunc(planetHandler.GetMine)))
+	mux.Handle("/api/games/{id}/research", gameAccess.RequireGame(researchHandler))
+	mux.Handle(EventStreamRoute, gameAccess.RequireGame(eventStreamHandler))
+
+	// Game management, open to global admins and the game's own game master
+	mux.Handle("/api/games/{id}/settings", gameAccess.RequireGameGM(http.HandlerFunc(gameHandler.UpdateSettings)))
+	mux.Handle("/api/games/{id}/transfer-gm", gameAccess.RequireGameGM(http.HandlerFunc(gameHandler.TransferGameMaster)))
+	mux.Handle("/api/planets/{id}/history", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.GetOwnershipHistory)))
+	mux.Handle("/api/planets/{id}/fortify", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.Fortify)))
+	mux.Handle("/api/planets/{id}/terraform", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.Terraform)))
+	mux.Handle("/api/planets/{id}/transfer", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.Transfer)))
+	mux.Handle("/api/planets/{id}/colonize", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.Colonize)))
+	mux.Handle("/api/planets/{id}/abandon", gameAccess.RequirePlanet(http.HandlerFunc(planetHandler.Abandon)))
+	mux.Handle("/api/planets/{id}/buildings", gameAccess.RequirePlanet(buildingHandler))
+	mux.Handle("/api/systems/{id}/explore", gameAccess.Require(http.HandlerFunc(visibilityHandler.Explore)))
+	mux.Handle("/api/systems/{id}/planets", gameAccess.Require(http.HandlerFunc(planetHandler.GetBySystemID)))
 
 	// Admin-only endpoints (authenticated + admin role)
-	mux.Handle("/api/server/health", middleware.RequireAdmin(healthHandler))
 	mux.Handle("/api/games/create", middleware.RequireAdmin(http.HandlerFunc(gameHandler.CreateGame)))
 	mux.Handle("/api/games/{id}/delete", middleware.RequireAdmin(http.HandlerFunc(gameHandler.DeleteGame)))
+	mux.Handle("/api/games/{id}/galaxies", middleware.RequireAdmin(http.HandlerFunc(gameHandler.AddGalaxy)))
+	mux.Handle("/api/games/import", middleware.RequireAdmin(http.HandlerFunc(gameHandler.ImportGame)))
+	mux.Handle("/api/admin/migrations/run", middleware.RequireAdminOrInternalToken(migrationsHandler))
+	mux.Handle("/api/admin/log-level", middleware.RequireAdmin(logLevelHandler))
+	mux.Handle("/api/admin/summary", middleware.RequireAdmin(adminSummaryHandler))
+	mux.Handle("/metrics", middleware.RequireAdminOrInternalToken(promhttp.Handler()))
+	mux.Handle("/api/admin/games/reconcile-counts", middleware.RequireAdminOrInternalToken(http.HandlerFunc(gameHandler.ReconcileCounts)))
 
 	// OAuth endpoints
 	mux.Handle("/auth/google", http.HandlerFunc(googleAuthHandler.HandleAuth))
@@ -102,13 +189,24 @@ func (r *Routes) Setup() *http.ServeMux {
 	mux.Handle("/auth/discord", http.HandlerFunc(discordAuthHandler.HandleAuth))
 	mux.Handle("/auth/discord/callback", http.HandlerFunc(discordAuthHandler.HandleCallback))
 	mux.Handle("/auth/logout", logoutHandler)
+	mux.Handle("/auth/refresh", refreshHandler)
 
 	logger.Info("Routes configured successfully",
-		"protected_endpoints", []string{"/api/players", "/api/games", "/api/games/{id}/stats", "/api/players/me"},
-		"spatial_endpoints", []string{"/api/spatial/{id}/children", "/api/spatial/{id}/ancestors", "/api/spatial/{id}/planets"},
-		"admin_endpoints", []string{"/api/server/health", "/api/games/create", "/api/games/{id}/delete"},
-		"auth_endpoints", []string{"/auth/google", "/auth/github", "/auth/discord", "/auth/logout"},
+		"public_endpoints", []string{"/readyz", "/api/server/live", "/api/server/health", "/api/auth/providers", "/api/server/version", "/api/games/{id}/public-stats"},
+		"protected_endpoints", []string{"/api/players", "/api/games", "/api/games/{id}/stats", "/api/games/{id}/join", "/api/players/me", "/api/players/me/games"},
+		"spatial_endpoints", []string{"/api/spatial/{id}/children", "/api/spatial/{id}/ancestors", "/api/spatial/{id}/planets", "/api/games/{id}/turn-timer", "/api/games/{id}/turns/{n}", "/api/games/{id}/planets/mine", "/api/games/{id}/research", "/api/games/{id}/events", "/api/planets/{id}/history", "/api/planets/{id}/fortify", "/api/planets/{id}/terraform", "/api/planets/{id}/transfer", "/api/planets/{id}/colonize", "/api/planets/{id}/abandon", "/api/planets/{id}/buildings", "/api/systems/{id}/explore", "/api/systems/{id}/planets"},
+		"game_master_endpoints", []string{"/api/games/{id}/settings", "/api/games/{id}/transfer-gm"},
+		"admin_endpoints", []string{"/api/games/create", "/api/games/{id}/delete", "/api/games/{id}/galaxies", "/api/admin/migrations/run", "/api/admin/summary", "/api/admin/games/reconcile-counts", "/metrics"},
+		"auth_endpoints", []string{"/auth/google", "/auth/github", "/auth/discord", "/auth/logout", "/auth/refresh"},
 	)
 
 	return mux
 }
+
+// Close stops the background work of the middleware created by Setup
+func (r *Routes) Close(ctx context.Context) error {
+	if r.publicRateLimiter != nil {
+		return r.publicRateLimiter.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Info identifies the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+var current = Info{
+	Version:   "dev",
+	Commit:    "dev",
+	BuildTime: "dev",
+	GoVersion: runtime.Version(),
+}
+
+// Init records the values stamped into the binary with -ldflags. Empty values stay "dev",
+// except the commit, which falls back to the VCS revision the Go toolchain embeds.
+func Init(version, commit, buildTime string) {
+	if version != "" {
+		current.Version = version
+	}
+	if buildTime != "" {
+		current.BuildTime = buildTime
+	}
+
+	if commit != "" {
+		current.Commit = commit
+	} else if revision := vcsRevision(); revision != "" {
+		current.Commit = revision
+	}
+}
+
+// Get returns the running build's info
+func Get() Info {
+	return current
+}
+
+func vcsRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
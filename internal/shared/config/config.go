@@ -3,24 +3,31 @@ package config
 import (
 	"fmt"
 	"net/http"
+	"net/netip"
+	"os"
 	"planets-server/internal/shared/utils"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	Redis     RedisConfig
-	Auth      AuthConfig
-	OAuth     OAuthConfig
-	Frontend  FrontendConfig
-	Logging   LoggingConfig
-	RateLimit RateLimitConfig
-	Game      GameConfig
-	Admin     AdminConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	Auth         AuthConfig
+	OAuth        OAuthConfig
+	Frontend     FrontendConfig
+	Logging      LoggingConfig
+	RateLimit    RateLimitConfig
+	Game         GameConfig
+	Admin        AdminConfig
+	Cache        CacheConfig
+	Registration RegistrationConfig
+	Events       EventsConfig
 }
 
 type RedisConfig struct {
@@ -38,6 +45,13 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	PrettyJSON   bool
+	// ResponseEnvelope wraps every success body as {"data": ..., "meta": {...}}
+	ResponseEnvelope bool
+	RunMigrations    bool
+	MigrateDownSteps int
+	// ReadinessRequiresMigrations fails readiness while migrations are pending
+	ReadinessRequiresMigrations bool
 }
 
 type DatabaseConfig struct {
@@ -50,15 +64,36 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	CopyThreshold   int
+	ReadRetries     int
 }
 
 type AuthConfig struct {
-	JWTSecret       string
-	TokenExpiration time.Duration
-	CookieSecure    bool
-	CookieSameSite  http.SameSite
+	JWTSecret              string
+	AccessTokenExpiration  time.Duration
+	RefreshTokenExpiration time.Duration
+	CookieName             string
+	CookiePath             string
+	CookieSecure           bool
+	CookieSameSite         http.SameSite
+	InternalToken          string
+	RequireProvider        bool
+	// TokenSource is where access tokens are accepted from: the cookie, an Authorization header, or both
+	TokenSource string
+	// CallbackReturnsTokens makes the OAuth callback answer with the tokens as JSON instead of redirecting
+	CallbackReturnsTokens bool
+	// StrictStateUserAgent rejects OAuth callbacks from a different User-Agent than the login started with
+	StrictStateUserAgent bool
+	// TokenPruneInterval is how often expired refresh tokens and revocation entries are deleted
+	TokenPruneInterval time.Duration
 }
 
+const (
+	TokenSourceCookie = "cookie"
+	TokenSourceHeader = "header"
+	TokenSourceBoth   = "both"
+)
+
 type OAuthConfig struct {
 	Google  GoogleOAuthConfig
 	GitHub  GitHubOAuthConfig
@@ -70,6 +105,7 @@ type GoogleOAuthConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	PKCE         bool
 }
 
 type GitHubOAuthConfig struct {
@@ -77,6 +113,7 @@ type GitHubOAuthConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	PKCE         bool
 }
 
 type DiscordOAuthConfig struct {
@@ -84,6 +121,7 @@ type DiscordOAuthConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	PKCE         bool
 }
 
 type FrontendConfig struct {
@@ -93,30 +131,138 @@ type FrontendConfig struct {
 }
 
 type LoggingConfig struct {
-	Level      string
+	Level string
+	// Format is the explicit LOG_FORMAT, empty when unset
+	Format string
+	// JSONFormat is the resolved choice: Format when set, otherwise JSON in production
 	JSONFormat bool
+	// AccessLogExcludePaths are only access-logged when they fail with a server error
+	AccessLogExcludePaths []string
 }
 
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
 type RateLimitConfig struct {
-	RequestsPerSecond float64
-	BurstSize         int
-	TrustProxy        bool
+	RequestsPerSecond       float64
+	BurstSize               int
+	TrustedProxies          []string
+	PublicRequestsPerSecond float64
+	PublicBurstSize         int
+	AdminBypass             bool
+	MaxConcurrent           int
+	MaxConcurrentPerIP      int
+	MaxClients              int
+	CleanupInterval         time.Duration
+	ClientTTL               time.Duration
 }
 
 type GameConfig struct {
-	MaxPlayers          int
-	TurnIntervalHours   int
-	GalaxyCount         int
-	SectorsPerGalaxy    int
-	SystemsPerSector    int
-	MinPlanetsPerSystem int
-	MaxPlanetsPerSystem int
+	MinPlayers            int
+	MaxPlayers            int
+	TurnIntervalHours     int
+	GalaxyCount           int
+	SectorsPerGalaxy      int
+	SystemsPerSector      int
+	MinPlanetsPerSystem   int
+	MaxPlanetsPerSystem   int
+	PopulationPerSize     int64
+	PopulationVariance    int
+	PopulationDecay       int
+	SpawnSystemsPerSector int
+	SchedulerInterval     time.Duration
+	// TurnSchedulerInterval is how often active games are checked for due turns
+	TurnSchedulerInterval time.Duration
+	LobbyGracePeriod      time.Duration
+	MaxGenerationTime     time.Duration
+	ResearchTreeFile      string
+	// SpawnStrategy decides where joining players get their home planet
+	SpawnStrategy string
+	// TerraformCosts is the population spent to turn a planet of one type into another, by
+	// source and then target type. Transitions that are not listed cannot be made.
+	TerraformCosts map[string]map[string]int64
+}
+
+// Values of SpawnStrategy: as far as possible from other players, or any free planet
+const (
+	SpawnStrategySpread = "spread"
+	SpawnStrategyRandom = "random"
+)
+
+// defaultTerraformCosts lets barren, ice and volcanic planets be made habitable. Gas giants
+// cannot be terraformed at all.
+const defaultTerraformCosts = "barren>terrestrial:100000,ice>barren:30000,ice>terrestrial:150000,volcanic>barren:40000,volcanic>terrestrial:200000"
+
+// planetTypes are the values of the planet_type database enum
+var planetTypes = []string{"barren", "terrestrial", "gas_giant", "ice", "volcanic"}
+
+// DefaultTerraformCosts returns the terraforming costs used when TERRAFORM_COSTS is not set
+func DefaultTerraformCosts() map[string]map[string]int64 {
+	costs, _ := parseTerraformCosts(defaultTerraformCosts)
+	return costs
+}
+
+type RegistrationConfig struct {
+	Mode                string
+	AllowedEmails       []string
+	AllowedEmailDomains []string
+	DeniedEmailDomains  []string
+	// UnverifiedEmailPolicy decides what happens to logins whose provider has no verified email
+	UnverifiedEmailPolicy string
+}
+
+const (
+	RegistrationModeOpen      = "open"
+	RegistrationModeAllowlist = "allowlist"
+)
+
+// Values of UnverifiedEmailPolicy: reject the login, or create an account with a
+// placeholder email that must be replaced by a verified one before joining games
+const (
+	UnverifiedEmailReject      = "reject"
+	UnverifiedEmailPlaceholder = "placeholder"
+)
+
+type EventsConfig struct {
+	Publisher    string
+	Stream       string
+	StreamMaxLen int64
+	// SubscriberBuffer is how many events a live update stream may fall behind by
+	SubscriberBuffer int
+	// SlowSubscriberPolicy is what happens to a stream whose buffer is full, see EventsSlowSubscriber*
+	SlowSubscriberPolicy string
+	// MaxSubscribers caps open live update streams across all games, 0 for no cap
+	MaxSubscribers int
+	// MaxSubscribersPerGame caps open live update streams of a single game, 0 for no cap
+	MaxSubscribersPerGame int
+}
+
+const (
+	// EventsSlowSubscriberDrop drops events a slow stream has no room for
+	EventsSlowSubscriberDrop = "drop"
+	// EventsSlowSubscriberDisconnect closes a slow stream so its client reconnects
+	EventsSlowSubscriberDisconnect = "disconnect"
+)
+
+const (
+	EventsPublisherNone  = "none"
+	EventsPublisherRedis = "redis"
+)
+
+type CacheConfig struct {
+	PlayersTTL      time.Duration
+	ImmutableMaxAge time.Duration
+	// RevocationTTL is how long a token found not revoked skips the revocation lookup
+	RevocationTTL time.Duration
 }
 
 type AdminConfig struct {
-	Email       string
-	Username    string
-	DisplayName string
+	Email          string
+	Username       string
+	DisplayName    string
+	BootstrapCount int
 }
 
 var GlobalConfig *Config
@@ -140,17 +286,30 @@ func Init() error {
 }
 
 func load() (*Config, error) {
+	registration, err := loadRegistrationConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := loadGameConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		Server:    loadServerConfig(),
-		Database:  loadDatabaseConfig(),
-		Redis:     loadRedisConfig(),
-		Auth:      loadAuthConfig(),
-		OAuth:     loadOAuthConfig(),
-		Frontend:  loadFrontendConfig(),
-		Logging:   loadLoggingConfig(),
-		RateLimit: loadRateLimitConfig(),
-		Game:      loadGameConfig(),
-		Admin:     loadAdminConfig(),
+		Server:       loadServerConfig(),
+		Database:     loadDatabaseConfig(),
+		Redis:        loadRedisConfig(),
+		Auth:         loadAuthConfig(),
+		OAuth:        loadOAuthConfig(),
+		Frontend:     loadFrontendConfig(),
+		Logging:      loadLoggingConfig(),
+		RateLimit:    loadRateLimitConfig(),
+		Game:         game,
+		Admin:        loadAdminConfig(),
+		Cache:        loadCacheConfig(),
+		Events:       loadEventsConfig(),
+		Registration: registration,
 	}
 
 	return config, nil
@@ -168,17 +327,29 @@ func loadRedisConfig() RedisConfig {
 }
 
 func loadServerConfig() ServerConfig {
+	environment := utils.GetEnv("ENVIRONMENT", "development")
+	prettyJSON := utils.GetEnv("PRETTY_JSON", strconv.FormatBool(environment == "development")) == "true"
+	migrateDownSteps, _ := strconv.Atoi(utils.GetEnv("MIGRATE_DOWN_STEPS", "0"))
+
 	return ServerConfig{
-		Port:         utils.GetEnv("SERVER_PORT", "8080"),
-		URL:          utils.GetEnv("SERVER_URL", "http://localhost:8080"),
-		Environment:  utils.GetEnv("ENVIRONMENT", "development"),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Port:                        utils.GetEnv("SERVER_PORT", "8080"),
+		URL:                         utils.GetEnv("SERVER_URL", "http://localhost:8080"),
+		Environment:                 environment,
+		ReadTimeout:                 15 * time.Second,
+		WriteTimeout:                15 * time.Second,
+		IdleTimeout:                 60 * time.Second,
+		PrettyJSON:                  prettyJSON,
+		ResponseEnvelope:            utils.GetEnv("RESPONSE_ENVELOPE", "false") == "true",
+		RunMigrations:               utils.GetEnv("RUN_MIGRATIONS", "true") == "true",
+		MigrateDownSteps:            migrateDownSteps,
+		ReadinessRequiresMigrations: utils.GetEnv("READINESS_REQUIRE_MIGRATIONS", "true") == "true",
 	}
 }
 
 func loadDatabaseConfig() DatabaseConfig {
+	copyThreshold, _ := strconv.Atoi(utils.GetEnv("DB_COPY_THRESHOLD", "20000"))
+	readRetries, _ := strconv.Atoi(utils.GetEnv("DB_READ_RETRIES", "1"))
+
 	return DatabaseConfig{
 		Host:            utils.GetEnv("DB_HOST", "localhost"),
 		Port:            utils.GetEnv("DB_PORT", "5432"),
@@ -189,11 +360,15 @@ func loadDatabaseConfig() DatabaseConfig {
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
+		CopyThreshold:   copyThreshold,
+		ReadRetries:     readRetries,
 	}
 }
 
 func loadAuthConfig() AuthConfig {
-	tokenExpiration, _ := strconv.Atoi(utils.GetEnv("JWT_EXPIRATION_HOURS", "24"))
+	accessExpirationMinutes, _ := strconv.Atoi(utils.GetEnv("JWT_ACCESS_EXPIRATION_MINUTES", "15"))
+	refreshExpirationDays, _ := strconv.Atoi(utils.GetEnv("JWT_REFRESH_EXPIRATION_DAYS", "7"))
+	tokenPruneIntervalMinutes, _ := strconv.Atoi(utils.GetEnv("AUTH_TOKEN_PRUNE_INTERVAL_MINUTES", "60"))
 
 	environment := utils.GetEnv("ENVIRONMENT", "development")
 	cookieSecure := environment == "production"
@@ -204,10 +379,19 @@ func loadAuthConfig() AuthConfig {
 	}
 
 	return AuthConfig{
-		JWTSecret:       utils.GetEnv("JWT_SECRET", ""),
-		TokenExpiration: time.Duration(tokenExpiration) * time.Hour,
-		CookieSecure:    cookieSecure,
-		CookieSameSite:  cookieSameSite,
+		JWTSecret:              utils.GetEnv("JWT_SECRET", ""),
+		AccessTokenExpiration:  time.Duration(accessExpirationMinutes) * time.Minute,
+		RefreshTokenExpiration: time.Duration(refreshExpirationDays) * 24 * time.Hour,
+		CookieName:             utils.GetEnv("AUTH_COOKIE_NAME", "auth_token"),
+		CookiePath:             utils.GetEnv("AUTH_COOKIE_PATH", "/"),
+		CookieSecure:           cookieSecure,
+		CookieSameSite:         cookieSameSite,
+		InternalToken:          utils.GetEnv("INTERNAL_TOKEN", ""),
+		RequireProvider:        utils.GetEnv("REQUIRE_AUTH_PROVIDER", "false") == "true",
+		TokenSource:            utils.GetEnv("AUTH_TOKEN_SOURCE", TokenSourceCookie),
+		CallbackReturnsTokens:  utils.GetEnv("AUTH_CALLBACK_RETURNS_TOKENS", "false") == "true",
+		StrictStateUserAgent:   utils.GetEnv("OAUTH_STATE_STRICT_USER_AGENT", "false") == "true",
+		TokenPruneInterval:     time.Duration(tokenPruneIntervalMinutes) * time.Minute,
 	}
 }
 
@@ -220,18 +404,21 @@ func loadOAuthConfig() OAuthConfig {
 			ClientSecret: utils.GetEnv("GOOGLE_CLIENT_SECRET", ""),
 			RedirectURL:  serverURL + "/auth/google/callback",
 			Scopes:       []string{"openid", "profile", "email"},
+			PKCE:         utils.GetEnv("GOOGLE_PKCE", "true") == "true",
 		},
 		GitHub: GitHubOAuthConfig{
 			ClientID:     utils.GetEnv("GITHUB_CLIENT_ID", ""),
 			ClientSecret: utils.GetEnv("GITHUB_CLIENT_SECRET", ""),
 			RedirectURL:  serverURL + "/auth/github/callback",
 			Scopes:       []string{"user:email"},
+			PKCE:         utils.GetEnv("GITHUB_PKCE", "false") == "true",
 		},
 		Discord: DiscordOAuthConfig{
 			ClientID:     utils.GetEnv("DISCORD_CLIENT_ID", ""),
 			ClientSecret: utils.GetEnv("DISCORD_CLIENT_SECRET", ""),
 			RedirectURL:  serverURL + "/auth/discord/callback",
 			Scopes:       []string{"identify", "email"},
+			PKCE:         utils.GetEnv("DISCORD_PKCE", "true") == "true",
 		},
 	}
 }
@@ -249,23 +436,46 @@ func loadFrontendConfig() FrontendConfig {
 func loadLoggingConfig() LoggingConfig {
 	environment := utils.GetEnv("ENVIRONMENT", "development")
 
+	format := utils.GetEnv("LOG_FORMAT", "")
+
+	jsonFormat := environment == "production"
+	if format != "" {
+		jsonFormat = format == LogFormatJSON
+	}
+
 	return LoggingConfig{
-		Level:      utils.GetEnv("LOG_LEVEL", "debug"),
-		JSONFormat: environment == "production",
+		Level:                 utils.GetEnv("LOG_LEVEL", "debug"),
+		Format:                format,
+		JSONFormat:            jsonFormat,
+		AccessLogExcludePaths: parseCaseSensitiveList(utils.GetEnv("ACCESS_LOG_EXCLUDE_PATHS", "/api/server/health,/api/server/live,/healthz,/readyz,/metrics")),
 	}
 }
 
 func loadRateLimitConfig() RateLimitConfig {
-	environment := utils.GetEnv("ENVIRONMENT", "development")
+	publicRequestsPerSecond, _ := strconv.ParseFloat(utils.GetEnv("PUBLIC_RATE_LIMIT_RPS", "1"), 64)
+	publicBurstSize, _ := strconv.Atoi(utils.GetEnv("PUBLIC_RATE_LIMIT_BURST", "5"))
+	maxConcurrent, _ := strconv.Atoi(utils.GetEnv("MAX_CONCURRENT_REQUESTS", "1000"))
+	maxConcurrentPerIP, _ := strconv.Atoi(utils.GetEnv("MAX_CONCURRENT_REQUESTS_PER_IP", "20"))
+	maxClients, _ := strconv.Atoi(utils.GetEnv("RATE_LIMIT_MAX_CLIENTS", "100000"))
+	cleanupIntervalSeconds, _ := strconv.Atoi(utils.GetEnv("RATE_LIMIT_CLEANUP_INTERVAL_SECONDS", "60"))
+	clientTTLSeconds, _ := strconv.Atoi(utils.GetEnv("RATE_LIMIT_CLIENT_TTL_SECONDS", "180"))
 
 	return RateLimitConfig{
-		RequestsPerSecond: 10,
-		BurstSize:         20,
-		TrustProxy:        environment == "production",
+		RequestsPerSecond:       10,
+		BurstSize:               20,
+		TrustedProxies:          parseCaseSensitiveList(utils.GetEnv("TRUSTED_PROXIES", "")),
+		PublicRequestsPerSecond: publicRequestsPerSecond,
+		PublicBurstSize:         publicBurstSize,
+		AdminBypass:             utils.GetEnv("RATE_LIMIT_ADMIN_BYPASS", "true") == "true",
+		MaxConcurrent:           maxConcurrent,
+		MaxConcurrentPerIP:      maxConcurrentPerIP,
+		MaxClients:              maxClients,
+		CleanupInterval:         time.Duration(cleanupIntervalSeconds) * time.Second,
+		ClientTTL:               time.Duration(clientTTLSeconds) * time.Second,
 	}
 }
 
-func loadGameConfig() GameConfig {
+func loadGameConfig() (GameConfig, error) {
 	maxPlayers, _ := strconv.Atoi(utils.GetEnv("MAX_PLAYERS", "200"))
 	turnIntervalHours, _ := strconv.Atoi(utils.GetEnv("TURN_INTERVAL_HOURS", "1"))
 	galaxyCount, _ := strconv.Atoi(utils.GetEnv("GALAXY_COUNT", "1"))
@@ -273,24 +483,169 @@ func loadGameConfig() GameConfig {
 	systemsPerSector, _ := strconv.Atoi(utils.GetEnv("SYSTEMS_PER_SECTOR", "16"))
 	minPlanets, _ := strconv.Atoi(utils.GetEnv("MIN_PLANETS_PER_SYSTEM", "3"))
 	maxPlanets, _ := strconv.Atoi(utils.GetEnv("MAX_PLANETS_PER_SYSTEM", "12"))
+	populationPerSize, _ := strconv.ParseInt(utils.GetEnv("PLANET_POPULATION_PER_SIZE", "5000"), 10, 64)
+	populationVariance, _ := strconv.Atoi(utils.GetEnv("PLANET_POPULATION_VARIANCE", "20"))
+	populationDecay, _ := strconv.Atoi(utils.GetEnv("PLANET_POPULATION_DECAY", "0"))
+	spawnSystemsPerSector, _ := strconv.Atoi(utils.GetEnv("SPAWN_SYSTEMS_PER_SECTOR", "1"))
+	schedulerIntervalSeconds, _ := strconv.Atoi(utils.GetEnv("GAME_SCHEDULER_INTERVAL_SECONDS", "30"))
+	turnSchedulerIntervalSeconds, _ := strconv.Atoi(utils.GetEnv("TURN_SCHEDULER_INTERVAL_SECONDS", "30"))
+	minPlayers, _ := strconv.Atoi(utils.GetEnv("MIN_PLAYERS", "0"))
+	lobbyGraceMinutes, _ := strconv.Atoi(utils.GetEnv("LOBBY_GRACE_PERIOD_MINUTES", "0"))
+	maxGenerationSeconds, _ := strconv.Atoi(utils.GetEnv("MAX_GENERATION_SECONDS", "120"))
+
+	terraformCosts, err := parseTerraformCosts(utils.GetEnv("TERRAFORM_COSTS", defaultTerraformCosts))
+	if err != nil {
+		return GameConfig{}, fmt.Errorf("failed to parse TERRAFORM_COSTS: %w", err)
+	}
 
 	return GameConfig{
-		MaxPlayers:          maxPlayers,
-		TurnIntervalHours:   turnIntervalHours,
-		GalaxyCount:         galaxyCount,
-		SectorsPerGalaxy:    sectorsPerGalaxy,
-		SystemsPerSector:    systemsPerSector,
-		MinPlanetsPerSystem: minPlanets,
-		MaxPlanetsPerSystem: maxPlanets,
+		MinPlayers:            minPlayers,
+		MaxPlayers:            maxPlayers,
+		TurnIntervalHours:     turnIntervalHours,
+		GalaxyCount:           galaxyCount,
+		SectorsPerGalaxy:      sectorsPerGalaxy,
+		SystemsPerSector:      systemsPerSector,
+		MinPlanetsPerSystem:   minPlanets,
+		MaxPlanetsPerSystem:   maxPlanets,
+		PopulationPerSize:     populationPerSize,
+		PopulationVariance:    populationVariance,
+		PopulationDecay:       populationDecay,
+		SpawnSystemsPerSector: spawnSystemsPerSector,
+		SchedulerInterval:     time.Duration(schedulerIntervalSeconds) * time.Second,
+		TurnSchedulerInterval: time.Duration(turnSchedulerIntervalSeconds) * time.Second,
+		LobbyGracePeriod:      time.Duration(lobbyGraceMinutes) * time.Minute,
+		MaxGenerationTime:     time.Duration(maxGenerationSeconds) * time.Second,
+		ResearchTreeFile:      utils.GetEnv("RESEARCH_TREE_FILE", ""),
+		SpawnStrategy:         utils.GetEnv("SPAWN_STRATEGY", SpawnStrategySpread),
+		TerraformCosts:        terraformCosts,
+	}, nil
+}
+
+// parseTerraformCosts reads comma-separated from>to:cost entries, such as barren>terrestrial:100000
+func parseTerraformCosts(value string) (map[string]map[string]int64, error) {
+	costs := map[string]map[string]int64{}
+	for _, entry := range parseList(value) {
+		transition, cost, ok := strings.Cut(entry, ":")
+		from, to, hasTarget := strings.Cut(transition, ">")
+		if !ok || !hasTarget {
+			return nil, fmt.Errorf("entry %q is not from>to:cost", entry)
+		}
+
+		amount, err := strconv.ParseInt(strings.TrimSpace(cost), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q has an invalid cost: %w", entry, err)
+		}
+
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if costs[from] == nil {
+			costs[from] = map[string]int64{}
+		}
+		costs[from][to] = amount
 	}
+	return costs, nil
 }
 
 func loadAdminConfig() AdminConfig {
+	bootstrapCount, _ := strconv.Atoi(utils.GetEnv("BOOTSTRAP_ADMIN_COUNT", "0"))
+
 	return AdminConfig{
-		Email:       utils.GetEnv("ADMIN_EMAIL", "admin@localhost"),
-		Username:    utils.GetEnv("ADMIN_USERNAME", "admin"),
-		DisplayName: utils.GetEnv("ADMIN_DISPLAY_NAME", "Admin"),
+		Email:          utils.GetEnv("ADMIN_EMAIL", "admin@localhost"),
+		Username:       utils.GetEnv("ADMIN_USERNAME", "admin"),
+		DisplayName:    utils.GetEnv("ADMIN_DISPLAY_NAME", "Admin"),
+		BootstrapCount: bootstrapCount,
+	}
+}
+
+func loadEventsConfig() EventsConfig {
+	streamMaxLen, _ := strconv.ParseInt(utils.GetEnv("EVENTS_STREAM_MAX_LEN", "100000"), 10, 64)
+	subscriberBuffer, _ := strconv.Atoi(utils.GetEnv("EVENTS_SUBSCRIBER_BUFFER", "64"))
+	maxSubscribers, _ := strconv.Atoi(utils.GetEnv("EVENTS_MAX_SUBSCRIBERS", "1000"))
+	maxSubscribersPerGame, _ := strconv.Atoi(utils.GetEnv("EVENTS_MAX_SUBSCRIBERS_PER_GAME", "100"))
+
+	return EventsConfig{
+		Publisher:             utils.GetEnv("EVENTS_PUBLISHER", EventsPublisherNone),
+		Stream:                utils.GetEnv("EVENTS_STREAM", "planets:events"),
+		StreamMaxLen:          streamMaxLen,
+		SubscriberBuffer:      subscriberBuffer,
+		SlowSubscriberPolicy:  utils.GetEnv("EVENTS_SLOW_SUBSCRIBER_POLICY", EventsSlowSubscriberDrop),
+		MaxSubscribers:        maxSubscribers,
+		MaxSubscribersPerGame: maxSubscribersPerGame,
+	}
+}
+
+func loadCacheConfig() CacheConfig {
+	playersTTLSeconds, _ := strconv.Atoi(utils.GetEnv("PLAYERS_CACHE_TTL_SECONDS", "30"))
+	immutableMaxAgeSeconds, _ := strconv.Atoi(utils.GetEnv("IMMUTABLE_CACHE_MAX_AGE_SECONDS", "86400"))
+	revocationTTLSeconds, _ := strconv.Atoi(utils.GetEnv("REVOCATION_CACHE_TTL_SECONDS", "5"))
+
+	return CacheConfig{
+		PlayersTTL:      time.Duration(playersTTLSeconds) * time.Second,
+		ImmutableMaxAge: time.Duration(immutableMaxAgeSeconds) * time.Second,
+		RevocationTTL:   time.Duration(revocationTTLSeconds) * time.Second,
+	}
+}
+
+func loadRegistrationConfig() (RegistrationConfig, error) {
+	deniedDomains := utils.GetEnv("DENIED_EMAIL_DOMAINS", "")
+
+	if path := utils.GetEnv("DENIED_EMAIL_DOMAINS_FILE", ""); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return RegistrationConfig{}, fmt.Errorf("failed to read DENIED_EMAIL_DOMAINS_FILE: %w", err)
+		}
+		deniedDomains += "," + strings.ReplaceAll(string(content), "\n", ",")
 	}
+
+	return RegistrationConfig{
+		Mode:                  utils.GetEnv("REGISTRATION_MODE", RegistrationModeOpen),
+		AllowedEmails:         parseList(utils.GetEnv("ALLOWED_EMAILS", "")),
+		AllowedEmailDomains:   parseList(utils.GetEnv("ALLOWED_EMAIL_DOMAINS", "")),
+		DeniedEmailDomains:    parseList(deniedDomains),
+		UnverifiedEmailPolicy: utils.GetEnv("UNVERIFIED_EMAIL_POLICY", UnverifiedEmailReject),
+	}, nil
+}
+
+// parseList splits a comma-separated value into trimmed, lowercased, non-empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCaseSensitiveList splits a comma-separated value into trimmed, non-empty entries
+// without lowercasing them, for values such as URL paths
+func parseCaseSensitiveList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateTerraformCosts checks that every transition changes one known planet type into another
+// for a positive population
+func validateTerraformCosts(costs map[string]map[string]int64) error {
+	for from, targets := range costs {
+		for to, cost := range targets {
+			if !slices.Contains(planetTypes, from) || !slices.Contains(planetTypes, to) {
+				return fmt.Errorf("TERRAFORM_COSTS entry %s>%s names an unknown planet type, want one of %s", from, to, strings.Join(planetTypes, ", "))
+			}
+			if from == to {
+				return fmt.Errorf("TERRAFORM_COSTS entry %s>%s does not change the planet type", from, to)
+			}
+			if cost <= 0 {
+				return fmt.Errorf("TERRAFORM_COSTS entry %s>%s must cost a positive population", from, to)
+			}
+		}
+	}
+	return nil
 }
 
 func (c *Config) validate() error {
@@ -302,10 +657,57 @@ func (c *Config) validate() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters long")
 	}
 
+	if c.Auth.AccessTokenExpiration <= 0 {
+		return fmt.Errorf("JWT_ACCESS_EXPIRATION_MINUTES must be positive")
+	}
+
+	if c.Auth.RefreshTokenExpiration <= c.Auth.AccessTokenExpiration {
+		return fmt.Errorf("JWT_REFRESH_EXPIRATION_DAYS must outlast the access token")
+	}
+
+	switch c.Auth.TokenSource {
+	case TokenSourceCookie, TokenSourceHeader, TokenSourceBoth:
+	default:
+		return fmt.Errorf("AUTH_TOKEN_SOURCE must be %q, %q or %q", TokenSourceCookie, TokenSourceHeader, TokenSourceBoth)
+	}
+
+	if !strings.HasPrefix(c.Auth.CookiePath, "/") {
+		return fmt.Errorf("AUTH_COOKIE_PATH must start with /")
+	}
+
+	authCookie := http.Cookie{Name: c.Auth.CookieName, Value: "token", Path: c.Auth.CookiePath}
+	if err := authCookie.Valid(); err != nil {
+		return fmt.Errorf("AUTH_COOKIE_NAME or AUTH_COOKIE_PATH is not valid: %w", err)
+	}
+
 	if c.Server.Port == "" {
 		return fmt.Errorf("SERVER_PORT is required")
 	}
 
+	for _, proxy := range c.RateLimit.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				return fmt.Errorf("TRUSTED_PROXIES entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.RateLimit.CleanupInterval <= 0 || c.RateLimit.ClientTTL <= 0 {
+		return fmt.Errorf("RATE_LIMIT_CLEANUP_INTERVAL_SECONDS and RATE_LIMIT_CLIENT_TTL_SECONDS must be positive")
+	}
+
+	if c.Game.SpawnStrategy != SpawnStrategySpread && c.Game.SpawnStrategy != SpawnStrategyRandom {
+		return fmt.Errorf("SPAWN_STRATEGY must be %q or %q", SpawnStrategySpread, SpawnStrategyRandom)
+	}
+
+	if err := validateTerraformCosts(c.Game.TerraformCosts); err != nil {
+		return err
+	}
+
+	if c.Server.MigrateDownSteps < 0 {
+		return fmt.Errorf("MIGRATE_DOWN_STEPS must not be negative")
+	}
+
 	if c.Database.Host == "" {
 		return fmt.Errorf("DB_HOST is required")
 	}
@@ -318,6 +720,48 @@ func (c *Config) validate() error {
 		return fmt.Errorf("SERVER_URL is required")
 	}
 
+	if c.Logging.Format != "" && c.Logging.Format != LogFormatJSON && c.Logging.Format != LogFormatText {
+		return fmt.Errorf("LOG_FORMAT must be %q or %q", LogFormatJSON, LogFormatText)
+	}
+
+	if c.Game.PopulationVariance < 0 || c.Game.PopulationVariance > 100 {
+		return fmt.Errorf("PLANET_POPULATION_VARIANCE must be between 0 and 100")
+	}
+
+	if c.Game.PopulationDecay < 0 || c.Game.PopulationDecay > 100 {
+		return fmt.Errorf("PLANET_POPULATION_DECAY must be between 0 and 100")
+	}
+
+	if c.Registration.Mode != RegistrationModeOpen && c.Registration.Mode != RegistrationModeAllowlist {
+		return fmt.Errorf("REGISTRATION_MODE must be %q or %q", RegistrationModeOpen, RegistrationModeAllowlist)
+	}
+
+	if c.Registration.UnverifiedEmailPolicy != UnverifiedEmailReject && c.Registration.UnverifiedEmailPolicy != UnverifiedEmailPlaceholder {
+		return fmt.Errorf("UNVERIFIED_EMAIL_POLICY must be %q or %q", UnverifiedEmailReject, UnverifiedEmailPlaceholder)
+	}
+
+	switch c.Events.Publisher {
+	case EventsPublisherNone:
+	case EventsPublisherRedis:
+		if !c.Redis.Enabled {
+			return fmt.Errorf("EVENTS_PUBLISHER=%s requires REDIS_ENABLED=true", EventsPublisherRedis)
+		}
+	default:
+		return fmt.Errorf("EVENTS_PUBLISHER must be %q or %q", EventsPublisherNone, EventsPublisherRedis)
+	}
+
+	if c.Events.SubscriberBuffer < 1 {
+		return fmt.Errorf("EVENTS_SUBSCRIBER_BUFFER must be at least 1")
+	}
+
+	if c.Events.SlowSubscriberPolicy != EventsSlowSubscriberDrop && c.Events.SlowSubscriberPolicy != EventsSlowSubscriberDisconnect {
+		return fmt.Errorf("EVENTS_SLOW_SUBSCRIBER_POLICY must be %q or %q", EventsSlowSubscriberDrop, EventsSlowSubscriberDisconnect)
+	}
+
+	if c.Events.MaxSubscribers < 0 || c.Events.MaxSubscribersPerGame < 0 {
+		return fmt.Errorf("EVENTS_MAX_SUBSCRIBERS and EVENTS_MAX_SUBSCRIBERS_PER_GAME cannot be negative")
+	}
+
 	return nil
 }
 
@@ -333,6 +777,11 @@ func (c *Config) DiscordOAuthConfigured() bool {
 	return c.OAuth.Discord.ClientID != "" && c.OAuth.Discord.ClientSecret != ""
 }
 
+// AnyAuthProviderConfigured reports whether players have at least one way to log in
+func (c *Config) AnyAuthProviderConfigured() bool {
+	return c.GoogleOAuthConfigured() || c.GitHubOAuthConfigured() || c.DiscordOAuthConfigured()
+}
+
 func (c *Config) ConnectionString() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
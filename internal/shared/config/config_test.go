@@ -0,0 +1,74 @@
+package config
+
+import "testing"
+
+func TestLogFormatPrecedence(t *testing.T) {
+	tests := []struct {
+		environment, format string
+		wantJSON            bool
+	}{
+		// Without LOG_FORMAT the environment decides
+		{"production", "", true},
+		{"development", "", false},
+		{"", "", false},
+		// An explicit LOG_FORMAT wins over the environment default
+		{"production", LogFormatText, false},
+		{"production", LogFormatJSON, true},
+		{"development", LogFormatJSON, true},
+		{"development", LogFormatText, false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("ENVIRONMENT", tt.environment)
+		t.Setenv("LOG_FORMAT", tt.format)
+
+		logging := loadLoggingConfig()
+		if logging.JSONFormat != tt.wantJSON {
+			t.Errorf("ENVIRONMENT=%q LOG_FORMAT=%q: JSONFormat = %v, want %v", tt.environment, tt.format, logging.JSONFormat, tt.wantJSON)
+		}
+		if logging.Format != tt.format {
+			t.Errorf("ENVIRONMENT=%q LOG_FORMAT=%q: Format = %q, want it kept as set", tt.environment, tt.format, logging.Format)
+		}
+	}
+}
+
+func TestDefaultTerraformCostsAreValid(t *testing.T) {
+	costs := DefaultTerraformCosts()
+	if err := validateTerraformCosts(costs); err != nil {
+		t.Fatal(err)
+	}
+	if costs["ice"]["terrestrial"] != 150000 || len(costs["gas_giant"]) != 0 {
+		t.Fatalf("default costs = %v, want ice>terrestrial at 150000 and no gas giant transitions", costs)
+	}
+}
+
+func TestTerraformCosts(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantParse bool
+		wantValid bool
+	}{
+		{"barren>terrestrial:100000, Ice>Barren:30000", true, true},
+		{"", true, true},
+		{"barren>terrestrial", false, false},
+		{"barren:100000", false, false},
+		{"barren>terrestrial:lots", false, false},
+		{"barren>oceanic:100000", true, false},
+		{"barren>barren:100000", true, false},
+		{"barren>terrestrial:0", true, false},
+	}
+
+	for _, tt := range tests {
+		costs, err := parseTerraformCosts(tt.value)
+		if (err == nil) != tt.wantParse {
+			t.Errorf("parseTerraformCosts(%q) error = %v, want parsed: %v", tt.value, err, tt.wantParse)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if err := validateTerraformCosts(costs); (err == nil) != tt.wantValid {
+			t.Errorf("validateTerraformCosts(%q) error = %v, want valid: %v", tt.value, err, tt.wantValid)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Probe checks that an external dependency is reachable
+type Probe struct {
+	Name  string
+	Fatal bool
+	Check func(ctx context.Context) error
+}
+
+// SelfCheckReport separates problems that should stop startup from ones that only need attention
+type SelfCheckReport struct {
+	Warnings []string
+	Fatal    []string
+}
+
+func (r *SelfCheckReport) HasFatal() bool {
+	return len(r.Fatal) > 0
+}
+
+// SelfCheck looks for misconfigurations that validate() lets through and probes the
+// given dependencies. It never fails on its own; callers decide what to do with the report.
+func (c *Config) SelfCheck(ctx context.Context, probes ...Probe) SelfCheckReport {
+	var report SelfCheckReport
+
+	for _, probe := range probes {
+		if err := probe.Check(ctx); err != nil {
+			problem := fmt.Sprintf("%s unreachable: %v", probe.Name, err)
+			if probe.Fatal {
+				report.Fatal = append(report.Fatal, problem)
+			} else {
+				report.Warnings = append(report.Warnings, problem)
+			}
+		}
+	}
+
+	if !c.AnyAuthProviderConfigured() {
+		problem := "no OAuth provider is configured, players will not be able to log in"
+		if c.Auth.RequireProvider {
+			report.Fatal = append(report.Fatal, problem)
+		} else {
+			report.Warnings = append(report.Warnings, problem)
+		}
+	}
+
+	if weakSecret(c.Auth.JWTSecret) {
+		report.Warnings = append(report.Warnings, "JWT_SECRET has low variety, generate one with: openssl rand -hex 32")
+	}
+
+	if c.Server.Environment == "production" {
+		if strings.HasPrefix(c.Server.URL, "http://") {
+			report.Warnings = append(report.Warnings, "SERVER_URL uses http in production, OAuth providers may reject the redirect URL")
+		}
+		if c.Frontend.ClientURL == "" && c.Frontend.AdminURL == "" {
+			report.Warnings = append(report.Warnings, "no FRONTEND_CLIENT_URL or FRONTEND_ADMIN_URL set, browsers will be blocked by CORS")
+		}
+		if !c.Redis.Enabled {
+			report.Warnings = append(report.Warnings, "Redis is disabled in production, OAuth state will not survive restarts or span replicas")
+		}
+	}
+
+	return report
+}
+
+// weakSecret flags secrets built from only a handful of distinct characters
+func weakSecret(secret string) bool {
+	distinct := make(map[rune]bool)
+	for _, ch := range secret {
+		distinct[ch] = true
+	}
+	return len(distinct) < 10
+}
@@ -7,12 +7,21 @@ import (
 	"strings"
 )
 
+// AuthCookieName returns the configured name of the cookie holding the access token.
+// Setting, clearing and reading the cookie all go through it so they stay in sync.
+func AuthCookieName() string {
+	return config.GlobalConfig.Auth.CookieName
+}
+
+// RefreshCookieName holds the refresh token, only sent to the /auth endpoints
+const RefreshCookieName = "refresh_token"
+
 func SetAuthCookie(w http.ResponseWriter, token string) {
 	cfg := config.GlobalConfig
 
 	cookie := createAuthCookie()
 	cookie.Value = token
-	cookie.MaxAge = int(cfg.Auth.TokenExpiration.Seconds())
+	cookie.MaxAge = int(cfg.Auth.AccessTokenExpiration.Seconds())
 
 	http.SetCookie(w, cookie)
 }
@@ -25,12 +34,37 @@ func ClearAuthCookie(w http.ResponseWriter) {
 	http.SetCookie(w, cookie)
 }
 
+func SetRefreshCookie(w http.ResponseWriter, token string) {
+	cfg := config.GlobalConfig
+
+	cookie := createRefreshCookie()
+	cookie.Value = token
+	cookie.MaxAge = int(cfg.Auth.RefreshTokenExpiration.Seconds())
+
+	http.SetCookie(w, cookie)
+}
+
+func ClearRefreshCookie(w http.ResponseWriter) {
+	cookie := createRefreshCookie()
+	cookie.Value = ""
+	cookie.MaxAge = -1
+
+	http.SetCookie(w, cookie)
+}
+
+func createRefreshCookie() *http.Cookie {
+	cookie := createAuthCookie()
+	cookie.Name = RefreshCookieName
+	cookie.Path = "/auth"
+	return cookie
+}
+
 func createAuthCookie() *http.Cookie {
 	cfg := config.GlobalConfig
 
 	return &http.Cookie{
-		Name:     "auth_token",
-		Path:     "/",
+		Name:     cfg.Auth.CookieName,
+		Path:     cfg.Auth.CookiePath,
 		Domain:   extractDomain(cfg.Frontend.ClientURL),
 		HttpOnly: true,
 		Secure:   cfg.Auth.CookieSecure,
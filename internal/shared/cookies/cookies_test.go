@@ -0,0 +1,91 @@
+package cookies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"planets-server/internal/shared/config"
+)
+
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+// written returns the single cookie write sets on a response
+func written(t *testing.T, write func(w http.ResponseWriter)) *http.Cookie {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	write(w)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d Set-Cookie headers, want 1", len(cookies))
+	}
+	return cookies[0]
+}
+
+func TestClearedCookiesMatchTheCookiesSet(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		clientURL string
+		secure    bool
+		sameSite  http.SameSite
+	}{
+		{"cross-site production", "https://play.example.com", true, http.SameSiteNoneMode},
+		{"local development", "http://localhost:3000", false, http.SameSiteLaxMode},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, &config.Config{
+				Auth: config.AuthConfig{
+					CookieName:             "planets_auth",
+					CookiePath:             "/",
+					CookieSecure:           tt.secure,
+					CookieSameSite:         tt.sameSite,
+					AccessTokenExpiration:  15 * time.Minute,
+					RefreshTokenExpiration: 7 * 24 * time.Hour,
+				},
+				Frontend: config.FrontendConfig{ClientURL: tt.clientURL},
+			})
+
+			for _, pair := range []struct {
+				name       string
+				set, clear func(w http.ResponseWriter)
+				path       string
+			}{
+				{"auth", func(w http.ResponseWriter) { SetAuthCookie(w, "access") }, ClearAuthCookie, "/"},
+				{"refresh", func(w http.ResponseWriter) { SetRefreshCookie(w, "refresh") }, ClearRefreshCookie, "/auth"},
+			} {
+				set, cleared := written(t, pair.set), written(t, pair.clear)
+
+				// A browser only drops a cookie when name, path and domain all match the one it holds
+				if cleared.Name != set.Name || cleared.Path != set.Path || cleared.Domain != set.Domain {
+					t.Errorf("%s: cleared %s path=%q domain=%q, set %s path=%q domain=%q",
+						pair.name, cleared.Name, cleared.Path, cleared.Domain, set.Name, set.Path, set.Domain)
+				}
+				if set.Path != pair.path {
+					t.Errorf("%s: path = %q, want %q", pair.name, set.Path, pair.path)
+				}
+				if cleared.SameSite != set.SameSite || set.SameSite != tt.sameSite {
+					t.Errorf("%s: SameSite set %v, cleared %v, want %v", pair.name, set.SameSite, cleared.SameSite, tt.sameSite)
+				}
+				if cleared.Secure != set.Secure || set.Secure != tt.secure {
+					t.Errorf("%s: Secure set %v, cleared %v, want %v", pair.name, set.Secure, cleared.Secure, tt.secure)
+				}
+				if !set.HttpOnly || !cleared.HttpOnly {
+					t.Errorf("%s: HttpOnly set %v, cleared %v, want both", pair.name, set.HttpOnly, cleared.HttpOnly)
+				}
+				if set.MaxAge <= 0 || cleared.MaxAge >= 0 || cleared.Value != "" {
+					t.Errorf("%s: set Max-Age %d, cleared Max-Age %d with value %q; want a live cookie replaced by an expired empty one",
+						pair.name, set.MaxAge, cleared.MaxAge, cleared.Value)
+				}
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 type DB struct {
 	*sql.DB
+	readRetries int
 }
 
 type Tx struct {
@@ -27,6 +28,12 @@ type Executor interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+// New wraps an already opened connection pool, for callers such as tests that do not
+// connect through the global config
+func New(sqlDB *sql.DB, readRetries int) *DB {
+	return &DB{DB: sqlDB, readRetries: readRetries}
+}
+
 func (db *DB) BeginTx(ctx context.Context) (*Tx, error) {
 	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -74,5 +81,5 @@ func Connect() (*DB, error) {
 	logger.Info("Database connection established successfully",
 		"host", cfg.Database.Host, "database", cfg.Database.Name)
 
-	return &DB{sqlDB}, nil
+	return &DB{DB: sqlDB, readRetries: cfg.Database.ReadRetries}, nil
 }
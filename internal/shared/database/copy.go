@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// CopyIn streams rows into table with the Postgres COPY protocol. It is much faster
+// than INSERT for very large batches but must run inside a transaction and cannot
+// return generated values, so callers that need IDs have to allocate them first.
+func CopyIn(ctx context.Context, tx *Tx, table string, columns []string, rows [][]any) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy into %s: %w", table, err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("failed to buffer row for copy into %s: %w", table, err)
+		}
+	}
+
+	// An Exec without arguments flushes the buffered rows to the server
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush copy into %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// NextIDs reserves count values from the serial sequence behind table.column,
+// for inserts such as COPY that cannot use RETURNING.
+func NextIDs(ctx context.Context, tx *Tx, table, column string, count int) ([]int, error) {
+	query := `SELECT nextval(pg_get_serial_sequence($1, $2)) FROM generate_series(1, $3)`
+
+	rows, err := tx.QueryContext(ctx, query, table, column, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve ids for %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]int, 0, count)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan reserved id for %s: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reserved ids for %s: %w", table, err)
+	}
+
+	return ids, nil
+}
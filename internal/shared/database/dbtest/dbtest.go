@@ -0,0 +1,196 @@
+// Package dbtest gives tests a migrated Postgres schema of their own. Tests that need the
+// database call Open, which skips them unless TEST_DATABASE_URL points at a server to use.
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"planets-server/internal/shared/database"
+
+	_ "github.com/lib/pq"
+)
+
+// URLEnv names the environment variable holding the connection string of the test server
+const URLEnv = "TEST_DATABASE_URL"
+
+// migrateMu serializes migration runs, which change the working directory of the process
+var migrateMu sync.Mutex
+
+// Open creates a fresh schema on the test server, applies every migration to it and returns
+// a connection whose search_path points at it. The schema is dropped when the test ends, so
+// tests never see each other's rows.
+func Open(t testing.TB) *database.DB {
+	t.Helper()
+
+	db := OpenEmpty(t)
+	migrate(t, db)
+
+	return db
+}
+
+// OpenEmpty is Open without the migrations, for tests of the migration runner itself
+func OpenEmpty(t testing.TB) *database.DB {
+	t.Helper()
+
+	baseURL := os.Getenv(URLEnv)
+	if baseURL == "" {
+		t.Skipf("%s is not set, skipping database test", URLEnv)
+	}
+
+	admin, err := sql.Open("postgres", baseURL)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := "test_" + randomSuffix(t)
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("failed to create test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Logf("failed to drop test schema %s: %v", schema, err)
+		}
+	})
+
+	sqlDB, err := sql.Open("postgres", withSearchPath(baseURL, schema))
+	if err != nil {
+		t.Fatalf("failed to open test schema: %v", err)
+	}
+	db := database.New(sqlDB, 1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// CreatePlayer inserts a player with a unique username and email and returns its ID
+func CreatePlayer(t testing.TB, db *database.DB) int {
+	t.Helper()
+
+	name := "player_" + randomSuffix(t)
+
+	var id int
+	err := db.QueryRow(
+		"INSERT INTO players (username, email, display_name) VALUES ($1, $2, $1) RETURNING id",
+		name, name+"@example.com",
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to create player: %v", err)
+	}
+
+	return id
+}
+
+// LockTable holds an ACCESS EXCLUSIVE lock on the table in a transaction of its own, so every
+// query reading it waits until release is called. It returns the backend holding the lock,
+// for Blocked. The lock is released when the test ends at the latest.
+func LockTable(t testing.TB, db *database.DB, table string) (holder int, release func()) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin lock transaction: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE "+table+" IN ACCESS EXCLUSIVE MODE"); err != nil {
+		_ = tx.Rollback()
+		t.Fatalf("failed to lock %s: %v", table, err)
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&holder); err != nil {
+		_ = tx.Rollback()
+		t.Fatalf("failed to read lock holder: %v", err)
+	}
+
+	var once sync.Once
+	release = func() { once.Do(func() { _ = tx.Rollback() }) }
+	t.Cleanup(release)
+
+	return holder, release
+}
+
+// Blocked counts the queries waiting on locks the holder backend has
+func Blocked(t testing.TB, db *database.DB, holder int) int {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pg_stat_activity WHERE $1 = ANY(pg_blocking_pids(pid))", holder).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count blocked queries: %v", err)
+	}
+	return count
+}
+
+// migrate runs the migrations from the module root, where RunMigrations expects to find them
+func migrate(t testing.TB, db *database.DB) {
+	t.Helper()
+
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to read working directory: %v", err)
+	}
+
+	root, err := moduleRoot(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("failed to change to module root: %v", err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if _, err := db.RunMigrations(context.Background()); err != nil {
+		t.Fatalf("failed to migrate test schema: %v", err)
+	}
+}
+
+func moduleRoot(dir string) (string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found above the test directory")
+		}
+		dir = parent
+	}
+}
+
+// withSearchPath adds search_path to the connection string, in URL or keyword/value form.
+// lib/pq passes parameters it does not know to the server as run-time settings.
+func withSearchPath(connStr, schema string) string {
+	if strings.HasPrefix(connStr, "postgres://") || strings.HasPrefix(connStr, "postgresql://") {
+		u, err := url.Parse(connStr)
+		if err == nil {
+			values := u.Query()
+			values.Set("search_path", schema)
+			u.RawQuery = values.Encode()
+			return u.String()
+		}
+	}
+	return connStr + " search_path=" + schema
+}
+
+func randomSuffix(t testing.TB) string {
+	t.Helper()
+
+	bytes := make([]byte, 6)
+	if _, err := rand.Read(bytes); err != nil {
+		t.Fatalf("failed to generate random name: %v", err)
+	}
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	stderrors "errors"
+
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes that reflect bad client input rather than a server fault
+const (
+	pgUniqueViolation     pq.ErrorCode = "23505"
+	pgForeignKeyViolation pq.ErrorCode = "23503"
+	pgNotNullViolation    pq.ErrorCode = "23502"
+	pgCheckViolation      pq.ErrorCode = "23514"
+	pgInvalidTextRepr     pq.ErrorCode = "22P02"
+	pgStringTooLong       pq.ErrorCode = "22001"
+	pgNoDataFound         pq.ErrorCode = "P0002"
+)
+
+// ClassifyError wraps a database error with the application error type matching its
+// Postgres error code, so constraint violations surface as 409/400 instead of 500.
+// A lost connection is reported as external, and errors without a recognised code are
+// wrapped as internal.
+func ClassifyError(message string, err error) error {
+	if IsConnectionError(err) {
+		return unavailable(err)
+	}
+
+	var pqErr *pq.Error
+	if !stderrors.As(err, &pqErr) {
+		return errors.WrapInternal(message, err)
+	}
+
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		return errors.WithCode(errors.WrapConflict(message, err), errors.CodeAlreadyExists)
+	case pgForeignKeyViolation, pgNotNullViolation, pgCheckViolation, pgInvalidTextRepr, pgStringTooLong:
+		return errors.WrapValidation(message, err)
+	case pgNoDataFound:
+		return errors.NotFoundf("%s: %s", message, pqErr.Message)
+	default:
+		return errors.WrapInternal(message, err)
+	}
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantType errors.ErrorType
+		wantCode string
+	}{
+		{"unique violation", &pq.Error{Code: "23505"}, errors.ErrorTypeConflict, errors.CodeAlreadyExists},
+		{"foreign key violation", &pq.Error{Code: "23503"}, errors.ErrorTypeValidation, ""},
+		{"not null violation", &pq.Error{Code: "23502"}, errors.ErrorTypeValidation, ""},
+		{"check violation", &pq.Error{Code: "23514"}, errors.ErrorTypeValidation, ""},
+		{"invalid text representation", &pq.Error{Code: "22P02"}, errors.ErrorTypeValidation, ""},
+		{"string too long", &pq.Error{Code: "22001"}, errors.ErrorTypeValidation, ""},
+		{"no data found", &pq.Error{Code: "P0002", Message: "planet 7"}, errors.ErrorTypeNotFound, ""},
+		{"unknown code", &pq.Error{Code: "42P01"}, errors.ErrorTypeInternal, ""},
+		{"wrapped unique violation", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), errors.ErrorTypeConflict, errors.CodeAlreadyExists},
+		{"not a postgres error", stderrors.New("boom"), errors.ErrorTypeInternal, ""},
+		{"connection lost", &pq.Error{Code: "57P01"}, errors.ErrorTypeExternal, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyError("failed to save", tt.err)
+
+			if got := errors.GetType(err); got != tt.wantType {
+				t.Fatalf("type = %s, want %s (error: %v)", got, tt.wantType, err)
+			}
+			if got := errors.GetCode(err); got != tt.wantCode {
+				t.Fatalf("code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorKeepsTheCause(t *testing.T) {
+	cause := &pq.Error{Code: "23505", Constraint: "players_email_key"}
+
+	err := ClassifyError("failed to create player", cause)
+
+	var pqErr *pq.Error
+	if !stderrors.As(err, &pqErr) || pqErr.Constraint != "players_email_key" {
+		t.Fatalf("the pq.Error is not reachable through %v", err)
+	}
+}
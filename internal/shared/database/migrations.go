@@ -1,6 +1,9 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
@@ -12,32 +15,177 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func (db *DB) RunMigrations() error {
+// migrationLockID is the Postgres advisory lock key that serializes migration runs
+const migrationLockID = 727_001
+
+// Migration files are named NNN_name.sql or NNN_name.up.sql, and are rolled back by a
+// NNN_name.down.sql next to them. A migration without a down file is up-only. Either way the
+// version recorded in schema_migrations is the file name that was applied.
+const (
+	upMigrationSuffix   = ".up.sql"
+	downMigrationSuffix = ".down.sql"
+)
+
+// RunMigrations applies pending migrations and returns the names of those it applied.
+// Concurrent runs, across processes too, are serialized with an advisory lock.
+func (db *DB) RunMigrations(ctx context.Context) ([]string, error) {
 	logger := slog.With("component", "migrations")
 	logger.Info("Starting database migrations")
 
+	applied := []string{}
+	err := db.withMigrationLock(ctx, logger, func() error {
+		if err := db.createMigrationsTable(); err != nil {
+			logger.Error("Failed to create migrations table", "error", err)
+			return fmt.Errorf("failed to create migrations table: %w", err)
+		}
+
+		migrations, err := db.getMigrationFiles()
+		if err != nil {
+			logger.Error("Failed to get migration files", "error", err)
+			return fmt.Errorf("failed to get migration files: %w", err)
+		}
+
+		logger.Info("Found migration files", "count", len(migrations))
+
+		for _, migration := range migrations {
+			ran, err := db.runMigration(migration)
+			if err != nil {
+				logger.Error("Failed to run migration", "migration", migration, "error", err)
+				return fmt.Errorf("failed to run migration %s: %w", migration, err)
+			}
+			if ran {
+				applied = append(applied, filepath.Base(migration))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, err
+	}
+
+	logger.Info("All migrations completed successfully", "applied", len(applied))
+	return applied, nil
+}
+
+// RollbackMigration reverts the most recently applied migration by running its .down.sql
+// file and returns its version, or "" when no migration has been applied. Up-only
+// migrations, such as the initial schema, cannot be rolled back.
+func (db *DB) RollbackMigration(ctx context.Context) (string, error) {
+	logger := slog.With("component", "migrations", "operation", "rollback")
+
+	var version string
+	err := db.withMigrationLock(ctx, logger, func() error {
+		if err := db.createMigrationsTable(); err != nil {
+			logger.Error("Failed to create migrations table", "error", err)
+			return fmt.Errorf("failed to create migrations table: %w", err)
+		}
+
+		err := db.QueryRowContext(ctx, "SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version)
+		if err == sql.ErrNoRows {
+			logger.Info("No applied migrations to roll back")
+			return nil
+		}
+		if err != nil {
+			logger.Error("Failed to find latest migration", "error", err)
+			return fmt.Errorf("failed to find latest migration: %w", err)
+		}
+
+		if err := db.revertMigration(version); err != nil {
+			logger.Error("Failed to roll back migration", "migration", version, "error", err)
+			return fmt.Errorf("failed to roll back migration %s: %w", version, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock
+func (db *DB) withMigrationLock(ctx context.Context, logger *slog.Logger, fn func() error) error {
+	// Advisory locks belong to a session, so hold one connection for the whole run
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		logger.Error("Failed to acquire connection for migration lock", "error", err)
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Another replica may be migrating; wait for it and then find its migrations applied
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked); err != nil {
+		logger.Error("Failed to acquire migration lock", "error", err)
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !locked {
+		logger.Info("Another process is running migrations, waiting for it to finish")
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			logger.Error("Failed to acquire migration lock", "error", err)
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			logger.Error("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	return fn()
+}
+
+// PendingMigrations returns the migration files that have not been applied yet
+func (db *DB) PendingMigrations(ctx context.Context) ([]string, error) {
 	if err := db.createMigrationsTable(); err != nil {
-		logger.Error("Failed to create migrations table", "error", err)
-		return fmt.Errorf("failed to create migrations table: %w", err)
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	migrations, err := db.getMigrationFiles()
 	if err != nil {
-		logger.Error("Failed to get migration files", "error", err)
-		return fmt.Errorf("failed to get migration files: %w", err)
+		return nil, fmt.Errorf("failed to get migration files: %w", err)
 	}
 
-	logger.Info("Found migration files", "count", len(migrations))
-
+	pending := []string{}
 	for _, migration := range migrations {
-		if err := db.runMigration(migration); err != nil {
-			logger.Error("Failed to run migration", "migration", migration, "error", err)
-			return fmt.Errorf("failed to run migration %s: %w", migration, err)
+		migrationName := filepath.Base(migration)
+
+		var exists bool
+		err := db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", migrationName).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check migration status for %s: %w", migrationName, err)
+		}
+
+		if !exists {
+			pending = append(pending, migrationName)
 		}
 	}
 
-	logger.Info("All migrations completed successfully")
-	return nil
+	return pending, nil
+}
+
+// SchemaCurrent reports whether the newest applied migration is at least the newest migration
+// file this binary ships. Unlike PendingMigrations it never creates schema_migrations, so
+// readiness probes can call it without writing to the database.
+func (db *DB) SchemaCurrent(ctx context.Context) (bool, error) {
+	migrations, err := db.getMigrationFiles()
+	if err != nil {
+		return false, fmt.Errorf("failed to get migration files: %w", err)
+	}
+	if len(migrations) == 0 {
+		return true, nil
+	}
+	expected := filepath.Base(migrations[len(migrations)-1])
+
+	var applied sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&applied); err != nil {
+		return false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	// Versions start with a zero-padded number, so they sort by age
+	return applied.Valid && applied.String >= expected, nil
 }
 
 func (db *DB) createMigrationsTable() error {
@@ -71,7 +219,8 @@ func (db *DB) getMigrationFiles() ([]string, error) {
 			return err
 		}
 
-		if !d.IsDir() && strings.HasSuffix(path, ".sql") {
+		// Down files are only read on rollback
+		if !d.IsDir() && strings.HasSuffix(path, ".sql") && !strings.HasSuffix(path, downMigrationSuffix) {
 			migrations = append(migrations, path)
 			logger.Debug("Found migration file", "file", path)
 		}
@@ -89,7 +238,7 @@ func (db *DB) getMigrationFiles() ([]string, error) {
 	return migrations, nil
 }
 
-func (db *DB) runMigration(migrationFile string) error {
+func (db *DB) runMigration(migrationFile string) (bool, error) {
 	migrationName := filepath.Base(migrationFile)
 	logger := slog.With(
 		"component", "migrations",
@@ -102,19 +251,19 @@ func (db *DB) runMigration(migrationFile string) error {
 	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", migrationName).Scan(&exists)
 	if err != nil {
 		logger.Error("Failed to check migration status", "error", err)
-		return err
+		return false, err
 	}
 
 	if exists {
 		logger.Debug("Migration already applied, skipping")
-		return nil
+		return false, nil
 	}
 
 	// Read migration file
 	content, err := fs.ReadFile(os.DirFS("."), migrationFile)
 	if err != nil {
 		logger.Error("Failed to read migration file", "error", err)
-		return err
+		return false, err
 	}
 
 	logger.Info("Running migration", "size_bytes", len(content))
@@ -123,7 +272,7 @@ func (db *DB) runMigration(migrationFile string) error {
 	tx, err := db.Begin()
 	if err != nil {
 		logger.Error("Failed to begin transaction", "error", err)
-		return err
+		return false, err
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil && err.Error() != "sql: transaction has already been committed or rolled back" {
@@ -134,21 +283,73 @@ func (db *DB) runMigration(migrationFile string) error {
 	// Execute migration SQL
 	if _, err := tx.Exec(string(content)); err != nil {
 		logger.Error("Failed to execute migration SQL", "error", err)
-		return err
+		return false, err
 	}
 
 	// Record migration as applied
 	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", migrationName); err != nil {
 		logger.Error("Failed to record migration", "error", err)
-		return err
+		return false, err
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		logger.Error("Failed to commit migration transaction", "error", err)
-		return err
+		return false, err
 	}
 
 	logger.Info("Migration completed successfully")
+	return true, nil
+}
+
+// revertMigration runs the down file paired with version and forgets that it was applied
+func (db *DB) revertMigration(version string) error {
+	logger := slog.With(
+		"component", "migrations",
+		"operation", "revert_migration",
+		"migration", version,
+	)
+
+	name := strings.TrimSuffix(strings.TrimSuffix(version, upMigrationSuffix), ".sql")
+	downFile := filepath.Join("migrations", name+downMigrationSuffix)
+
+	content, err := fs.ReadFile(os.DirFS("."), downFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("migration %s is up-only and has no down file", version)
+	}
+	if err != nil {
+		logger.Error("Failed to read down migration file", "file", downFile, "error", err)
+		return err
+	}
+
+	logger.Info("Rolling back migration", "size_bytes", len(content))
+
+	tx, err := db.Begin()
+	if err != nil {
+		logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("Failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if _, err := tx.Exec(string(content)); err != nil {
+		logger.Error("Failed to execute down migration SQL", "error", err)
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", version); err != nil {
+		logger.Error("Failed to remove migration record", "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit rollback transaction", "error", err)
+		return err
+	}
+
+	logger.Info("Migration rolled back successfully")
 	return nil
 }
@@ -0,0 +1,125 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"planets-server/internal/shared/database/dbtest"
+)
+
+func TestRunMigrationsConcurrently(t *testing.T) {
+	db := dbtest.OpenEmpty(t)
+	t.Chdir("../../..")
+
+	files, err := filepath.Glob("migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want int
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".down.sql") {
+			want++
+		}
+	}
+
+	ctx := context.Background()
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	applied := make([][]string, 2)
+	errs := make([]error, 2)
+
+	for i := range applied {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			applied[i], errs[i] = db.RunMigrations(ctx)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("run %d failed: %v", i, err)
+		}
+	}
+
+	// Whichever run got the lock applies everything; the other finds nothing left to do
+	seen := map[string]bool{}
+	for _, run := range applied {
+		for _, version := range run {
+			if seen[version] {
+				t.Fatalf("migration %s was applied by both runs", version)
+			}
+			seen[version] = true
+		}
+	}
+	if len(seen) != want {
+		t.Fatalf("%d migrations applied, want %d", len(seen), want)
+	}
+	if len(applied[0]) != 0 && len(applied[1]) != 0 {
+		t.Fatalf("both runs applied migrations (%d and %d), the lock did not serialize them", len(applied[0]), len(applied[1]))
+	}
+
+	var recorded int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&recorded); err != nil {
+		t.Fatal(err)
+	}
+	if recorded != want {
+		t.Fatalf("schema_migrations has %d rows, want %d", recorded, want)
+	}
+
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("pending migrations after both runs: %v", pending)
+	}
+}
+
+func TestMigrationsRollBackAndReapply(t *testing.T) {
+	db := dbtest.OpenEmpty(t)
+	t.Chdir("../../..")
+	ctx := context.Background()
+
+	applied, err := db.RunMigrations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every migration but the initial schema rolls back, newest first
+	for i := len(applied) - 1; i > 0; i-- {
+		version, err := db.RollbackMigration(ctx)
+		if err != nil {
+			t.Fatalf("rolling back %s: %v", applied[i], err)
+		}
+		if version != applied[i] {
+			t.Fatalf("rolled back %s, want %s", version, applied[i])
+		}
+	}
+	if _, err := db.RollbackMigration(ctx); err == nil {
+		t.Fatalf("rolled back %s, want it to be up-only", applied[0])
+	}
+
+	// The down files left a schema the up files apply to cleanly again
+	reapplied, err := db.RunMigrations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reapplied) != len(applied)-1 {
+		t.Fatalf("reapplied %d migrations, want %d", len(reapplied), len(applied)-1)
+	}
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("pending migrations after reapplying: %v", pending)
+	}
+}
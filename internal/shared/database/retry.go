@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"net"
+	"time"
+
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes sent when the server drops or refuses the connection
+const (
+	pgConnectionExceptionClass              = "08"
+	pgAdminShutdown            pq.ErrorCode = "57P01"
+	pgCrashShutdown            pq.ErrorCode = "57P02"
+	pgCannotConnectNow         pq.ErrorCode = "57P03"
+)
+
+// readRetryDelay is the pause before each retry, multiplied by the attempt number
+const readRetryDelay = 100 * time.Millisecond
+
+// IsConnectionError reports whether err means the database connection was lost or refused,
+// as opposed to the query itself failing
+func IsConnectionError(err error) bool {
+	if stderrors.Is(err, driver.ErrBadConn) || stderrors.Is(err, sql.ErrConnDone) ||
+		stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pgAdminShutdown, pgCrashShutdown, pgCannotConnectNow:
+			return true
+		}
+		return pqErr.Code.Class() == pgConnectionExceptionClass
+	}
+
+	return false
+}
+
+// unavailable reports a lost connection as a temporary outage rather than an internal error
+func unavailable(err error) error {
+	return errors.WrapExternal("database temporarily unavailable", err)
+}
+
+// Read runs an idempotent read outside any transaction, retrying it up to DB_READ_RETRIES
+// times while the failure is a lost connection, so a database restart does not fail the
+// request. Writes must not go through Read, since a retry could apply them twice.
+func Read[T any](ctx context.Context, db *DB, read func() (T, error)) (T, error) {
+	result, err := read()
+	for attempt := 1; attempt <= db.readRetries && err != nil && IsConnectionError(err); attempt++ {
+		select {
+		case <-ctx.Done():
+			return result, unavailable(err)
+		case <-time.After(time.Duration(attempt) * readRetryDelay):
+		}
+
+		result, err = read()
+	}
+
+	if err != nil && IsConnectionError(err) {
+		return result, unavailable(err)
+	}
+	return result, err
+}
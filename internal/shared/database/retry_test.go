@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+// flakyRead fails with failure for the first failures calls, then returns "ok"
+func flakyRead(failures int, failure error) (read func() (string, error), calls *int) {
+	calls = new(int)
+	read = func() (string, error) {
+		*calls++
+		if *calls <= failures {
+			return "", failure
+		}
+		return "ok", nil
+	}
+	return read, calls
+}
+
+func TestReadRetriesABadConnection(t *testing.T) {
+	db := &DB{readRetries: 1}
+	read, calls := flakyRead(1, driver.ErrBadConn)
+
+	result, err := Read(context.Background(), db, read)
+	if err != nil || result != "ok" {
+		t.Fatalf("Read() = %q, %v; want the retried result", result, err)
+	}
+	if *calls != 2 {
+		t.Fatalf("read ran %d times, want 2", *calls)
+	}
+}
+
+func TestReadGivesUpAfterTheConfiguredRetries(t *testing.T) {
+	for _, retries := range []int{0, 2} {
+		db := &DB{readRetries: retries}
+		read, calls := flakyRead(10, fmt.Errorf("query: %w", driver.ErrBadConn))
+
+		_, err := Read(context.Background(), db, read)
+		if errors.GetType(err) != errors.ErrorTypeExternal {
+			t.Fatalf("%d retries: Read() error = %v, want database temporarily unavailable", retries, err)
+		}
+		if !stderrors.Is(err, driver.ErrBadConn) {
+			t.Fatalf("%d retries: Read() error = %v, want the connection error kept as its cause", retries, err)
+		}
+		if *calls != retries+1 {
+			t.Fatalf("%d retries: read ran %d times, want %d", retries, *calls, retries+1)
+		}
+	}
+}
+
+func TestReadDoesNotRetryQueryErrors(t *testing.T) {
+	db := &DB{readRetries: 3}
+	failure := errors.NotFoundf("planet not found with id: 7")
+	read, calls := flakyRead(1, failure)
+
+	if _, err := Read(context.Background(), db, read); err != failure {
+		t.Fatalf("Read() error = %v, want %v unchanged", err, failure)
+	}
+	if *calls != 1 {
+		t.Fatalf("read ran %d times, want 1", *calls)
+	}
+}
+
+func TestReadStopsRetryingWhenTheContextEnds(t *testing.T) {
+	db := &DB{readRetries: 5}
+	read, calls := flakyRead(10, driver.ErrBadConn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Read(ctx, db, read); errors.GetType(err) != errors.ErrorTypeExternal {
+		t.Fatalf("Read() error = %v, want database temporarily unavailable", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("read ran %d times after the context ended, want 1", *calls)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{driver.ErrBadConn, true},
+		{sql.ErrConnDone, true},
+		{io.ErrUnexpectedEOF, true},
+		{fmt.Errorf("read: %w", io.EOF), true},
+		{&net.OpError{Op: "dial", Err: stderrors.New("connection refused")}, true},
+		{&pq.Error{Code: "57P01"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "23505"}, false},
+		{sql.ErrNoRows, false},
+		{stderrors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsConnectionError(tt.err); got != tt.want {
+			t.Errorf("IsConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package errors
+
+// Machine-readable error codes, sent to clients alongside the broad error type so they
+// can tell apart failures that share a status code.
+const (
+	CodeAlreadyExists          = "already_exists"
+	CodeRegistrationDenied     = "registration_denied"
+	CodeNotPlanetOwner         = "not_planet_owner"
+	CodePlanetAlreadyOwned     = "planet_already_owned"
+	CodeSameOwner              = "same_owner"
+	CodePlayerNotInGame        = "player_not_in_game"
+	CodeInsufficientPopulation = "insufficient_population"
+	CodeMaxDefenseExceeded     = "max_defense_exceeded"
+	CodeGameFinished           = "game_finished"
+	CodeGameAlreadyStarted     = "game_already_started"
+	CodeSettingImmutable       = "setting_immutable"
+	CodeTooManyPlayers         = "too_many_players"
+	CodeSystemOutOfReach       = "system_out_of_reach"
+	CodeGameFull               = "game_full"
+	CodeNoHomePlanet           = "no_home_planet"
+	CodeMaxBuildingLevel       = "max_building_level"
+	CodeTechLocked             = "tech_locked"
+	CodeTerraformNotAllowed    = "terraform_not_allowed"
+	CodeEmailUnverified        = "email_unverified"
+	CodeNotGameMaster          = "not_game_master"
+	CodeTooManySubscribers     = "too_many_subscribers"
+)
@@ -21,7 +21,9 @@ const (
 type AppError struct {
 	Type    ErrorType
 	Message string
-	Err     error
+	// Code optionally identifies the specific failure within its type, see codes.go
+	Code string
+	Err  error
 }
 
 func (e *AppError) Error() string {
@@ -71,6 +73,14 @@ func Conflictf(format string, args ...interface{}) error {
 	}
 }
 
+func WrapConflict(message string, err error) error {
+	return &AppError{
+		Type:    ErrorTypeConflict,
+		Message: message,
+		Err:     err,
+	}
+}
+
 func WrapInternal(message string, err error) error {
 	return &AppError{
 		Type:    ErrorTypeInternal,
@@ -115,6 +125,31 @@ func WrapExternal(message string, err error) error {
 	}
 }
 
+// WithCode attaches a machine-readable code to err without changing its type
+func WithCode(err error, code string) error {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		coded := *appErr
+		coded.Code = code
+		return &coded
+	}
+	return &AppError{
+		Type:    ErrorTypeInternal,
+		Message: err.Error(),
+		Code:    code,
+		Err:     err,
+	}
+}
+
+// GetCode returns the error's code, or "" when it has none
+func GetCode(err error) string {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
 func GetType(err error) ErrorType {
 	var appErr *AppError
 	if errors.As(err, &appErr) {
@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Closer is a component with background work to stop at shutdown
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// CloserFunc adapts a function to Closer
+type CloserFunc func(ctx context.Context) error
+
+func (f CloserFunc) Close(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedCloser struct {
+	name   string
+	closer Closer
+}
+
+// Registry collects the components to close at shutdown. They are closed in reverse
+// registration order, so a component is closed before those it was built on.
+type Registry struct {
+	mu      sync.Mutex
+	closers []namedCloser
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a component to close at shutdown; name identifies it in logs
+func (r *Registry) Register(name string, closer Closer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closers = append(r.closers, namedCloser{name: name, closer: closer})
+}
+
+// Close closes every registered component, continuing past failures, and returns them
+// joined. The registry is emptied, so a second call does nothing.
+func (r *Registry) Close(ctx context.Context) error {
+	r.mu.Lock()
+	closers := r.closers
+	r.closers = nil
+	r.mu.Unlock()
+
+	logger := slog.With("component", "lifecycle")
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		c := closers[i]
+		if err := c.closer.Close(ctx); err != nil {
+			logger.Error("Failed to close component", "name", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		logger.Debug("Closed component", "name", c.name)
+	}
+
+	return errors.Join(errs...)
+}
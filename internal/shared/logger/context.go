@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	loggerKey    contextKey = "logger"
+)
+
+// WithRequestID stores the request ID on ctx together with a logger that tags every
+// line with it, so log lines from all layers of one request can be correlated
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, loggerKey, slog.Default().With("request_id", requestID))
+}
+
+// RequestID returns the request ID stored on ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// FromContext returns the request-scoped logger, or the default logger outside a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
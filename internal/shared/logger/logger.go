@@ -1,11 +1,16 @@
 package logger
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"planets-server/internal/shared/config"
 )
 
+// level backs the default handler so the log level can change without a restart.
+// Runtime changes are not persisted; a restart returns to LOG_LEVEL.
+var level slog.LevelVar
+
 func Init() {
 	if config.GlobalConfig == nil {
 		panic("config must be initialized before logger")
@@ -14,15 +19,15 @@ func Init() {
 	logConfig := config.GlobalConfig.Logging
 	var handler slog.Handler
 
-	level := parseLogLevel(logConfig.Level)
+	level.Set(parseLogLevel(logConfig.Level))
 
 	if logConfig.JSONFormat {
 		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
+			Level: &level,
 		})
 	} else {
 		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
+			Level: &level,
 		})
 	}
 
@@ -32,10 +37,34 @@ func Init() {
 	logger.Debug("Logger initialized",
 		"level", logConfig.Level,
 		"json_format", logConfig.JSONFormat,
+		"format_override", logConfig.Format,
 		"environment", config.GlobalConfig.Server.Environment,
 	)
 }
 
+// Level returns the active log level name
+func Level() string {
+	return levelNames[level.Level()]
+}
+
+// SetLevel changes the active log level until the next restart
+func SetLevel(name string) error {
+	for lvl, levelName := range levelNames {
+		if levelName == name {
+			level.Set(lvl)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+var levelNames = map[slog.Level]string{
+	slog.LevelDebug: "debug",
+	slog.LevelInfo:  "info",
+	slog.LevelWarn:  "warn",
+	slog.LevelError: "error",
+}
+
 func parseLogLevel(levelStr string) slog.Level {
 	switch levelStr {
 	case "debug":
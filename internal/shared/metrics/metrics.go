@@ -0,0 +1,77 @@
+// Package metrics holds the Prometheus collectors the server exports on /metrics
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "planets"
+
+// HTTP metrics are labeled by route pattern rather than path, so IDs in paths cannot
+// create a series per resource
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time spent serving HTTP requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	HTTPRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served, by method and route.",
+	}, []string{"method", "route"})
+)
+
+// Game metrics
+var (
+	TurnsProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "turns_processed_total",
+		Help:      "Game turns advanced by the turn scheduler.",
+	})
+
+	TurnDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "turn_duration_seconds",
+		Help:      "Time spent processing a single game turn.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	GenerationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "generation_duration_seconds",
+		Help:      "Time spent generating universe content, by stage.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"stage"})
+)
+
+// Event metrics
+var (
+	EventSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_subscribers",
+		Help:      "Live event streams currently subscribed to a game.",
+	})
+)
+
+// Stages of GenerationDuration
+const (
+	StageSpatialEntities = "spatial_entities"
+	StagePlanets         = "planets"
+)
+
+// ObserveSince records the time elapsed since start on observer, for use in a defer
+func ObserveSince(observer prometheus.Observer, start time.Time) {
+	observer.Observe(time.Since(start).Seconds())
+}
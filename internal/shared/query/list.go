@@ -0,0 +1,200 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"planets-server/internal/shared/errors"
+)
+
+// Filter maps a query parameter to the column it filters on.
+// Parse converts and validates the raw value; nil passes it through as a string.
+// Op is the comparison operator, equality when empty.
+type Filter struct {
+	Column string
+	Op     string
+	Parse  func(string) (any, error)
+}
+
+// Comparison operators for Filter.Op
+const (
+	OpGreaterOrEqual = ">="
+	OpLessOrEqual    = "<="
+)
+
+type appliedFilter struct {
+	param  string
+	column string
+	op     string
+	value  any
+}
+
+// ListParams holds the validated sort and filter options of a list request.
+// Column names only ever come from the caller's allowlists, never from the request.
+type ListParams struct {
+	sortColumn string
+	descending bool
+	filters    []appliedFilter
+}
+
+// ParseListParams reads ?sort=<field>&order=asc|desc plus any allowed filter parameters.
+// allowedSortFields maps public field names to column names. Parameters that are not
+// in allowedFilters are ignored, but an unknown sort field or order is rejected.
+func ParseListParams(r *http.Request, allowedSortFields map[string]string, allowedFilters map[string]Filter) (ListParams, error) {
+	var params ListParams
+	values := r.URL.Query()
+
+	if field := values.Get("sort"); field != "" {
+		column, ok := allowedSortFields[field]
+		if !ok {
+			return ListParams{}, errors.Validationf("unknown sort field: %s", field)
+		}
+		params.sortColumn = column
+	}
+
+	switch order := strings.ToLower(values.Get("order")); order {
+	case "", "asc":
+	case "desc":
+		params.descending = true
+	default:
+		return ListParams{}, errors.Validationf("invalid sort order: %s", order)
+	}
+
+	names := make([]string, 0, len(allowedFilters))
+	for name := range allowedFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+
+		filter := allowedFilters[name]
+		var value any = raw
+		if filter.Parse != nil {
+			parsed, err := filter.Parse(raw)
+			if err != nil {
+				return ListParams{}, errors.WrapValidation(fmt.Sprintf("invalid value for %s", name), err)
+			}
+			value = parsed
+		}
+
+		op := filter.Op
+		if op == "" {
+			op = "="
+		}
+
+		params.filters = append(params.filters, appliedFilter{param: name, column: filter.Column, op: op, value: value})
+	}
+
+	return params, nil
+}
+
+// OrderBy returns the requested ORDER BY expression, or fallback when no sort was requested
+func (p ListParams) OrderBy(fallback string) string {
+	if p.sortColumn == "" {
+		return fallback
+	}
+
+	if p.descending {
+		return p.sortColumn + " DESC"
+	}
+	return p.sortColumn + " ASC"
+}
+
+// Where returns the filter conditions joined with AND, numbering placeholders from
+// firstArg so they can follow the caller's own arguments. It returns "" when no filter applies.
+func (p ListParams) Where(firstArg int) (string, []any) {
+	if len(p.filters) == 0 {
+		return "", nil
+	}
+
+	conditions := make([]string, len(p.filters))
+	args := make([]any, len(p.filters))
+	for i, filter := range p.filters {
+		conditions[i] = fmt.Sprintf("%s %s $%d", filter.column, filter.op, firstArg+i)
+		args[i] = filter.value
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// Key identifies the parameter set, for use as a cache or request-coalescing key
+func (p ListParams) Key() string {
+	parts := []string{p.OrderBy("default")}
+	for _, filter := range p.filters {
+		parts = append(parts, fmt.Sprintf("%s%s%v", filter.param, filter.op, filter.value))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Int parses an integer filter value
+func Int(raw string) (any, error) {
+	return strconv.Atoi(raw)
+}
+
+// OneOf accepts only the listed filter values
+func OneOf(allowed ...string) func(string) (any, error) {
+	return func(raw string) (any, error) {
+		for _, value := range allowed {
+			if raw == value {
+				return raw, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of: %s", strings.Join(allowed, ", "))
+	}
+}
+
+// Pagination defaults for list endpoints that page their results
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// Page is the validated ?limit=&offset= window of a paginated list
+type Page struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// ParsePage reads ?limit=<1..MaxPageLimit>&offset=<n>, defaulting to the first DefaultPageLimit rows
+func ParsePage(r *http.Request) (Page, error) {
+	page := Page{Limit: DefaultPageLimit}
+	values := r.URL.Query()
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxPageLimit {
+			return Page{}, errors.Validationf("limit must be between 1 and %d", MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return Page{}, errors.Validation("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// Clause returns the LIMIT/OFFSET clause, numbering placeholders from firstArg
+func (p Page) Clause(firstArg int) (string, []any) {
+	return fmt.Sprintf("LIMIT $%d OFFSET $%d", firstArg, firstArg+1), []any{p.Limit, p.Offset}
+}
+
+// HasMore reports whether rows remain past this page out of total matching rows.
+// An offset beyond total yields an empty page with HasMore false, which clients can
+// tell apart from a short last page by comparing offset and total.
+func (p Page) HasMore(total int) bool {
+	return p.Offset+p.Limit < total
+}
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"planets-server/internal/shared/errors"
+)
+
+var (
+	testSortFields = map[string]string{
+		"name":       "p.name",
+		"population": "p.population",
+	}
+	testFilters = map[string]Filter{
+		"type":           {Column: "p.type", Parse: OneOf("ice", "terrestrial")},
+		"min_population": {Column: "p.population", Op: OpGreaterOrEqual, Parse: Int},
+		"owner":          {Column: "p.owner_name"},
+	}
+)
+
+// parse runs ParseListParams on a query written unescaped, as name=value pairs joined by &
+func parse(t *testing.T, rawQuery string) (ListParams, error) {
+	t.Helper()
+
+	values := url.Values{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if name, value, ok := strings.Cut(pair, "="); ok {
+			values.Add(name, value)
+		}
+	}
+
+	return ParseListParams(httptest.NewRequest("GET", "/list?"+values.Encode(), nil), testSortFields, testFilters)
+}
+
+func TestParseListParamsRejectsUnknownSortFields(t *testing.T) {
+	for _, rawQuery := range []string{
+		"sort=created_at",
+		"sort=p.name",
+		"sort=name;DROP TABLE players",
+		"sort=population DESC, id",
+		"sort=NAME",
+	} {
+		_, err := parse(t, rawQuery)
+		if errors.GetType(err) != errors.ErrorTypeValidation {
+			t.Errorf("%s: got %v, want a validation error", rawQuery, err)
+		}
+	}
+}
+
+func TestParseListParamsRejectsUnknownOrder(t *testing.T) {
+	for _, rawQuery := range []string{"sort=name&order=sideways", "order=desc;--"} {
+		if _, err := parse(t, rawQuery); errors.GetType(err) != errors.ErrorTypeValidation {
+			t.Errorf("%s: got %v, want a validation error", rawQuery, err)
+		}
+	}
+}
+
+func TestParseListParamsMapsSortFieldsToColumns(t *testing.T) {
+	tests := []struct {
+		rawQuery string
+		want     string
+	}{
+		{"", "fallback"},
+		{"sort=name", "p.name ASC"},
+		{"sort=name&order=asc", "p.name ASC"},
+		{"sort=population&order=desc", "p.population DESC"},
+		{"sort=population&order=DESC", "p.population DESC"},
+	}
+
+	for _, tt := range tests {
+		params, err := parse(t, tt.rawQuery)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.rawQuery, err)
+		}
+		if got := params.OrderBy("fallback"); got != tt.want {
+			t.Errorf("%s: OrderBy() = %q, want %q", tt.rawQuery, got, tt.want)
+		}
+	}
+}
+
+func TestParseListParamsBuildsParameterizedFilters(t *testing.T) {
+	params, err := parse(t, "type=ice&min_population=500&owner=x' OR '1'='1&unlisted=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	where, args := params.Where(3)
+
+	// Filters come out in name order, and values only ever travel as arguments
+	wantWhere := "p.population >= $3 AND p.owner_name = $4 AND p.type = $5"
+	if where != wantWhere {
+		t.Fatalf("Where() = %q, want %q", where, wantWhere)
+	}
+	wantArgs := []any{500, "x' OR '1'='1", "ice"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestParseListParamsValidatesFilterValues(t *testing.T) {
+	for _, rawQuery := range []string{"type=lava", "min_population=lots"} {
+		if _, err := parse(t, rawQuery); errors.GetType(err) != errors.ErrorTypeValidation {
+			t.Errorf("%s: got %v, want a validation error", rawQuery, err)
+		}
+	}
+}
+
+func TestListParamsWithoutFilters(t *testing.T) {
+	params, err := parse(t, "unlisted=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if where, args := params.Where(1); where != "" || args != nil {
+		t.Fatalf("Where() = %q, %v; want nothing for unlisted parameters", where, args)
+	}
+}
+
+func TestListParamsKeyDistinguishesParameterSets(t *testing.T) {
+	queries := []string{"", "sort=name", "sort=name&order=desc", "type=ice", "type=terrestrial", "min_population=5"}
+
+	keys := map[string]string{}
+	for _, rawQuery := range queries {
+		params, err := parse(t, rawQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := params.Key()
+		if other, ok := keys[key]; ok {
+			t.Fatalf("%q and %q share the key %q", other, rawQuery, key)
+		}
+		keys[key] = rawQuery
+	}
+}
+
+func TestPageHasMore(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  Page
+		total int
+		want  bool
+	}{
+		{"rows past the page", Page{Limit: 10}, 25, true},
+		{"page ends on the last row", Page{Limit: 10, Offset: 15}, 25, false},
+		{"short last page", Page{Limit: 10, Offset: 20}, 25, false},
+		{"offset at total", Page{Limit: 10, Offset: 25}, 25, false},
+		{"offset beyond total", Page{Limit: 10, Offset: 100}, 25, false},
+		{"no rows", Page{Limit: 10}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.HasMore(tt.total); got != tt.want {
+				t.Fatalf("%+v.HasMore(%d) = %v, want %v", tt.page, tt.total, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+
+	"planets-server/internal/shared/config"
+)
+
+// SetCacheControl lets browsers and CDNs cache a response for the configured max-age
+// when the underlying data can no longer change, and disables caching otherwise.
+// Private responses are restricted to the browser cache since they sit behind authentication.
+func SetCacheControl(w http.ResponseWriter, immutable bool, private bool) {
+	maxAge := 0
+	if cfg := config.GlobalConfig; cfg != nil {
+		maxAge = int(cfg.Cache.ImmutableMaxAge.Seconds())
+	}
+
+	if !immutable || maxAge <= 0 {
+		w.Header().Set("Cache-Control", "no-store")
+		return
+	}
+
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, maxAge))
+}
@@ -5,14 +5,17 @@ import (
 	"log/slog"
 	"net/http"
 
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/errors"
+	sharedLogger "planets-server/internal/shared/logger"
 )
 
 // ErrorResponse represents the JSON error response sent to clients
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Message   string `json:"message"`
+	Code      int    `json:"code"`
 }
 
 // Error logs an error and sends a JSON error response to the client
@@ -25,7 +28,7 @@ func Error(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err erro
 	logError(logger, r, err, errorType, statusCode)
 
 	// Send JSON error response
-	sendErrorResponse(w, errorType, err.Error(), statusCode)
+	sendErrorResponse(w, errorType, errors.GetCode(err), err.Error(), statusCode)
 }
 
 // mapErrorTypeToStatusCode maps error types to HTTP status codes
@@ -62,6 +65,9 @@ func logError(logger *slog.Logger, r *http.Request, err error, errorType errors.
 		"error_type", errorType,
 		"status_code", statusCode,
 	)
+	if requestID := sharedLogger.RequestID(r.Context()); requestID != "" {
+		logCtx = logCtx.With("request_id", requestID)
+	}
 
 	// Log at appropriate level based on error type
 	switch errorType {
@@ -94,29 +100,64 @@ func setCommonHeaders(w http.ResponseWriter) {
 }
 
 // sendErrorResponse sends a JSON error response to the client
-func sendErrorResponse(w http.ResponseWriter, errorType errors.ErrorType, message string, statusCode int) {
+func sendErrorResponse(w http.ResponseWriter, errorType errors.ErrorType, errorCode string, message string, statusCode int) {
 	setCommonHeaders(w)
 	w.WriteHeader(statusCode)
 
 	response := ErrorResponse{
-		Error:   string(errorType),
-		Message: message,
-		Code:    statusCode,
+		Error:     string(errorType),
+		ErrorCode: errorCode,
+		Message:   message,
+		Code:      statusCode,
 	}
 
 	// If JSON encoding fails, there's not much we can do at this point
 	// The status code has already been sent
-	_ = json.NewEncoder(w).Encode(response)
+	_ = newEncoder(w).Encode(response)
+}
+
+// Envelope is the success body shape when RESPONSE_ENVELOPE is enabled. Clients always
+// find the payload under data, whether it is an object or a list.
+type Envelope struct {
+	Data any          `json:"data"`
+	Meta EnvelopeMeta `json:"meta"`
 }
 
-// Success sends a JSON success response to the client
+type EnvelopeMeta struct {
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// requestIDHeader is set on the response by the request ID middleware, which cannot be
+// imported here without a cycle
+const requestIDHeader = "X-Request-ID"
+
+// Success sends a JSON success response to the client. Bodies are sent bare unless
+// RESPONSE_ENVELOPE is enabled, in which case they are wrapped in an Envelope.
 func Success(w http.ResponseWriter, statusCode int, data interface{}) {
 	setCommonHeaders(w)
 	w.WriteHeader(statusCode)
 
-	if data != nil {
-		// If JSON encoding fails, there's not much we can do at this point
-		// The status code has already been sent
-		_ = json.NewEncoder(w).Encode(data)
+	if data == nil {
+		return
+	}
+
+	if cfg := config.GlobalConfig; cfg != nil && cfg.Server.ResponseEnvelope {
+		data = Envelope{
+			Data: data,
+			Meta: EnvelopeMeta{RequestID: w.Header().Get(requestIDHeader)},
+		}
+	}
+
+	// If JSON encoding fails, there's not much we can do at this point
+	// The status code has already been sent
+	_ = newEncoder(w).Encode(data)
+}
+
+// newEncoder returns a JSON encoder that indents output when PRETTY_JSON is enabled
+func newEncoder(w http.ResponseWriter) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if cfg := config.GlobalConfig; cfg != nil && cfg.Server.PrettyJSON {
+		encoder.SetIndent("", "  ")
 	}
+	return encoder
 }
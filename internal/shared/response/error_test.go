@@ -0,0 +1,98 @@
+package response_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"planets-server/internal/middleware"
+	"planets-server/internal/shared/config"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/response"
+)
+
+func useEnvelope(t *testing.T, enabled bool) {
+	t.Helper()
+
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{Server: config.ServerConfig{ResponseEnvelope: enabled}}
+	t.Cleanup(func() { config.GlobalConfig = previous })
+}
+
+// respond runs handler behind the request ID middleware and returns the response
+func respond(handler http.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/games", nil)
+	r.Header.Set(middleware.RequestIDHeader, "req-123")
+	middleware.RequestID(handler).ServeHTTP(w, r)
+	return w
+}
+
+func TestSuccessShapes(t *testing.T) {
+	type game struct {
+		ID int `json:"id"`
+	}
+
+	tests := []struct {
+		name     string
+		envelope bool
+		data     any
+		want     string
+	}{
+		{"bare object", false, game{ID: 1}, `{"id":1}`},
+		{"bare list", false, []game{{ID: 1}, {ID: 2}}, `[{"id":1},{"id":2}]`},
+		{"bare empty list", false, []game{}, `[]`},
+		{"enveloped object", true, game{ID: 1}, `{"data":{"id":1},"meta":{"request_id":"req-123"}}`},
+		{"enveloped list", true, []game{{ID: 1}, {ID: 2}}, `{"data":[{"id":1},{"id":2}],"meta":{"request_id":"req-123"}}`},
+		{"enveloped empty list", true, []game{}, `{"data":[],"meta":{"request_id":"req-123"}}`},
+		{"no body", false, nil, ``},
+		{"no body enveloped", true, nil, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useEnvelope(t, tt.envelope)
+
+			w := respond(func(w http.ResponseWriter, r *http.Request) {
+				response.Success(w, http.StatusOK, tt.data)
+			})
+
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+				t.Fatalf("status %d with Content-Type %q, want 200 JSON", w.Code, w.Header().Get("Content-Type"))
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Fatalf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeWithoutRequestID(t *testing.T) {
+	useEnvelope(t, true)
+
+	w := httptest.NewRecorder()
+	response.Success(w, http.StatusCreated, map[string]int{"id": 1})
+
+	if got, want := strings.TrimSpace(w.Body.String()), `{"data":{"id":1},"meta":{}}`; w.Code != http.StatusCreated || got != want {
+		t.Fatalf("got %d %s, want 201 %s", w.Code, got, want)
+	}
+}
+
+func TestErrorsAreNeverEnveloped(t *testing.T) {
+	for _, envelope := range []bool{false, true} {
+		useEnvelope(t, envelope)
+
+		w := respond(func(w http.ResponseWriter, r *http.Request) {
+			err := errors.WithCode(errors.Conflictf("game is full (max players: %d)", 4), errors.CodeGameFull)
+			response.Error(w, r, slog.New(slog.NewTextHandler(io.Discard, nil)), err)
+		})
+
+		want := `{"error":"conflict","error_code":"game_full","message":"game is full (max players: 4)","code":409}`
+		if got := strings.TrimSpace(w.Body.String()); w.Code != http.StatusConflict || got != want {
+			t.Fatalf("envelope %v: got %d %s, want 409 %s", envelope, w.Code, got, want)
+		}
+	}
+}
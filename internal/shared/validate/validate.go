@@ -0,0 +1,39 @@
+package validate
+
+import (
+	"planets-server/internal/shared/errors"
+)
+
+// NonNegative requires n >= 0
+func NonNegative(field string, n int) error {
+	if n < 0 {
+		return errors.Validationf("%s must not be negative", field)
+	}
+	return nil
+}
+
+// Positive requires n >= 1
+func Positive(field string, n int) error {
+	if n < 1 {
+		return errors.Validationf("%s must be at least 1", field)
+	}
+	return nil
+}
+
+// InRange requires min <= n <= max
+func InRange(field string, n, min, max int) error {
+	if n < min || n > max {
+		return errors.Validationf("%s must be between %d and %d", field, min, max)
+	}
+	return nil
+}
+
+// First returns the first failed check, so a sequence of checks reads as one expression
+func First(checks ...error) error {
+	for _, err := range checks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
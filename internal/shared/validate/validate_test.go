@@ -0,0 +1,58 @@
+package validate
+
+import (
+	"testing"
+
+	"planets-server/internal/shared/errors"
+)
+
+func TestChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{"non-negative zero", NonNegative("x_coord", 0), ""},
+		{"non-negative positive", NonNegative("x_coord", 7), ""},
+		{"non-negative negative", NonNegative("x_coord", -1), "x_coord must not be negative"},
+		{"positive one", Positive("count", 1), ""},
+		{"positive zero", Positive("count", 0), "count must be at least 1"},
+		{"positive negative", Positive("count", -3), "count must be at least 1"},
+		{"in range lower bound", InRange("radius", 1, 1, 10), ""},
+		{"in range upper bound", InRange("radius", 10, 1, 10), ""},
+		{"below range", InRange("radius", 0, 1, 10), "radius must be between 1 and 10"},
+		{"above range", InRange("radius", 11, 1, 10), "radius must be between 1 and 10"},
+	}
+
+	for _, tt := range tests {
+		if tt.wantErr == "" {
+			if tt.err != nil {
+				t.Errorf("%s: got %v, want no error", tt.name, tt.err)
+			}
+			continue
+		}
+
+		if errors.GetType(tt.err) != errors.ErrorTypeValidation {
+			t.Errorf("%s: got %v, want a validation error", tt.name, tt.err)
+			continue
+		}
+		if tt.err.Error() != tt.wantErr {
+			t.Errorf("%s: message = %q, want %q", tt.name, tt.err, tt.wantErr)
+		}
+	}
+}
+
+func TestFirstReturnsTheFirstFailure(t *testing.T) {
+	first := NonNegative("a", -1)
+	second := Positive("b", 0)
+
+	if err := First(nil, first, second); err != first {
+		t.Fatalf("First() = %v, want %v", err, first)
+	}
+	if err := First(NonNegative("a", 0), Positive("b", 1)); err != nil {
+		t.Fatalf("First() = %v, want nil when every check passes", err)
+	}
+	if err := First(); err != nil {
+		t.Fatalf("First() = %v, want nil without checks", err)
+	}
+}
@@ -0,0 +1,59 @@
+package spatial
+
+import "math"
+
+// Coordinate is a grid cell within a parent entity
+type Coordinate struct {
+	X int
+	Y int
+}
+
+// allocateCoordinates returns count free grid cells for a parent whose children
+// already occupy the given cells. The grid is the smallest square that fits the
+// existing and new children, filled column by column and grown when occupied
+// cells leave too few gaps. An empty parent gets the same layout as a fresh grid.
+func allocateCoordinates(occupied map[Coordinate]bool, count int) []Coordinate {
+	if count <= 0 {
+		return []Coordinate{}
+	}
+
+	side := int(math.Ceil(math.Sqrt(float64(len(occupied) + count))))
+	coords := make([]Coordinate, 0, count)
+	taken := make(map[Coordinate]bool, len(occupied)+count)
+	for c := range occupied {
+		taken[c] = true
+	}
+
+	for len(coords) < count {
+		for x := 0; x < side && len(coords) < count; x++ {
+			for y := 0; y < side && len(coords) < count; y++ {
+				c := Coordinate{X: x, Y: y}
+				if taken[c] {
+					continue
+				}
+				taken[c] = true
+				coords = append(coords, c)
+			}
+		}
+		side++
+	}
+
+	return coords
+}
+
+// gridSide is the side of the square grid a parent lays its children out on
+func gridSide(parent SpatialEntity) int {
+	return max(int(math.Ceil(math.Sqrt(float64(parent.ChildCount)))), 1)
+}
+
+// compositePosition places the last entity of path, a chain of ancestors ending at the
+// entity, on the grid of path[0]'s children. Each level subdivides its parent's cell, so
+// the result is measured in cells of the entity's own level.
+func compositePosition(path []SpatialEntity) (x, y float64) {
+	for i := 1; i < len(path); i++ {
+		side := float64(gridSide(path[i-1]))
+		x = x*side + float64(path[i].XCoord)
+		y = y*side + float64(path[i].YCoord)
+	}
+	return x, y
+}
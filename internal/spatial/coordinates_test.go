@@ -0,0 +1,70 @@
+package spatial
+
+import "testing"
+
+func TestAllocateCoordinatesFreshGrid(t *testing.T) {
+	coords := allocateCoordinates(nil, 4)
+
+	want := []Coordinate{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	if len(coords) != len(want) {
+		t.Fatalf("got %d coordinates, want %d", len(coords), len(want))
+	}
+	for i, c := range want {
+		if coords[i] != c {
+			t.Fatalf("coordinate %d = %v, want %v", i, coords[i], c)
+		}
+	}
+}
+
+func TestAllocateCoordinatesSkipsOccupiedCells(t *testing.T) {
+	occupied := map[Coordinate]bool{}
+	for _, c := range allocateCoordinates(nil, 5) {
+		occupied[c] = true
+	}
+
+	// Several rounds of additions must never reuse a cell
+	for round := 0; round < 4; round++ {
+		added := allocateCoordinates(occupied, 3)
+		if len(added) != 3 {
+			t.Fatalf("round %d: got %d coordinates, want 3", round, len(added))
+		}
+		for _, c := range added {
+			if occupied[c] {
+				t.Fatalf("round %d: cell %v allocated twice", round, c)
+			}
+			occupied[c] = true
+		}
+	}
+}
+
+func TestAllocateCoordinatesGrowsPastAFullGrid(t *testing.T) {
+	occupied := map[Coordinate]bool{}
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			occupied[Coordinate{x, y}] = true
+		}
+	}
+
+	added := allocateCoordinates(occupied, 1)
+	if len(added) != 1 || occupied[added[0]] {
+		t.Fatalf("got %v, want one free cell outside the full 3x3 grid", added)
+	}
+}
+
+func TestAllocateCoordinatesFillsGaps(t *testing.T) {
+	occupied := map[Coordinate]bool{{0, 0}: true, {1, 1}: true}
+
+	added := allocateCoordinates(occupied, 2)
+
+	for _, c := range added {
+		if c.X > 1 || c.Y > 1 {
+			t.Fatalf("allocated %v although the 2x2 grid still had free cells", c)
+		}
+	}
+}
+
+func TestAllocateCoordinatesZeroCount(t *testing.T) {
+	if coords := allocateCoordinates(nil, 0); len(coords) != 0 {
+		t.Fatalf("got %v, want no coordinates", coords)
+	}
+}
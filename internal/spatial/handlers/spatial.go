@@ -1,13 +1,16 @@
 package handlers
 
 import (
-	"log/slog"
 	"net/http"
 	"strconv"
 
+	"planets-server/internal/middleware"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
 	"planets-server/internal/shared/response"
+	"planets-server/internal/shared/validate"
 	"planets-server/internal/spatial"
+	"planets-server/internal/visibility"
 )
 
 type SpatialHandler struct {
@@ -20,7 +23,7 @@ func NewSpatialHandler(service *spatial.Service) *SpatialHandler {
 
 func (h *SpatialHandler) GetChildren(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "get_children")
+	logger := logger.FromContext(r.Context()).With("handler", "get_children")
 
 	if r.Method != http.MethodGet {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -39,7 +42,14 @@ func (h *SpatialHandler) GetChildren(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	children, err := h.service.GetChildren(ctx, entityID)
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+	viewer := visibility.Viewer{PlayerID: claims.PlayerID, Admin: claims.Role == "admin"}
+
+	children, err := h.service.GetChildren(ctx, entityID, viewer)
 	if err != nil {
 		response.Error(w, r, logger, err)
 		return
@@ -49,12 +59,19 @@ func (h *SpatialHandler) GetChildren(w http.ResponseWriter, r *http.Request) {
 		children = []spatial.SpatialEntity{}
 	}
 
+	completed, err := h.service.IsGameCompleted(ctx, entityID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, completed, true)
 	response.Success(w, http.StatusOK, children)
 }
 
 func (h *SpatialHandler) GetAncestors(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "get_ancestors")
+	logger := logger.FromContext(r.Context()).With("handler", "get_ancestors")
 
 	if r.Method != http.MethodGet {
 		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
@@ -83,5 +100,66 @@ func (h *SpatialHandler) GetAncestors(w http.ResponseWriter, r *http.Request) {
 		ancestors = []spatial.SpatialEntity{}
 	}
 
+	completed, err := h.service.IsGameCompleted(ctx, entityID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, completed, true)
 	response.Success(w, http.StatusOK, ancestors)
 }
+
+func (h *SpatialHandler) GetEntityAtCoord(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "get_entity_at_coord")
+
+	if r.Method != http.MethodGet {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	parentIDStr := r.PathValue("id")
+	if parentIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("entity ID is required"))
+		return
+	}
+
+	parentID, err := strconv.Atoi(parentIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid entity ID format", err))
+		return
+	}
+
+	x, err := strconv.Atoi(r.URL.Query().Get("x"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("x must be an integer", err))
+		return
+	}
+
+	y, err := strconv.Atoi(r.URL.Query().Get("y"))
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("y must be an integer", err))
+		return
+	}
+
+	if err := validate.First(validate.NonNegative("x", x), validate.NonNegative("y", y)); err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	entity, err := h.service.GetEntityAtCoord(ctx, parentID, x, y)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	completed, err := h.service.IsGameCompleted(ctx, parentID)
+	if err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.SetCacheControl(w, completed, true)
+	response.Success(w, http.StatusOK, entity)
+}
@@ -30,6 +30,8 @@ type SpatialEntity struct {
 	YCoord      int        `json:"y_coord"`
 	Name       string     `json:"name"`
 	ChildCount int        `json:"child_count"`
+	// Unexplored marks a system the viewer hasn't discovered, with its contents withheld
+	Unexplored  bool       `json:"unexplored,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
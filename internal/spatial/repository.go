@@ -3,6 +3,7 @@ package spatial
 import (
 	"context"
 	"database/sql"
+	"planets-server/internal/shared/config"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
 
@@ -11,14 +12,27 @@ import (
 
 type Repository struct {
 	db *database.DB
+	// copyThreshold is the batch size from which inserts switch to COPY; zero disables COPY
+	copyThreshold int
 }
 
 func NewRepository(db *database.DB) *Repository {
+	var copyThreshold int
+	if cfg := config.GlobalConfig; cfg != nil {
+		copyThreshold = cfg.Database.CopyThreshold
+	}
+
 	return &Repository{
-		db: db,
+		db:            db,
+		copyThreshold: copyThreshold,
 	}
 }
 
+// useCopy reports whether a batch of n rows should be inserted with COPY, which needs a transaction
+func (r *Repository) useCopy(n int, tx *database.Tx) bool {
+	return tx != nil && r.copyThreshold > 0 && n >= r.copyThreshold
+}
+
 func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
 	if tx != nil {
 		return tx
@@ -37,13 +51,18 @@ type BatchInsertRequest struct {
 	Name string
 }
 
-// CreateEntitiesBatch creates multiple spatial entities in a single database operation using JSON
+// CreateEntitiesBatch creates multiple spatial entities in a single database operation using JSON,
+// or with COPY for batches above the configured threshold.
 // Returns only the IDs of created entities to minimize memory usage
 func (r *Repository) CreateEntitiesBatch(ctx context.Context, entities []BatchInsertRequest, tx *database.Tx) ([]int, error) {
 	if len(entities) == 0 {
 		return []int{}, nil
 	}
 
+	if r.useCopy(len(entities), tx) {
+		return r.copyEntities(ctx, entities, tx)
+	}
+
 	exec := r.getExecutor(tx)
 
 	// Build arrays for each column
@@ -109,6 +128,66 @@ func (r *Repository) CreateEntitiesBatch(ctx context.Context, entities []BatchIn
 	return entityIDs, nil
 }
 
+// copyEntities reserves IDs up front because COPY cannot return them
+func (r *Repository) copyEntities(ctx context.Context, entities []BatchInsertRequest, tx *database.Tx) ([]int, error) {
+	entityIDs, err := database.NextIDs(ctx, tx, "spatial_entities", "id", len(entities))
+	if err != nil {
+		return nil, errors.WrapInternal("failed to reserve spatial entity IDs", err)
+	}
+
+	columns := []string{"id", "game_id", "parent_id", "entity_type", "level", "x_coord", "y_coord", "name", "child_count"}
+
+	rows := make([][]any, len(entities))
+	for i, entity := range entities {
+		rows[i] = []any{
+			entityIDs[i], entity.GameID, entity.ParentID, string(entity.EntityType),
+			entity.Level, entity.XCoord, entity.YCoord, entity.Name, 0,
+		}
+	}
+
+	if err := database.CopyIn(ctx, tx, "spatial_entities", columns, rows); err != nil {
+		return nil, errors.WrapInternal("failed to copy spatial entities", err)
+	}
+
+	return entityIDs, nil
+}
+
+// GetOccupiedCoordinates returns the grid cells already used by children of each parent
+func (r *Repository) GetOccupiedCoordinates(ctx context.Context, parentIDs []int, tx *database.Tx) (map[int]map[Coordinate]bool, error) {
+	occupied := make(map[int]map[Coordinate]bool)
+	if len(parentIDs) == 0 {
+		return occupied, nil
+	}
+
+	exec := r.getExecutor(tx)
+
+	query := `SELECT parent_id, x_coord, y_coord FROM spatial_entities WHERE parent_id = ANY($1::int[])`
+
+	rows, err := exec.QueryContext(ctx, query, pq.Array(parentIDs))
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query occupied coordinates", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var parentID int
+		var c Coordinate
+		if err := rows.Scan(&parentID, &c.X, &c.Y); err != nil {
+			return nil, errors.WrapInternal("failed to scan occupied coordinate", err)
+		}
+		if occupied[parentID] == nil {
+			occupied[parentID] = make(map[Coordinate]bool)
+		}
+		occupied[parentID][c] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating occupied coordinates", err)
+	}
+
+	return occupied, nil
+}
+
 func (r *Repository) scanEntity(scanner interface{ Scan(...any) error }) (SpatialEntity, error) {
 	var e SpatialEntity
 	err := scanner.Scan(
@@ -121,6 +200,12 @@ func (r *Repository) scanEntity(scanner interface{ Scan(...any) error }) (Spatia
 const entityColumns = `id, game_id, parent_id, entity_type, level, x_coord, y_coord, name, child_count, created_at, updated_at`
 
 func (r *Repository) GetByID(ctx context.Context, entityID int) (*SpatialEntity, error) {
+	return database.Read(ctx, r.db, func() (*SpatialEntity, error) {
+		return r.getByID(ctx, entityID)
+	})
+}
+
+func (r *Repository) getByID(ctx context.Context, entityID int) (*SpatialEntity, error) {
 	query := `SELECT ` + entityColumns + ` FROM spatial_entities WHERE id = $1`
 
 	entity, err := r.scanEntity(r.db.QueryRowContext(ctx, query, entityID))
@@ -134,7 +219,72 @@ func (r *Repository) GetByID(ctx context.Context, entityID int) (*SpatialEntity,
 	return &entity, nil
 }
 
+// GetEntityAtCoord returns the child of parentID occupying the grid cell (x, y)
+func (r *Repository) GetEntityAtCoord(ctx context.Context, parentID, x, y int) (*SpatialEntity, error) {
+	query := `SELECT ` + entityColumns + ` FROM spatial_entities WHERE parent_id = $1 AND x_coord = $2 AND y_coord = $3`
+
+	entity, err := r.scanEntity(r.db.QueryRowContext(ctx, query, parentID, x, y))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundf("no spatial entity at (%d, %d) in parent %d", x, y, parentID)
+		}
+		return nil, errors.WrapInternal("failed to get spatial entity by coordinates", err)
+	}
+
+	return &entity, nil
+}
+
+// GetNeighbors returns the entity's siblings whose grid cell lies within radius of its own,
+// nearest first. An entity without a parent has no siblings.
+func (r *Repository) GetNeighbors(ctx context.Context, entityID, radius int) ([]SpatialEntity, error) {
+	return database.Read(ctx, r.db, func() ([]SpatialEntity, error) {
+		return r.getNeighbors(ctx, entityID, radius)
+	})
+}
+
+func (r *Repository) getNeighbors(ctx context.Context, entityID, radius int) ([]SpatialEntity, error) {
+	query := `
+		WITH origin AS (
+			SELECT parent_id AS origin_parent_id, x_coord AS origin_x, y_coord AS origin_y
+			FROM spatial_entities
+			WHERE id = $1
+		)
+		SELECT ` + entityColumns + `
+		FROM spatial_entities
+		JOIN origin ON parent_id = origin_parent_id
+		WHERE id <> $1
+			AND (x_coord - origin_x) ^ 2 + (y_coord - origin_y) ^ 2 <= $2 ^ 2
+		ORDER BY (x_coord - origin_x) ^ 2 + (y_coord - origin_y) ^ 2, id`
+
+	rows, err := r.db.QueryContext(ctx, query, entityID, radius)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query neighbors", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entities []SpatialEntity
+	for rows.Next() {
+		entity, err := r.scanEntity(rows)
+		if err != nil {
+			return nil, errors.WrapInternal("failed to scan neighbor entity", err)
+		}
+		entities = append(entities, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating neighbor entities", err)
+	}
+
+	return entities, nil
+}
+
 func (r *Repository) GetChildren(ctx context.Context, parentID int) ([]SpatialEntity, error) {
+	return database.Read(ctx, r.db, func() ([]SpatialEntity, error) {
+		return r.getChildren(ctx, parentID)
+	})
+}
+
+func (r *Repository) getChildren(ctx context.Context, parentID int) ([]SpatialEntity, error) {
 	query := `SELECT ` + entityColumns + ` FROM spatial_entities WHERE parent_id = $1 ORDER BY x_coord, y_coord`
 
 	rows, err := r.db.QueryContext(ctx, query, parentID)
@@ -193,3 +343,23 @@ func (r *Repository) GetAncestors(ctx context.Context, entityID int) ([]SpatialE
 
 	return entities, nil
 }
+
+// IsGameCompleted reports whether the game owning the entity has finished
+func (r *Repository) IsGameCompleted(ctx context.Context, entityID int) (bool, error) {
+	query := `
+		SELECT g.status = 'completed'
+		FROM spatial_entities se
+		JOIN games g ON g.id = se.game_id
+		WHERE se.id = $1`
+
+	var completed bool
+	err := r.db.QueryRowContext(ctx, query, entityID).Scan(&completed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors.NotFoundf("spatial entity not found with id: %d", entityID)
+		}
+		return false, errors.WrapInternal("failed to get game status for spatial entity", err)
+	}
+
+	return completed, nil
+}
@@ -0,0 +1,54 @@
+package spatial
+
+import (
+	"context"
+	"testing"
+
+	"planets-server/internal/shared/errors"
+)
+
+func TestGetEntityAtCoord(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	galaxies := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 3)
+	sectors := generate(t, service, db, gameID, []*int{&galaxies[0]}, EntityTypeSector, 1)
+
+	children, err := service.repo.GetChildren(ctx, universeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range children {
+		entity, err := service.repo.GetEntityAtCoord(ctx, universeID, child.XCoord, child.YCoord)
+		if err != nil {
+			t.Fatalf("GetEntityAtCoord(%d, %d) error = %v", child.XCoord, child.YCoord, err)
+		}
+		if entity.ID != child.ID {
+			t.Fatalf("GetEntityAtCoord(%d, %d) = entity %d, want %d", child.XCoord, child.YCoord, entity.ID, child.ID)
+		}
+	}
+
+	sector, err := service.repo.GetByID(ctx, sectors[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	misses := []struct {
+		name           string
+		parentID, x, y int
+	}{
+		{"empty cell", universeID, 50, 50},
+		{"negative cell", universeID, -1, 0},
+		// The sector's cell is only taken inside its own galaxy
+		{"other parent", galaxies[1], sector.XCoord, sector.YCoord},
+		{"missing parent", 999999, 0, 0},
+	}
+	for _, miss := range misses {
+		_, err := service.repo.GetEntityAtCoord(ctx, miss.parentID, miss.x, miss.y)
+		if errors.GetType(err) != errors.ErrorTypeNotFound {
+			t.Fatalf("%s: GetEntityAtCoord() error = %v, want not found", miss.name, err)
+		}
+	}
+}
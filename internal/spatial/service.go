@@ -5,28 +5,53 @@ import (
 	"math"
 	"planets-server/internal/shared/database"
 	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/metrics"
+	"planets-server/internal/shared/validate"
+	"planets-server/internal/visibility"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type Service struct {
-	repo *Repository
+	repo       *Repository
+	visibility *visibility.Service
+	// childrenGroup collapses concurrent GetChildren calls for the same parent into one query
+	childrenGroup singleflight.Group
 }
 
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, visibility *visibility.Service) *Service {
 	return &Service{
-		repo: repo,
+		repo:       repo,
+		visibility: visibility,
 	}
 }
 
-// GenerateEntities generates entities for one or more parent entities in a single batch operation
+// GenerateEntities generates entities for one or more parent entities in a single batch operation.
+// Parents that already have children keep them; new entities take the next free grid cells.
 // Returns only the IDs of created entities to minimize memory usage
 func (s *Service) GenerateEntities(ctx context.Context, gameID int, parentIDs []*int, entityType EntityType, countPerParent int, tx *database.Tx) ([]int, error) {
 	if len(parentIDs) == 0 {
 		return []int{}, nil
 	}
 
-	entitiesPerSide := int(math.Sqrt(float64(countPerParent)))
-	if entitiesPerSide*entitiesPerSide != countPerParent {
-		entitiesPerSide = int(math.Ceil(math.Sqrt(float64(countPerParent))))
+	defer metrics.ObserveSince(metrics.GenerationDuration.WithLabelValues(metrics.StageSpatialEntities), time.Now())
+
+	if err := validate.NonNegative("entities per parent", countPerParent); err != nil {
+		return nil, err
+	}
+
+	var existingParentIDs []int
+	for _, parentID := range parentIDs {
+		if parentID != nil {
+			existingParentIDs = append(existingParentIDs, *parentID)
+		}
+	}
+
+	occupied, err := s.repo.GetOccupiedCoordinates(ctx, existingParentIDs, tx)
+	if err != nil {
+		return nil, errors.WrapInternal("failed to load occupied coordinates", err)
 	}
 
 	names := s.generateNames(entityType)
@@ -41,33 +66,27 @@ func (s *Service) GenerateEntities(ctx context.Context, gameID int, parentIDs []
 			return nil, errors.WrapInternal("spatial entity generation cancelled", err)
 		}
 
-		nameIndex := 0
-		entityCount := 0
-
-		for x := 0; x < entitiesPerSide; x++ {
-			for y := 0; y < entitiesPerSide; y++ {
-				if entityCount >= countPerParent {
-					break
-				}
-
-				name := names[nameIndex%len(names)]
-				nameIndex++
-
-				batchRequests = append(batchRequests, BatchInsertRequest{
-					GameID:     gameID,
-					ParentID:   parentID,
-					EntityType: entityType,
-					Level:      level,
-					XCoord:     x,
-					YCoord:     y,
-					Name:       name,
-				})
-
-				entityCount++
-			}
-			if entityCount >= countPerParent {
-				break
-			}
+		var siblings map[Coordinate]bool
+		if parentID != nil {
+			siblings = occupied[*parentID]
+		}
+
+		// Continue the name sequence after any existing siblings
+		nameIndex := len(siblings)
+
+		for _, c := range allocateCoordinates(siblings, countPerParent) {
+			name := names[nameIndex%len(names)]
+			nameIndex++
+
+			batchRequests = append(batchRequests, BatchInsertRequest{
+				GameID:     gameID,
+				ParentID:   parentID,
+				EntityType: entityType,
+				Level:      level,
+				XCoord:     c.X,
+				YCoord:     c.Y,
+				Name:       name,
+			})
 		}
 	}
 
@@ -84,14 +103,123 @@ func (s *Service) GetByID(ctx context.Context, entityID int) (*SpatialEntity, er
 	return s.repo.GetByID(ctx, entityID)
 }
 
-func (s *Service) GetChildren(ctx context.Context, parentID int) ([]SpatialEntity, error) {
-	return s.repo.GetChildren(ctx, parentID)
+// GetChildren lists the entity's children. Under fog of war, systems the viewer hasn't
+// discovered are listed without their contents.
+func (s *Service) GetChildren(ctx context.Context, parentID int, viewer visibility.Viewer) ([]SpatialEntity, error) {
+	// The shared query must not be cancelled when only the first caller goes away
+	sharedCtx := context.WithoutCancel(ctx)
+
+	result, err, _ := s.childrenGroup.Do(strconv.Itoa(parentID), func() (any, error) {
+		return s.repo.GetChildren(sharedCtx, parentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	children := result.([]SpatialEntity)
+
+	var systemIDs []int
+	for _, child := range children {
+		if child.EntityType == EntityTypeSystem {
+			systemIDs = append(systemIDs, child.ID)
+		}
+	}
+
+	scope, err := s.visibility.Scope(ctx, viewer, parentID, systemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The slice is shared with concurrent callers, so redact a copy
+	visible := make([]SpatialEntity, len(children))
+	for i, child := range children {
+		if child.EntityType == EntityTypeSystem && !scope.Sees(child.ID) {
+			child.ChildCount = 0
+			child.Unexplored = true
+		}
+		visible[i] = child
+	}
+
+	return visible, nil
+}
+
+func (s *Service) GetEntityAtCoord(ctx context.Context, parentID, x, y int) (*SpatialEntity, error) {
+	return s.repo.GetEntityAtCoord(ctx, parentID, x, y)
 }
 
 func (s *Service) GetAncestors(ctx context.Context, entityID int) ([]SpatialEntity, error) {
 	return s.repo.GetAncestors(ctx, entityID)
 }
 
+// Distance returns the Euclidean distance between two entities of the same level in a game,
+// in cells of that level. Entities under different parents are placed on a composite grid
+// below their closest common ancestor, assuming parents at the same level share a grid
+// size, which holds for generated universes.
+func (s *Service) Distance(ctx context.Context, gameID, fromEntityID, toEntityID int) (float64, error) {
+	from, err := s.repo.GetAncestors(ctx, fromEntityID)
+	if err != nil {
+		return 0, err
+	}
+	to, err := s.repo.GetAncestors(ctx, toEntityID)
+	if err != nil {
+		return 0, err
+	}
+	if len(from) == 0 {
+		return 0, errors.NotFoundf("spatial entity not found with id: %d", fromEntityID)
+	}
+	if len(to) == 0 {
+		return 0, errors.NotFoundf("spatial entity not found with id: %d", toEntityID)
+	}
+
+	fromEntity, toEntity := from[len(from)-1], to[len(to)-1]
+	if fromEntity.GameID != gameID || toEntity.GameID != gameID {
+		return 0, errors.Validationf("entities %d and %d do not both belong to game %d", fromEntityID, toEntityID, gameID)
+	}
+	if fromEntity.Level != toEntity.Level {
+		return 0, errors.Validationf("cannot measure distance between a %s and a %s", fromEntity.EntityType, toEntity.EntityType)
+	}
+
+	// Ancestor chains run from the universe down, so they match up to the closest common ancestor.
+	// Without a common root the entities share no grid to measure on.
+	if from[0].ID != to[0].ID {
+		return 0, errors.Validationf("entities %d and %d are not in the same universe", fromEntityID, toEntityID)
+	}
+	common := 0
+	for common+1 < len(from) && from[common+1].ID == to[common+1].ID {
+		common++
+	}
+
+	fromX, fromY := compositePosition(from[common:])
+	toX, toY := compositePosition(to[common:])
+
+	return math.Hypot(toX-fromX, toY-fromY), nil
+}
+
+// Neighbors lists the entity's siblings within radius grid cells, nearest first
+func (s *Service) Neighbors(ctx context.Context, entityID int, radius int) ([]SpatialEntity, error) {
+	if err := validate.NonNegative("radius", radius); err != nil {
+		return nil, err
+	}
+
+	// The neighbor query finds nothing for an unknown entity, so report it explicitly
+	if _, err := s.repo.GetByID(ctx, entityID); err != nil {
+		return nil, err
+	}
+
+	neighbors, err := s.repo.GetNeighbors(ctx, entityID, radius)
+	if err != nil {
+		return nil, err
+	}
+	if neighbors == nil {
+		neighbors = []SpatialEntity{}
+	}
+
+	return neighbors, nil
+}
+
+func (s *Service) IsGameCompleted(ctx context.Context, entityID int) (bool, error) {
+	return s.repo.IsGameCompleted(ctx, entityID)
+}
+
 func (s *Service) generateNames(entityType EntityType) []string {
 	switch entityType {
 	case EntityTypeUniverse:
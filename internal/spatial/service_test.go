@@ -0,0 +1,313 @@
+package spatial
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/visibility"
+)
+
+func newTestService(t *testing.T) (*Service, *database.DB) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	return NewService(NewRepository(db), visibility.NewService(visibility.NewRepository(db))), db
+}
+
+func createTestGame(t *testing.T, db *database.DB) int {
+	t.Helper()
+
+	var id int
+	if err := db.QueryRow("INSERT INTO games (name, seed) VALUES ('spatial test', 'seed') RETURNING id").Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// generate runs GenerateEntities in its own committed transaction
+func generate(t *testing.T, service *Service, db *database.DB, gameID int, parentIDs []*int, entityType EntityType, count int) []int {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := service.GenerateEntities(ctx, gameID, parentIDs, entityType, count, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestGenerateEntitiesIncrementallyWithoutCollision(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+
+	first := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 4)
+	second := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 3)
+	third := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 2)
+
+	if len(first) != 4 || len(second) != 3 || len(third) != 2 {
+		t.Fatalf("created %d, %d and %d galaxies, want 4, 3 and 2", len(first), len(second), len(third))
+	}
+
+	children, err := service.repo.GetChildren(ctx, universeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 9 {
+		t.Fatalf("universe has %d galaxies, want 9", len(children))
+	}
+
+	cells := map[Coordinate]int{}
+	for _, child := range children {
+		c := Coordinate{X: child.XCoord, Y: child.YCoord}
+		if other, taken := cells[c]; taken {
+			t.Fatalf("galaxies %d and %d share cell %v", other, child.ID, c)
+		}
+		cells[c] = child.ID
+	}
+}
+
+func TestGenerateEntitiesIntoSeveralParentsKeepsEachGridSeparate(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	galaxies := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 2)
+
+	parents := []*int{&galaxies[0], &galaxies[1]}
+	generate(t, service, db, gameID, parents, EntityTypeSector, 2)
+	generate(t, service, db, gameID, parents, EntityTypeSector, 2)
+
+	for _, galaxyID := range galaxies {
+		sectors, err := service.repo.GetChildren(ctx, galaxyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sectors) != 4 {
+			t.Fatalf("galaxy %d has %d sectors, want 4", galaxyID, len(sectors))
+		}
+
+		cells := map[Coordinate]bool{}
+		for _, sector := range sectors {
+			c := Coordinate{X: sector.XCoord, Y: sector.YCoord}
+			if cells[c] {
+				t.Fatalf("galaxy %d has two sectors at %v", galaxyID, c)
+			}
+			cells[c] = true
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+
+	// A 3x3 grid of systems, filled column by column
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	galaxyID := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 1)[0]
+	sectorID := generate(t, service, db, gameID, []*int{&galaxyID}, EntityTypeSector, 1)[0]
+	systems := generate(t, service, db, gameID, []*int{&sectorID}, EntityTypeSystem, 9)
+	at := func(x, y int) int { return systems[x*3+y] }
+
+	tests := []struct {
+		name     string
+		entityID int
+		radius   int
+		want     []int
+	}{
+		{"orthogonal cells", at(1, 1), 1, []int{at(0, 1), at(1, 0), at(1, 2), at(2, 1)}},
+		{"whole grid, nearest first", at(1, 1), 2, []int{at(0, 1), at(1, 0), at(1, 2), at(2, 1), at(0, 0), at(0, 2), at(2, 0), at(2, 2)}},
+		{"corner", at(0, 0), 1, []int{at(0, 1), at(1, 0)}},
+		{"zero radius", at(1, 1), 0, []int{}},
+		{"no parent", universeID, 10, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			neighbors, err := service.Neighbors(ctx, tt.entityID, tt.radius)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]int, len(neighbors))
+			for i, neighbor := range neighbors {
+				got[i] = neighbor.ID
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("Neighbors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := service.Neighbors(ctx, 999999, 1); errors.GetType(err) != errors.ErrorTypeNotFound {
+		t.Fatalf("Neighbors() of a missing entity error = %v, want not found", err)
+	}
+	if _, err := service.Neighbors(ctx, at(1, 1), -1); errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("Neighbors() with a negative radius error = %v, want a validation error", err)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+	otherGameID := createTestGame(t, db)
+
+	// Two galaxies of one sector each, stacked on the universe grid, with a 2x2 grid of systems per sector
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	galaxies := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 2)
+	firstSector := generate(t, service, db, gameID, []*int{&galaxies[0]}, EntityTypeSector, 1)[0]
+	secondSector := generate(t, service, db, gameID, []*int{&galaxies[1]}, EntityTypeSector, 1)[0]
+	first := generate(t, service, db, gameID, []*int{&firstSector}, EntityTypeSystem, 4)
+	second := generate(t, service, db, gameID, []*int{&secondSector}, EntityTypeSystem, 4)
+
+	otherUniverseID := generate(t, service, db, otherGameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	var strayUniverseID int
+	err := db.QueryRow(`
+		INSERT INTO spatial_entities (game_id, entity_type, level, x_coord, y_coord, name)
+		VALUES ($1, 'universe', 0, 0, 0, 'Stray') RETURNING id`, gameID).Scan(&strayUniverseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	distances := []struct {
+		name     string
+		from, to int
+		want     float64
+	}{
+		{"same entity", first[0], first[0], 0},
+		{"same sector", first[0], first[3], math.Sqrt2},
+		// The second sector starts two system cells further along the universe grid
+		{"across galaxies", first[0], second[0], 2},
+		{"galaxies", galaxies[0], galaxies[1], 1},
+	}
+	for _, tt := range distances {
+		got, err := service.Distance(ctx, gameID, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%s: Distance() error = %v", tt.name, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("%s: Distance() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		name     string
+		from, to int
+	}{
+		{"different levels", first[0], firstSector},
+		{"different games", universeID, otherUniverseID},
+		{"different universes", universeID, strayUniverseID},
+	}
+	for _, tt := range invalid {
+		if _, err := service.Distance(ctx, gameID, tt.from, tt.to); errors.GetType(err) != errors.ErrorTypeValidation {
+			t.Fatalf("%s: Distance() error = %v, want a validation error", tt.name, err)
+		}
+	}
+
+	if _, err := service.Distance(ctx, gameID, first[0], 999999); errors.GetType(err) != errors.ErrorTypeNotFound {
+		t.Fatalf("Distance() to a missing entity error = %v, want not found", err)
+	}
+}
+
+func TestConcurrentChildReadsShareOneQuery(t *testing.T) {
+	service, db := newTestService(t)
+	ctx := context.Background()
+	gameID := createTestGame(t, db)
+	if _, err := db.Exec(`UPDATE games SET settings = '{"fog_of_war": true}' WHERE id = $1`, gameID); err != nil {
+		t.Fatal(err)
+	}
+
+	universeID := generate(t, service, db, gameID, []*int{nil}, EntityTypeUniverse, 1)[0]
+	galaxyID := generate(t, service, db, gameID, []*int{&universeID}, EntityTypeGalaxy, 1)[0]
+	sectorID := generate(t, service, db, gameID, []*int{&galaxyID}, EntityTypeSector, 1)[0]
+	systems := generate(t, service, db, gameID, []*int{&sectorID}, EntityTypeSystem, 3)
+
+	// Half the readers discovered the first system, the other half have discovered nothing
+	const readers = 8
+	viewers := make([]visibility.Viewer, readers)
+	for i := range viewers {
+		viewers[i] = visibility.Viewer{PlayerID: dbtest.CreatePlayer(t, db)}
+		if i%2 == 0 {
+			if _, err := db.Exec("INSERT INTO player_visibility (player_id, system_id) VALUES ($1, $2)", viewers[i].PlayerID, systems[0]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Every reader queues behind the lock, so they all overlap with the first query
+	holder, release := dbtest.LockTable(t, db, "spatial_entities")
+
+	var wg sync.WaitGroup
+	results := make([][]SpatialEntity, readers)
+	errs := make([]error, readers)
+	start := make(chan struct{})
+	for i, viewer := range viewers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = service.GetChildren(ctx, sectorID, viewer)
+		}()
+	}
+	close(start)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for dbtest.Blocked(t, db, holder) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no children query reached the database")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give the other readers time to arrive; any that query on their own show up as blocked too
+	time.Sleep(200 * time.Millisecond)
+	blocked := dbtest.Blocked(t, db, holder)
+	release()
+	wg.Wait()
+
+	if blocked != 1 {
+		t.Fatalf("%d readers queried the children, want them all to share one query", blocked)
+	}
+
+	for i, children := range results {
+		if errs[i] != nil {
+			t.Fatalf("reader %d: %v", i, errs[i])
+		}
+		if len(children) != len(systems) {
+			t.Fatalf("reader %d got %d children, want %d", i, len(children), len(systems))
+		}
+		for _, child := range children {
+			discovered := i%2 == 0 && child.ID == systems[0]
+			if child.Unexplored == discovered {
+				t.Fatalf("reader %d got system %d with unexplored = %v, want %v", i, child.ID, child.Unexplored, !discovered)
+			}
+		}
+	}
+
+	// No two readers share a slice, so one reader's redaction cannot leak into another's
+	for i := range results {
+		for j := i + 1; j < readers; j++ {
+			if &results[i][0] == &results[j][0] {
+				t.Fatalf("readers %d and %d got the same slice", i, j)
+			}
+		}
+	}
+}
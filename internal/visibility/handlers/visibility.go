@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"planets-server/internal/middleware"
+	"planets-server/internal/shared/errors"
+	"planets-server/internal/shared/logger"
+	"planets-server/internal/shared/response"
+	"planets-server/internal/visibility"
+)
+
+type VisibilityHandler struct {
+	service *visibility.Service
+}
+
+func NewVisibilityHandler(service *visibility.Service) *VisibilityHandler {
+	return &VisibilityHandler{service: service}
+}
+
+func (h *VisibilityHandler) Explore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logger.FromContext(r.Context()).With("handler", "explore_system")
+
+	if r.Method != http.MethodPost {
+		response.Error(w, r, logger, errors.MethodNotAllowed(r.Method))
+		return
+	}
+
+	claims := middleware.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, r, logger, errors.Unauthorized("no user claims found in context"))
+		return
+	}
+
+	systemIDStr := r.PathValue("id")
+	if systemIDStr == "" {
+		response.Error(w, r, logger, errors.Validation("system ID is required"))
+		return
+	}
+
+	systemID, err := strconv.Atoi(systemIDStr)
+	if err != nil {
+		response.Error(w, r, logger, errors.WrapValidation("invalid system ID format", err))
+		return
+	}
+
+	if err := h.service.Explore(ctx, claims.PlayerID, systemID); err != nil {
+		response.Error(w, r, logger, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, map[string]int{"explored_system_id": systemID})
+}
@@ -0,0 +1,127 @@
+package visibility
+
+import (
+	"context"
+	"database/sql"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) getExecutor(tx *database.Tx) database.Executor {
+	if tx != nil {
+		return tx
+	}
+	return r.db
+}
+
+// FogEnabled reports whether the game owning the spatial entity has fog of war turned on
+func (r *Repository) FogEnabled(ctx context.Context, entityID int) (bool, error) {
+	query := `
+		SELECT COALESCE((g.settings->>'fog_of_war')::BOOLEAN, FALSE)
+		FROM spatial_entities s
+		JOIN games g ON g.id = s.game_id
+		WHERE s.id = $1`
+
+	var enabled bool
+	err := r.db.QueryRowContext(ctx, query, entityID).Scan(&enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors.NotFoundf("spatial entity not found with id: %d", entityID)
+		}
+		return false, errors.WrapInternal("failed to check fog of war", err)
+	}
+
+	return enabled, nil
+}
+
+// KnownSystems returns which of systemIDs the player has discovered
+func (r *Repository) KnownSystems(ctx context.Context, playerID int, systemIDs []int) (map[int]bool, error) {
+	query := `SELECT system_id FROM player_visibility WHERE player_id = $1 AND system_id = ANY($2)`
+
+	rows, err := r.db.QueryContext(ctx, query, playerID, pq.Array(systemIDs))
+	if err != nil {
+		return nil, errors.WrapInternal("failed to query known systems", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	known := make(map[int]bool)
+	for rows.Next() {
+		var systemID int
+		if err := rows.Scan(&systemID); err != nil {
+			return nil, errors.WrapInternal("failed to scan known system", err)
+		}
+		known[systemID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapInternal("error iterating known systems", err)
+	}
+
+	return known, nil
+}
+
+// GetEntityType returns the type of the spatial entity
+func (r *Repository) GetEntityType(ctx context.Context, entityID int) (string, error) {
+	query := `SELECT entity_type FROM spatial_entities WHERE id = $1`
+
+	var entityType string
+	err := r.db.QueryRowContext(ctx, query, entityID).Scan(&entityType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.NotFoundf("spatial entity not found with id: %d", entityID)
+		}
+		return "", errors.WrapInternal("failed to get spatial entity type", err)
+	}
+
+	return entityType, nil
+}
+
+// OwnsPlanetNear reports whether the player owns a planet in the system or in one of the
+// systems next to it on its sector's grid
+func (r *Repository) OwnsPlanetNear(ctx context.Context, playerID, systemID int) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM spatial_entities target
+			JOIN spatial_entities s ON s.parent_id = target.parent_id
+			JOIN planets p ON p.system_id = s.id
+			WHERE target.id = $1
+				AND p.owner_id = $2
+				AND ABS(s.x_coord - target.x_coord) <= 1
+				AND ABS(s.y_coord - target.y_coord) <= 1
+		)`
+
+	var near bool
+	if err := r.db.QueryRowContext(ctx, query, systemID, playerID).Scan(&near); err != nil {
+		return false, errors.WrapInternal("failed to check planets near system", err)
+	}
+
+	return near, nil
+}
+
+// RecordSystem marks the system as discovered by the player; recording it again is a no-op
+func (r *Repository) RecordSystem(ctx context.Context, playerID, systemID int, tx *database.Tx) error {
+	exec := r.getExecutor(tx)
+
+	query := `
+		INSERT INTO player_visibility (player_id, system_id)
+		VALUES ($1, $2)
+		ON CONFLICT (player_id, system_id) DO NOTHING`
+
+	if _, err := exec.ExecContext(ctx, query, playerID, systemID); err != nil {
+		return errors.WrapInternal("failed to record discovered system", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package visibility
+
+import (
+	"context"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/errors"
+)
+
+// entityTypeSystem is the spatial entity type that can be explored
+const entityTypeSystem = "system"
+
+// Viewer is the player a map read is made for
+type Viewer struct {
+	PlayerID int
+	Admin    bool
+}
+
+// Scope is the part of the map a viewer can see in full
+type Scope struct {
+	all   bool
+	known map[int]bool
+}
+
+// Sees reports whether the viewer gets full details for the system
+func (s Scope) Sees(systemID int) bool {
+	return s.all || s.known[systemID]
+}
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Scope resolves which of systemIDs, all under the spatial entity entityID, the viewer can
+// see. Admins and games without fog of war see everything; otherwise players see only the
+// systems they have discovered.
+func (s *Service) Scope(ctx context.Context, viewer Viewer, entityID int, systemIDs []int) (Scope, error) {
+	if viewer.Admin || len(systemIDs) == 0 {
+		return Scope{all: true}, nil
+	}
+
+	fog, err := s.repo.FogEnabled(ctx, entityID)
+	if err != nil {
+		return Scope{}, err
+	}
+	if !fog {
+		return Scope{all: true}, nil
+	}
+
+	known, err := s.repo.KnownSystems(ctx, viewer.PlayerID, systemIDs)
+	if err != nil {
+		return Scope{}, err
+	}
+
+	return Scope{known: known}, nil
+}
+
+// Discover reveals the system to the player, as part of tx when one is given
+func (s *Service) Discover(ctx context.Context, playerID, systemID int, tx *database.Tx) error {
+	return s.repo.RecordSystem(ctx, playerID, systemID, tx)
+}
+
+// Explore reveals a system to the player, who must own a planet in it or in a neighbouring
+// system of the same sector. Exploring an already known system succeeds without change.
+func (s *Service) Explore(ctx context.Context, playerID, systemID int) error {
+	entityType, err := s.repo.GetEntityType(ctx, systemID)
+	if err != nil {
+		return err
+	}
+	if entityType != entityTypeSystem {
+		return errors.Validationf("spatial entity %d is a %s, only systems can be explored", systemID, entityType)
+	}
+
+	near, err := s.repo.OwnsPlanetNear(ctx, playerID, systemID)
+	if err != nil {
+		return err
+	}
+	if !near {
+		return errors.WithCode(errors.Forbidden("system is out of reach: own a planet in or next to it first"), errors.CodeSystemOutOfReach)
+	}
+
+	return s.repo.RecordSystem(ctx, playerID, systemID, nil)
+}
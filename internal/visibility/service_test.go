@@ -0,0 +1,95 @@
+package visibility
+
+import (
+	"context"
+	"testing"
+
+	"planets-server/internal/shared/database"
+	"planets-server/internal/shared/database/dbtest"
+	"planets-server/internal/shared/errors"
+)
+
+// insertEntity adds a spatial entity to the game; parentID 0 makes it the root
+func insertEntity(t *testing.T, db *database.DB, gameID, parentID int, entityType string, level, x, y int) int {
+	t.Helper()
+
+	var parent *int
+	if parentID != 0 {
+		parent = &parentID
+	}
+
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO spatial_entities (game_id, parent_id, entity_type, level, x_coord, y_coord, name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		gameID, parent, entityType, level, x, y, entityType,
+	).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestExploreAllowedAndDisallowed(t *testing.T) {
+	db := dbtest.Open(t)
+	service := NewService(NewRepository(db))
+	ctx := context.Background()
+
+	var gameID int
+	if err := db.QueryRow("INSERT INTO games (name, seed) VALUES ('explore test', 'seed') RETURNING id").Scan(&gameID); err != nil {
+		t.Fatal(err)
+	}
+	universe := insertEntity(t, db, gameID, 0, "universe", 0, 0, 0)
+	galaxy := insertEntity(t, db, gameID, universe, "galaxy", 1, 0, 0)
+	sector := insertEntity(t, db, gameID, galaxy, "sector", 2, 0, 0)
+	otherSector := insertEntity(t, db, gameID, galaxy, "sector", 2, 1, 0)
+
+	home := insertEntity(t, db, gameID, sector, "system", 3, 0, 0)
+	diagonal := insertEntity(t, db, gameID, sector, "system", 3, 1, 1)
+	far := insertEntity(t, db, gameID, sector, "system", 3, 3, 0)
+	// Same coordinates as a neighbour, but on another sector's grid
+	elsewhere := insertEntity(t, db, gameID, otherSector, "system", 3, 0, 1)
+
+	playerID := dbtest.CreatePlayer(t, db)
+	strangerID := dbtest.CreatePlayer(t, db)
+	_, err := db.Exec(`INSERT INTO planets (system_id, planet_index, name, owner_id) VALUES ($1, 0, 'Home', $2)`, home, playerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, systemID := range []int{home, diagonal, diagonal} {
+		if err := service.Explore(ctx, playerID, systemID); err != nil {
+			t.Fatalf("Explore(%d) error = %v, want it allowed", systemID, err)
+		}
+	}
+
+	denied := []struct {
+		name               string
+		playerID, systemID int
+	}{
+		{"too far", playerID, far},
+		{"other sector", playerID, elsewhere},
+		{"no planets", strangerID, home},
+	}
+	for _, tt := range denied {
+		err := service.Explore(ctx, tt.playerID, tt.systemID)
+		if errors.GetCode(err) != errors.CodeSystemOutOfReach {
+			t.Fatalf("%s: Explore() error = %v, want %s", tt.name, err, errors.CodeSystemOutOfReach)
+		}
+	}
+
+	if err := service.Explore(ctx, playerID, sector); errors.GetType(err) != errors.ErrorTypeValidation {
+		t.Fatalf("exploring a sector: error = %v, want a validation error", err)
+	}
+	if err := service.Explore(ctx, playerID, 999999); errors.GetType(err) != errors.ErrorTypeNotFound {
+		t.Fatalf("exploring a missing system: error = %v, want not found", err)
+	}
+
+	known, err := service.repo.KnownSystems(ctx, playerID, []int{home, diagonal, far, elsewhere})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 2 || !known[home] || !known[diagonal] {
+		t.Fatalf("known systems = %v, want only %d and %d", known, home, diagonal)
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE planet_ownership_history (
+    id SERIAL PRIMARY KEY,
+    planet_id INTEGER NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    old_owner_id INTEGER REFERENCES players(id) ON DELETE SET NULL,
+    new_owner_id INTEGER REFERENCES players(id) ON DELETE SET NULL,
+    turn INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT check_ownership_reason CHECK (reason IN ('colonization', 'transfer', 'combat'))
+);
+
+CREATE INDEX idx_planet_ownership_history_planet_id ON planet_ownership_history(planet_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE planets DROP COLUMN IF EXISTS defense;
@@ -0,0 +1,12 @@
+ALTER TABLE planets ADD COLUMN defense INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE planets ADD CONSTRAINT check_planet_defense CHECK (defense >= 0);
+
+-- Give existing planets the base defense generation sets, see BaseDefense in internal/planet/defense.go
+UPDATE planets SET defense = size / 5 + CASE type
+    WHEN 'barren' THEN 5
+    WHEN 'terrestrial' THEN 10
+    WHEN 'gas_giant' THEN 20
+    WHEN 'ice' THEN 8
+    WHEN 'volcanic' THEN 15
+END;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trigger_update_game_player_count ON game_players;
+DROP FUNCTION IF EXISTS update_game_player_count();
+
+ALTER TABLE games DROP COLUMN IF EXISTS player_count;
@@ -0,0 +1,19 @@
+ALTER TABLE games ADD COLUMN player_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE games g SET player_count = (SELECT COUNT(*) FROM game_players gp WHERE gp.game_id = g.id);
+
+CREATE OR REPLACE FUNCTION update_game_player_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE games SET player_count = player_count + 1 WHERE id = NEW.game_id;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE games SET player_count = player_count - 1 WHERE id = OLD.game_id;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_game_player_count AFTER INSERT OR DELETE ON game_players FOR EACH ROW EXECUTE FUNCTION update_game_player_count();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_games_scheduled_start;
+
+ALTER TABLE games DROP COLUMN IF EXISTS start_at;
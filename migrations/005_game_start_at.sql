@@ -0,0 +1,3 @@
+ALTER TABLE games ADD COLUMN start_at TIMESTAMP;
+
+CREATE INDEX idx_games_scheduled_start ON games(start_at) WHERE status = 'scheduled';
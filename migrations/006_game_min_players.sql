@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN min_players INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN settings JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,6 @@
+-- Abandonments cannot be recorded without the reason, so their history goes with it
+DELETE FROM planet_ownership_history WHERE reason = 'abandonment';
+
+ALTER TABLE planet_ownership_history DROP CONSTRAINT check_ownership_reason;
+ALTER TABLE planet_ownership_history ADD CONSTRAINT check_ownership_reason
+    CHECK (reason IN ('colonization', 'transfer', 'combat'));
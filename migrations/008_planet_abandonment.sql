@@ -0,0 +1,3 @@
+ALTER TABLE planet_ownership_history DROP CONSTRAINT check_ownership_reason;
+ALTER TABLE planet_ownership_history ADD CONSTRAINT check_ownership_reason
+    CHECK (reason IN ('colonization', 'transfer', 'combat', 'abandonment'));
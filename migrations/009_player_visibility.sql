@@ -0,0 +1,10 @@
+CREATE TABLE player_visibility (
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    system_id INTEGER NOT NULL REFERENCES spatial_entities(id) ON DELETE CASCADE,
+    discovered_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (player_id, system_id)
+);
+
+-- Players already know the systems they own planets in
+INSERT INTO player_visibility (player_id, system_id)
+SELECT DISTINCT owner_id, system_id FROM planets WHERE owner_id IS NOT NULL;
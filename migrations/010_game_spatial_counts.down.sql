@@ -0,0 +1,3 @@
+ALTER TABLE games DROP COLUMN IF EXISTS galaxy_count;
+ALTER TABLE games DROP COLUMN IF EXISTS sector_count;
+ALTER TABLE games DROP COLUMN IF EXISTS system_count;
@@ -0,0 +1,8 @@
+ALTER TABLE games ADD COLUMN galaxy_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN sector_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN system_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE games g SET
+    galaxy_count = (SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'galaxy'),
+    sector_count = (SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'sector'),
+    system_count = (SELECT COUNT(*) FROM spatial_entities s WHERE s.game_id = g.id AND s.entity_type = 'system');
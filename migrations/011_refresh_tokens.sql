@@ -0,0 +1,9 @@
+CREATE TABLE refresh_tokens (
+    id VARCHAR(64) PRIMARY KEY,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_tokens_player_id ON refresh_tokens(player_id);
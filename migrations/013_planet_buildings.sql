@@ -0,0 +1,11 @@
+CREATE TABLE planet_buildings (
+    planet_id INTEGER NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    building_type VARCHAR(30) NOT NULL,
+    level INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (planet_id, building_type),
+    CHECK (level >= 1)
+);
+
+CREATE TRIGGER update_planet_buildings_updated_at BEFORE UPDATE ON planet_buildings FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+CREATE TABLE player_research (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    tech_id VARCHAR(50) NOT NULL,
+    progress INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP,
+    PRIMARY KEY (game_id, player_id, tech_id)
+);
+
+-- A player researches one tech at a time in each game
+CREATE UNIQUE INDEX idx_player_research_active ON player_research(game_id, player_id) WHERE completed_at IS NULL;
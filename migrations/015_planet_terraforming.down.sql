@@ -0,0 +1,10 @@
+-- Terraforming cannot be recorded without the reason, so its history goes with it
+DELETE FROM planet_ownership_history WHERE reason = 'terraforming';
+
+ALTER TABLE planet_ownership_history DROP CONSTRAINT check_ownership_reason;
+ALTER TABLE planet_ownership_history ADD CONSTRAINT check_ownership_reason
+    CHECK (reason IN ('colonization', 'transfer', 'combat', 'abandonment'));
+
+ALTER TABLE planet_ownership_history
+    DROP COLUMN IF EXISTS old_type,
+    DROP COLUMN IF EXISTS new_type;
@@ -0,0 +1,7 @@
+ALTER TABLE planet_ownership_history
+    ADD COLUMN old_type planet_type,
+    ADD COLUMN new_type planet_type;
+
+ALTER TABLE planet_ownership_history DROP CONSTRAINT check_ownership_reason;
+ALTER TABLE planet_ownership_history ADD CONSTRAINT check_ownership_reason
+    CHECK (reason IN ('colonization', 'transfer', 'combat', 'abandonment', 'terraforming'));
@@ -0,0 +1,8 @@
+-- One row per processed turn. The primary key keeps a retried turn from being applied twice.
+CREATE TABLE turn_log (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    turn INTEGER NOT NULL,
+    summary JSONB NOT NULL DEFAULT '{}',
+    processed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, turn)
+);
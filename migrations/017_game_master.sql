@@ -0,0 +1,3 @@
+-- The player with per-game authority, starting with the admin who created the game.
+-- Existing games have no game master and stay under global admin control.
+ALTER TABLE games ADD COLUMN gm_player_id INTEGER REFERENCES players(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+-- Lets the token pruner find expired refresh tokens without scanning the table
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);